@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata"
+
+	processorapp "github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/app"
+	senderapp "github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/app"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/adapters"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/config"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
+)
+
+// allInOne runs the sender and the processor in one process against the
+// in-memory adapters. Nothing is persisted across restarts.
+type allInOne struct {
+	sender    *senderapp.App
+	processor *processorapp.App
+	queue     rabbitPort.MessageQueue
+}
+
+func newAllInOne(cfg *config.Config) (*allInOne, error) {
+	// There is no Redis to share rate limits through.
+	cfg.RateLimit.Backend = ratelimit.BackendLocal
+
+	queue, err := adapters.NewMemoryMessageQueue(cfg.RabbitMQ.Topology)
+	if err != nil {
+		return nil, err
+	}
+
+	db := adapters.NewMemoryDatabase()
+	messages := adapters.NewMemoryMessageRepository(db)
+	outbox := adapters.NewMemoryOutboxRepository(db)
+	leases := adapters.NewMemoryLeaseStore(db)
+
+	return &allInOne{
+		sender: senderapp.New(cfg, senderapp.Dependencies{
+			Messages:       messages,
+			Outbox:         outbox,
+			Recurring:      adapters.NewMemoryRecurringMessageRepository(db),
+			SchedulerState: adapters.NewMemorySchedulerStateStore(db),
+			Leases:         leases,
+			Queue:          queue,
+		}),
+		processor: processorapp.New(cfg, processorapp.Dependencies{
+			Messages:    messages,
+			Outbox:      outbox,
+			Leases:      leases,
+			AlertState:  adapters.NewMemoryAlertStateStore(db),
+			Queue:       queue,
+			Idempotency: adapters.NewMemoryIdempotencyService(),
+		}),
+		queue: queue,
+	}, nil
+}
+
+func (a *allInOne) Start(ctx context.Context) {
+	a.sender.Start(ctx)
+	a.processor.Start()
+}
+
+// Stop stops the sender before the processor, so nothing is published to
+// the queue after the processor has stopped consuming.
+func (a *allInOne) Stop() {
+	a.sender.Stop()
+	a.processor.Stop()
+	a.queue.Close()
+}
+
+func main() {
+	cfg := config.LoadConfig()
+
+	services, err := newAllInOne(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create in-memory message queue: %v", err)
+	}
+	services.Start(context.Background())
+
+	go func() {
+		if err := services.sender.Router.Run(":8080"); err != nil {
+			log.Fatalf("Failed to start sender server: %v", err)
+		}
+	}()
+	go func() {
+		if err := services.processor.Router.Run(":8081"); err != nil {
+			log.Fatalf("Failed to start processor server: %v", err)
+		}
+	}()
+
+	grpcListener, err := net.Listen("tcp", cfg.GRPC.Addr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", cfg.GRPC.Addr, err)
+	}
+	go func() {
+		if err := services.sender.GRPC.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	log.Println("Shutting down all-in-one service...")
+	services.Stop()
+}
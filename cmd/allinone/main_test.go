@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/config"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(webhookURL string) *config.Config {
+	cfg := config.LoadConfig()
+	cfg.Instance.ID = "allinone-test"
+	cfg.LeaderElection.Enabled = false
+	cfg.Auth.APIKeys = nil
+	cfg.Webhook.URL = webhookURL
+	cfg.Webhook.Timeout = 5 * time.Second
+	cfg.MessageProcessor.PollInterval = time.Hour
+	cfg.MessageProcessor.ChangeStream = true
+	cfg.Alerting.LogEnabled = false
+	cfg.Alerting.WebhookURL = ""
+	cfg.Alerting.SlackWebhookURL = ""
+	return cfg
+}
+
+func TestAllInOne_DeliversMessageEndToEnd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mu sync.Mutex
+	var received []map[string]string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		received = append(received, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"message":"Accepted","messageId":"webhook-1"}`))
+	}))
+	defer webhook.Close()
+
+	services, err := newAllInOne(testConfig(webhook.URL))
+	require.NoError(t, err)
+	services.Start(context.Background())
+	defer services.Stop()
+
+	sender := httptest.NewServer(services.sender.Router)
+	defer sender.Close()
+
+	resp, err := http.Post(sender.URL+"/api/v1/scheduler/start", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	payload, _ := json.Marshal(map[string]string{"to": "+905321234567", "content": "hello from the all-in-one test"})
+	resp, err = http.Post(sender.URL+"/api/v1/messages", "application/json", bytes.NewReader(payload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(sender.URL + "/api/v1/messages")
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+
+		var list struct {
+			Messages []models.Message `json:"messages"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			return false
+		}
+		return len(list.Messages) == 1 && list.Messages[0].Status == models.StatusSent
+	}, 10*time.Second, 50*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.Equal(t, "+905321234567", received[0]["to"])
+	assert.Equal(t, "hello from the all-in-one test", received[0]["content"])
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/handlers"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/service"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/infrastructure/alerting"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/infrastructure/middleware"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/infrastructure/webhook"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/auth"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/config"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
+	mongoPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+	redisPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/redis/interfaces"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	redisClient "github.com/go-redis/redis/v8"
+)
+
+// Dependencies are the adapters the processor service runs against. Redis is
+// only used by the redis rate limit backend and may be nil.
+type Dependencies struct {
+	Messages    mongoPort.MessageRepository
+	Outbox      mongoPort.OutboxRepository
+	Leases      mongoPort.LeaseStore
+	AlertState  mongoPort.AlertStateStore
+	Queue       rabbitPort.MessageQueue
+	Idempotency redisPort.IdempotencyServicePort
+	Redis       *redisClient.Client
+}
+
+// App is the processor service wired to its dependencies. The caller serves
+// Router and closes the dependencies after Stop.
+type App struct {
+	Router *gin.Engine
+
+	processor    *service.ProcessorService
+	alerts       *service.AlertService
+	stopElection func()
+}
+
+func New(cfg *config.Config, deps Dependencies) *App {
+	webhookLimiter := ratelimit.NewLimiter(cfg.RateLimit.Backend, deps.Redis, "webhook", cfg.RateLimit.WebhookRPS, cfg.RateLimit.WebhookBurst)
+	webhookClient := webhook.NewHTTPWebhookClientWithLimiter(cfg.Webhook.URL, cfg.Webhook.Timeout, webhookLimiter)
+
+	processor := domain.NewMessageProcessor(cfg.MessageProcessor.MaxRetries, 4*time.Minute)
+
+	leadership, stopElection := leader.Start(cfg.LeaderElection.Enabled, deps.Leases, "processor-stale-monitor", cfg.Instance.ID, cfg.LeaderElection.LeaseTTL)
+
+	failureWindow := domain.NewFailureWindow(cfg.Alerting.FailureRateWindow)
+
+	processorService := service.NewProcessorService(
+		processor,
+		deps.Messages,
+		deps.Queue,
+		deps.Idempotency,
+		webhookClient,
+	).WithLeadership(leadership).
+		WithInstanceID(cfg.Instance.ID).
+		WithTopology(cfg.RabbitMQ.Topology).
+		WithWebhookTimeout(cfg.Webhook.Timeout).
+		WithRetryBackoff(domain.NewRetryBackoff(cfg.RabbitMQ.Topology.RetryBackoff, cfg.MessageProcessor.RetryJitter)).
+		WithFailureWindow(failureWindow).
+		WithConcurrency(cfg.MessageProcessor.Workers, cfg.MessageProcessor.Prefetch)
+
+	var alertSinks []ports.AlertSink
+	if cfg.Alerting.LogEnabled {
+		alertSinks = append(alertSinks, alerting.NewLogSink())
+	}
+	if cfg.Alerting.WebhookURL != "" {
+		alertSinks = append(alertSinks, alerting.NewWebhookSink(cfg.Alerting.WebhookURL, 10*time.Second))
+	}
+	if cfg.Alerting.SlackWebhookURL != "" {
+		alertSinks = append(alertSinks, alerting.NewSlackSink(cfg.Alerting.SlackWebhookURL, 10*time.Second))
+	}
+
+	alertService := service.NewAlertService(cfg.Alerting.Interval, alertSinks...).
+		WithLeadership(leadership).
+		WithAlertState(deps.AlertState).
+		WithInstanceID(cfg.Instance.ID).
+		WithRule(service.NewFailureRateRule(failureWindow, cfg.Alerting.FailureRateThreshold, cfg.Alerting.FailureRateMinSample)).
+		WithRule(service.NewCircuitBreakerRule(webhookClient, cfg.Alerting.CircuitOpenDuration)).
+		WithLeaderRule(service.NewDLQDepthRule(deps.Queue, cfg.MessageProcessor.DLQAlertThreshold)).
+		WithLeaderRule(service.NewOutboxBacklogRule(deps.Outbox, cfg.Alerting.OutboxBacklogAge))
+
+	healthService := service.NewHealthService(deps.Messages, deps.Queue, deps.Idempotency).
+		WithLeadership(leadership).
+		WithProcessor(processorService)
+	healthHandler := handlers.NewHealthHandler(healthService)
+
+	dlqHandler := handlers.NewDLQHandler(service.NewDLQService(deps.Queue, deps.Messages))
+
+	router := gin.Default()
+	router.GET("/status", healthHandler.GetStatus)
+	// Replaying and purging change the DLQ, so the DLQ endpoints take the
+	// same API keys as the sender's.
+	dlq := router.Group("/dlq", middleware.APIKeyAuth(auth.NewAPIKeys(cfg.Auth.APIKeys)))
+	{
+		dlq.GET("/messages", dlqHandler.ListMessages)
+		dlq.POST("/replay", dlqHandler.ReplayMessages)
+		dlq.POST("/purge", dlqHandler.PurgeMessages)
+	}
+
+	return &App{
+		Router:       router,
+		processor:    processorService,
+		alerts:       alertService,
+		stopElection: stopElection,
+	}
+}
+
+// Start starts consuming and evaluating alert rules in the background.
+func (a *App) Start() {
+	go a.processor.Start()
+	go a.alerts.Start()
+}
+
+func (a *App) Stop() {
+	a.alerts.Stop()
+	a.processor.Stop()
+	a.stopElection()
+}
@@ -5,16 +5,12 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
-	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/handlers"
-	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/service"
-	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
-	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/infrastructure/webhook"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/app"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/adapters"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/config"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
 
-	"github.com/gin-gonic/gin"
 	redisClient "github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -30,11 +26,6 @@ func main() {
 	defer mongoClient.Disconnect(nil)
 
 	db := mongoClient.Database(cfg.MongoDB.Database)
-	messageRepo := adapters.NewMessageRepository(db)
-	messageQueue, err := adapters.NewMessageQueue(cfg.RabbitMQ.URI)
-	if err != nil {
-		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
-	}
 
 	redisOpts := &redisClient.Options{
 		Addr:     cfg.Redis.URI,
@@ -42,40 +33,44 @@ func main() {
 		DB:       cfg.Redis.DB,
 	}
 	redisConn := redisClient.NewClient(redisOpts)
-	idempotencyService := adapters.NewIdempotencyService(redisConn)
-
-	webhookClient := webhook.NewHTTPWebhookClient(cfg.Webhook.URL, cfg.Webhook.Timeout)
-
-	processor := domain.NewMessageProcessor(cfg.MessageProcessor.MaxRetries, 4*time.Minute)
 
-	processorService := service.NewProcessorService(
-		processor,
-		messageRepo,
-		messageQueue,
-		idempotencyService,
-		webhookClient,
-	)
-
-	healthService := service.NewHealthService(messageRepo, messageQueue, idempotencyService)
-	healthHandler := handlers.NewHealthHandler(healthService)
+	var messageQueue rabbitPort.MessageQueue
+	switch cfg.MessageQueue.Backend {
+	case adapters.QueueBackendRabbitMQ:
+		messageQueue, err = adapters.NewMessageQueue(cfg.RabbitMQ.URI, cfg.RabbitMQ.Topology)
+	case adapters.QueueBackendRedis:
+		messageQueue, err = adapters.NewRedisMessageQueue(redisConn, cfg.RabbitMQ.Topology, cfg.Instance.ID, cfg.MessageQueue.ClaimIdle)
+	default:
+		log.Fatalf("Unknown message queue backend %q", cfg.MessageQueue.Backend)
+	}
+	if err != nil {
+		log.Fatalf("Failed to connect to message queue: %v", err)
+	}
 
-	router := gin.Default()
-	router.GET("/status", healthHandler.GetStatus)
+	processorApp := app.New(cfg, app.Dependencies{
+		Messages:    adapters.NewMessageRepository(db),
+		Outbox:      adapters.NewOutboxRepository(db),
+		Leases:      adapters.NewLeaseStore(db),
+		AlertState:  adapters.NewAlertStateStore(db),
+		Queue:       messageQueue,
+		Idempotency: adapters.NewIdempotencyService(redisConn),
+		Redis:       redisConn,
+	})
 
 	go func() {
-		if err := router.Run(":8081"); err != nil {
+		if err := processorApp.Router.Run(":8081"); err != nil {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
-	go processorService.Start()
+	processorApp.Start()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	log.Println("Shutting down Message Processor Service...")
-	processorService.Stop()
+	processorApp.Stop()
 	messageQueue.Close()
 	if err := redisConn.Close(); err != nil {
 		log.Printf("Error closing Redis connection: %v", err)
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/service"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DLQHandler struct {
+	service *service.DLQService
+}
+
+type ListDLQQuery struct {
+	Offset int `form:"offset,default=0" binding:"min=0"`
+	Limit  int `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+type ReplayDLQRequest struct {
+	IDs                []string   `json:"ids"`
+	All                bool       `json:"all"`
+	Reason             string     `json:"reason" example:"max_retries"`
+	To                 string     `json:"to" example:"+90111111111"`
+	ContentContains    string     `json:"content_contains"`
+	DeadLetteredAfter  *time.Time `json:"dead_lettered_after"`
+	DeadLetteredBefore *time.Time `json:"dead_lettered_before"`
+}
+
+type PurgeDLQRequest struct {
+	ConfirmToken string `json:"confirm_token"`
+}
+
+type PurgeDLQResponse struct {
+	Purged int `json:"purged"`
+}
+
+func NewDLQHandler(service *service.DLQService) *DLQHandler {
+	return &DLQHandler{
+		service: service,
+	}
+}
+
+// ListMessages handles DLQ browse requests
+// @Summary Browse the DLQ
+// @Description Page through DLQ messages without removing them. Each message is shown with its parsed body and headers.
+// @Tags dlq
+// @Produce json
+// @Param offset query int false "Messages to skip" default(0)
+// @Param limit query int false "Messages to return, at most 100" default(20)
+// @Success 200 {object} service.DLQPage
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dlq/messages [get]
+func (h *DLQHandler) ListMessages(c *gin.Context) {
+	var query ListDLQQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be at least 0 and limit between 1 and 100"})
+		return
+	}
+
+	page, err := h.service.List(query.Offset, query.Limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDLQPage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// ReplayMessages handles DLQ replay requests
+// @Summary Replay DLQ messages
+// @Description Move DLQ messages back to the main queue with their retry count reset. Select messages by ID, by filter, or all of them.
+// @Tags dlq
+// @Accept json
+// @Produce json
+// @Param replay body ReplayDLQRequest true "Messages to replay"
+// @Success 200 {object} service.ReplayResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} service.ReplayResult
+// @Router /dlq/replay [post]
+func (h *DLQHandler) ReplayMessages(c *gin.Context) {
+	var req ReplayDLQRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := h.service.Replay(ctx, service.ReplayFilter{
+		IDs:             req.IDs,
+		All:             req.All,
+		Reason:          rabbitPort.FailureReason(req.Reason),
+		To:              req.To,
+		ContentContains: req.ContentContains,
+		After:           req.DeadLetteredAfter,
+		Before:          req.DeadLetteredBefore,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyReplayFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "replayed": result.Replayed, "skipped": result.Skipped})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PurgeMessages handles DLQ purge requests
+// @Summary Purge the DLQ
+// @Description Without a confirm_token, returns a token that is valid for five minutes. With that token, deletes every DLQ message.
+// @Tags dlq
+// @Accept json
+// @Produce json
+// @Param purge body PurgeDLQRequest false "Confirmation token"
+// @Success 200 {object} PurgeDLQResponse
+// @Success 202 {object} service.PurgeConfirmation
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dlq/purge [post]
+func (h *DLQHandler) PurgeMessages(c *gin.Context) {
+	var req PurgeDLQRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	if req.ConfirmToken == "" {
+		confirmation, err := h.service.RequestPurge()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, confirmation)
+		return
+	}
+
+	purged, err := h.service.Purge(req.ConfirmToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPurgeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, PurgeDLQResponse{Purged: purged})
+}
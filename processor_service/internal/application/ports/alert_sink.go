@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+)
+
+type AlertSink interface {
+	Send(ctx context.Context, alert domain.Alert) error
+}
@@ -1,12 +1,23 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type WebhookResponse struct {
 	Message   string `json:"message"`
 	MessageID string `json:"messageId"`
 }
 
+// CircuitState is the state of the webhook circuit breaker. OpenSince is when
+// the breaker last left the closed state, and nil while it is closed.
+type CircuitState struct {
+	State     string     `json:"state"`
+	OpenSince *time.Time `json:"open_since,omitempty"`
+}
+
 type WebhookClient interface {
 	SendMessage(ctx context.Context, content string, to string) (*WebhookResponse, error)
+	CircuitState() CircuitState
 } 
\ No newline at end of file
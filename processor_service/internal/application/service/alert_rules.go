@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+)
+
+// RuleResult is the outcome of one rule evaluation. Summary describes the
+// current state and is used for both firing and resolved alerts.
+type RuleResult struct {
+	Firing    bool
+	Value     float64
+	Threshold float64
+	Summary   string
+}
+
+type AlertRule interface {
+	Name() string
+	Evaluate(ctx context.Context) (RuleResult, error)
+}
+
+type dlqDepthRule struct {
+	queue     rabbitPort.MessageQueue
+	threshold int
+}
+
+// NewDLQDepthRule fires while the DLQ holds more than threshold messages.
+func NewDLQDepthRule(queue rabbitPort.MessageQueue, threshold int) AlertRule {
+	return &dlqDepthRule{queue: queue, threshold: threshold}
+}
+
+func (r *dlqDepthRule) Name() string {
+	return "dlq_depth"
+}
+
+func (r *dlqDepthRule) Evaluate(ctx context.Context) (RuleResult, error) {
+	count, err := r.queue.GetDLQMessageCount()
+	if err != nil {
+		return RuleResult{}, fmt.Errorf("failed to count DLQ messages: %v", err)
+	}
+
+	return RuleResult{
+		Firing:    count > r.threshold,
+		Value:     float64(count),
+		Threshold: float64(r.threshold),
+		Summary:   fmt.Sprintf("DLQ holds %d messages (threshold %d)", count, r.threshold),
+	}, nil
+}
+
+type failureRateRule struct {
+	window     *domain.FailureWindow
+	threshold  float64
+	minSamples int
+}
+
+// NewFailureRateRule fires while the share of failed webhook deliveries in
+// window is above threshold. It stays quiet until the window holds at least
+// minSamples deliveries, so a handful of failures after a quiet period does
+// not page anyone.
+func NewFailureRateRule(window *domain.FailureWindow, threshold float64, minSamples int) AlertRule {
+	return &failureRateRule{window: window, threshold: threshold, minSamples: minSamples}
+}
+
+func (r *failureRateRule) Name() string {
+	return "webhook_failure_rate"
+}
+
+func (r *failureRateRule) Evaluate(ctx context.Context) (RuleResult, error) {
+	rate, samples := r.window.Rate()
+	return RuleResult{
+		Firing:    samples >= r.minSamples && rate > r.threshold,
+		Value:     rate,
+		Threshold: r.threshold,
+		Summary:   fmt.Sprintf("%.0f%% of %d webhook deliveries failed (threshold %.0f%%)", rate*100, samples, r.threshold*100),
+	}, nil
+}
+
+type circuitBreakerRule struct {
+	client  ports.WebhookClient
+	maxOpen time.Duration
+}
+
+// NewCircuitBreakerRule fires once the webhook circuit breaker has been away
+// from the closed state for longer than maxOpen. A breaker that flaps between
+// open and half-open counts as open the whole time.
+func NewCircuitBreakerRule(client ports.WebhookClient, maxOpen time.Duration) AlertRule {
+	return &circuitBreakerRule{client: client, maxOpen: maxOpen}
+}
+
+func (r *circuitBreakerRule) Name() string {
+	return "circuit_breaker_open"
+}
+
+func (r *circuitBreakerRule) Evaluate(ctx context.Context) (RuleResult, error) {
+	state := r.client.CircuitState()
+	if state.OpenSince == nil {
+		return RuleResult{
+			Threshold: r.maxOpen.Seconds(),
+			Summary:   fmt.Sprintf("webhook circuit breaker is %s", state.State),
+		}, nil
+	}
+
+	open := time.Since(*state.OpenSince)
+	return RuleResult{
+		Firing:    open > r.maxOpen,
+		Value:     open.Seconds(),
+		Threshold: r.maxOpen.Seconds(),
+		Summary:   fmt.Sprintf("webhook circuit breaker has been %s for %s (threshold %s)", state.State, open.Round(time.Second), r.maxOpen),
+	}, nil
+}
+
+type outboxBacklogRule struct {
+	outbox interfaces.OutboxRepository
+	maxAge time.Duration
+}
+
+// NewOutboxBacklogRule fires while the oldest unpublished outbox event is
+// older than maxAge, which means the relay is down or cannot keep up.
+func NewOutboxBacklogRule(outbox interfaces.OutboxRepository, maxAge time.Duration) AlertRule {
+	return &outboxBacklogRule{outbox: outbox, maxAge: maxAge}
+}
+
+func (r *outboxBacklogRule) Name() string {
+	return "outbox_backlog_age"
+}
+
+func (r *outboxBacklogRule) Evaluate(ctx context.Context) (RuleResult, error) {
+	event, err := r.outbox.OldestEvent(ctx)
+	if err != nil {
+		return RuleResult{}, fmt.Errorf("failed to get oldest outbox event: %v", err)
+	}
+	if event == nil {
+		return RuleResult{
+			Threshold: r.maxAge.Seconds(),
+			Summary:   "outbox is empty",
+		}, nil
+	}
+
+	age := time.Since(event.CreatedAt)
+	return RuleResult{
+		Firing:    age > r.maxAge,
+		Value:     age.Seconds(),
+		Threshold: r.maxAge.Seconds(),
+		Summary:   fmt.Sprintf("oldest outbox event %s is %s old (threshold %s)", event.ID.Hex(), age.Round(time.Second), r.maxAge),
+	}, nil
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	mongoPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+)
+
+type alertRule struct {
+	rule       AlertRule
+	leaderOnly bool
+}
+
+// AlertService evaluates alert rules on a schedule and notifies the sinks
+// when a rule starts firing and when it resolves. A rule that keeps firing is
+// not reported again. The alerts of leader rules are kept in the shared alert
+// state when one is set, so they survive a change of leader.
+type AlertService struct {
+	interval   time.Duration
+	sinks      []ports.AlertSink
+	rules      []alertRule
+	instanceID string
+	leadership leader.Leadership
+	state      mongoPort.AlertStateStore
+	now        func() time.Time
+
+	mu     sync.Mutex
+	active map[string]domain.Alert
+	done   chan bool
+}
+
+func NewAlertService(interval time.Duration, sinks ...ports.AlertSink) *AlertService {
+	return &AlertService{
+		interval:   interval,
+		sinks:      sinks,
+		leadership: leader.AlwaysLeader{Name: "processor-alerts"},
+		now:        time.Now,
+		active:     make(map[string]domain.Alert),
+		done:       make(chan bool),
+	}
+}
+
+// WithLeadership makes rules added with WithLeaderRule run only while this
+// instance holds the given leadership.
+func (s *AlertService) WithLeadership(leadership leader.Leadership) *AlertService {
+	s.leadership = leadership
+	return s
+}
+
+// WithAlertState keeps the alerts of leader rules in store, so a new leader
+// resolves the alerts the previous one fired instead of firing them again.
+func (s *AlertService) WithAlertState(store mongoPort.AlertStateStore) *AlertService {
+	s.state = store
+	return s
+}
+
+// WithInstanceID sets the instance ID recorded on alerts.
+func (s *AlertService) WithInstanceID(id string) *AlertService {
+	s.instanceID = id
+	return s
+}
+
+// WithRule adds a rule that every instance evaluates, for state local to the
+// instance such as its circuit breaker.
+func (s *AlertService) WithRule(rule AlertRule) *AlertService {
+	s.rules = append(s.rules, alertRule{rule: rule})
+	return s
+}
+
+// WithLeaderRule adds a rule about shared state, such as the DLQ, that only
+// the leader evaluates so each alert is sent once.
+func (s *AlertService) WithLeaderRule(rule AlertRule) *AlertService {
+	s.rules = append(s.rules, alertRule{rule: rule, leaderOnly: true})
+	return s
+}
+
+func (s *AlertService) Start() {
+	log.Printf("Alert service started with %d rules and %d sinks", len(s.rules), len(s.sinks))
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			s.evaluate(ctx)
+			cancel()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *AlertService) Stop() {
+	close(s.done)
+}
+
+func (s *AlertService) evaluate(ctx context.Context) {
+	isLeader := s.leadership.IsLeader()
+
+	for _, r := range s.rules {
+		// A follower forgets the leader rules it fired, as the leader now
+		// reports them and may resolve them.
+		if r.leaderOnly && !isLeader {
+			s.forget(r.rule.Name())
+			continue
+		}
+
+		result, err := r.rule.Evaluate(ctx)
+		if err != nil {
+			log.Printf("Failed to evaluate alert rule %s: %v", r.rule.Name(), err)
+			continue
+		}
+
+		if r.leaderOnly && s.state != nil {
+			if alert, ok := s.transitionShared(ctx, r.rule.Name(), result); ok {
+				s.notify(ctx, alert)
+			}
+			continue
+		}
+		if alert, ok := s.transition(r.rule.Name(), result); ok {
+			s.notify(ctx, alert)
+		}
+	}
+}
+
+func (s *AlertService) forget(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.active, name)
+}
+
+// transitionShared is transition for a leader rule whose alert is kept in
+// the shared alert state. Only the instance that creates or deletes the state
+// returns the alert, so an alert is sent once even if two instances briefly
+// both think they lead.
+func (s *AlertService) transitionShared(ctx context.Context, name string, result RuleResult) (domain.Alert, bool) {
+	state, err := s.state.LoadAlertState(ctx, name)
+	if err != nil {
+		log.Printf("Failed to load the state of alert rule %s: %v", name, err)
+		return domain.Alert{}, false
+	}
+
+	switch {
+	case result.Firing && state == nil:
+		alert := s.firing(name, result)
+		created, err := s.state.CreateAlertState(ctx, &models.AlertState{
+			Rule:      alert.Rule,
+			Summary:   alert.Summary,
+			Value:     alert.Value,
+			Threshold: alert.Threshold,
+			Instance:  alert.Instance,
+			StartsAt:  alert.StartsAt,
+		})
+		if err != nil {
+			log.Printf("Failed to save the state of alert rule %s: %v", name, err)
+			return domain.Alert{}, false
+		}
+		return alert, created
+	case !result.Firing && state != nil:
+		deleted, err := s.state.DeleteAlertState(ctx, name)
+		if err != nil {
+			log.Printf("Failed to delete the state of alert rule %s: %v", name, err)
+			return domain.Alert{}, false
+		}
+		endsAt := s.now()
+		return domain.Alert{
+			Rule:      name,
+			Status:    domain.AlertResolved,
+			Summary:   result.Summary,
+			Value:     result.Value,
+			Threshold: state.Threshold,
+			Instance:  state.Instance,
+			StartsAt:  state.StartsAt,
+			EndsAt:    &endsAt,
+		}, deleted
+	default:
+		return domain.Alert{}, false
+	}
+}
+
+// transition updates the active alerts with a rule result and returns the
+// alert to send, if the rule started firing or resolved.
+func (s *AlertService) transition(name string, result RuleResult) (domain.Alert, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active, firing := s.active[name]
+	switch {
+	case result.Firing && !firing:
+		alert := s.firing(name, result)
+		s.active[name] = alert
+		return alert, true
+	case !result.Firing && firing:
+		delete(s.active, name)
+		endsAt := s.now()
+		active.Status = domain.AlertResolved
+		active.Summary = result.Summary
+		active.Value = result.Value
+		active.EndsAt = &endsAt
+		return active, true
+	default:
+		return domain.Alert{}, false
+	}
+}
+
+func (s *AlertService) firing(name string, result RuleResult) domain.Alert {
+	return domain.Alert{
+		Rule:      name,
+		Status:    domain.AlertFiring,
+		Summary:   result.Summary,
+		Value:     result.Value,
+		Threshold: result.Threshold,
+		Instance:  s.instanceID,
+		StartsAt:  s.now(),
+	}
+}
+
+func (s *AlertService) notify(ctx context.Context, alert domain.Alert) {
+	for _, sink := range s.sinks {
+		if err := sink.Send(ctx, alert); err != nil {
+			log.Printf("Failed to send %s alert %s: %v", alert.Status, alert.Rule, err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/mocks"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/adapters"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubRule struct {
+	name   string
+	result RuleResult
+	err    error
+	calls  int
+}
+
+func (r *stubRule) Name() string {
+	return r.name
+}
+
+func (r *stubRule) Evaluate(ctx context.Context) (RuleResult, error) {
+	r.calls++
+	return r.result, r.err
+}
+
+func TestAlertService_FiresOnceAndResolves(t *testing.T) {
+	sink := new(mocks.MockAlertSink)
+	rule := &stubRule{name: "dlq_depth"}
+	service := NewAlertService(time.Minute, sink).WithInstanceID("processor-1").WithRule(rule)
+
+	startsAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return startsAt }
+
+	service.evaluate(context.Background())
+	sink.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+
+	rule.result = RuleResult{Firing: true, Value: 12, Threshold: 10, Summary: "DLQ holds 12 messages"}
+	sink.On("Send", mock.Anything, domain.Alert{
+		Rule:      "dlq_depth",
+		Status:    domain.AlertFiring,
+		Summary:   "DLQ holds 12 messages",
+		Value:     12,
+		Threshold: 10,
+		Instance:  "processor-1",
+		StartsAt:  startsAt,
+	}).Return(nil).Once()
+
+	service.evaluate(context.Background())
+	service.evaluate(context.Background())
+	sink.AssertNumberOfCalls(t, "Send", 1)
+
+	endsAt := startsAt.Add(5 * time.Minute)
+	service.now = func() time.Time { return endsAt }
+	rule.result = RuleResult{Value: 3, Threshold: 10, Summary: "DLQ holds 3 messages"}
+	sink.On("Send", mock.Anything, domain.Alert{
+		Rule:      "dlq_depth",
+		Status:    domain.AlertResolved,
+		Summary:   "DLQ holds 3 messages",
+		Value:     3,
+		Threshold: 10,
+		Instance:  "processor-1",
+		StartsAt:  startsAt,
+		EndsAt:    &endsAt,
+	}).Return(nil).Once()
+
+	service.evaluate(context.Background())
+	service.evaluate(context.Background())
+	sink.AssertExpectations(t)
+	sink.AssertNumberOfCalls(t, "Send", 2)
+}
+
+func TestAlertService_SinkErrorDoesNotStopOtherSinks(t *testing.T) {
+	failing := new(mocks.MockAlertSink)
+	working := new(mocks.MockAlertSink)
+	rule := &stubRule{name: "circuit_breaker_open", result: RuleResult{Firing: true}}
+	service := NewAlertService(time.Minute, failing, working).WithRule(rule)
+
+	failing.On("Send", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	working.On("Send", mock.Anything, mock.Anything).Return(nil)
+
+	service.evaluate(context.Background())
+	failing.AssertNumberOfCalls(t, "Send", 1)
+	working.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestAlertService_RuleErrorKeepsState(t *testing.T) {
+	sink := new(mocks.MockAlertSink)
+	rule := &stubRule{name: "outbox_backlog_age", result: RuleResult{Firing: true}}
+	service := NewAlertService(time.Minute, sink).WithRule(rule)
+	sink.On("Send", mock.Anything, mock.Anything).Return(nil)
+
+	service.evaluate(context.Background())
+	rule.err = errors.New("mongo down")
+	rule.result = RuleResult{}
+	service.evaluate(context.Background())
+
+	sink.AssertNumberOfCalls(t, "Send", 1)
+	assert.Contains(t, service.active, "outbox_backlog_age")
+}
+
+func TestAlertService_LeaderRules(t *testing.T) {
+	sink := new(mocks.MockAlertSink)
+	leadership := new(mocks.MockLeadership)
+	local := &stubRule{name: "circuit_breaker_open"}
+	shared := &stubRule{name: "dlq_depth"}
+	service := NewAlertService(time.Minute, sink).
+		WithLeadership(leadership).
+		WithRule(local).
+		WithLeaderRule(shared)
+
+	leadership.On("IsLeader").Return(false).Once()
+	service.evaluate(context.Background())
+	assert.Equal(t, 1, local.calls)
+	assert.Equal(t, 0, shared.calls)
+
+	leadership.On("IsLeader").Return(true).Once()
+	service.evaluate(context.Background())
+	assert.Equal(t, 2, local.calls)
+	assert.Equal(t, 1, shared.calls)
+}
+
+func TestAlertService_LeaderRulesSurviveFailover(t *testing.T) {
+	store := adapters.NewMemoryAlertStateStore(adapters.NewMemoryDatabase())
+	rule := &stubRule{name: "dlq_depth", result: RuleResult{Firing: true, Value: 12, Threshold: 10}}
+
+	oldSink, newSink := new(mocks.MockAlertSink), new(mocks.MockAlertSink)
+	oldLeadership, newLeadership := new(mocks.MockLeadership), new(mocks.MockLeadership)
+	oldLeader := NewAlertService(time.Minute, oldSink).WithLeadership(oldLeadership).WithAlertState(store).WithInstanceID("processor-1").WithLeaderRule(rule)
+	newLeader := NewAlertService(time.Minute, newSink).WithLeadership(newLeadership).WithAlertState(store).WithInstanceID("processor-2").WithLeaderRule(rule)
+
+	startsAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	oldLeader.now = func() time.Time { return startsAt }
+	oldSink.On("Send", mock.Anything, mock.MatchedBy(func(alert domain.Alert) bool {
+		return alert.Status == domain.AlertFiring
+	})).Return(nil).Once()
+	oldLeadership.On("IsLeader").Return(true).Once()
+	oldLeader.evaluate(context.Background())
+
+	// The new leader takes over while the rule is still firing, and reports
+	// only its resolve, for the alert the old leader fired.
+	oldLeadership.On("IsLeader").Return(false).Once()
+	oldLeader.evaluate(context.Background())
+	newLeadership.On("IsLeader").Return(true).Twice()
+	newLeader.evaluate(context.Background())
+	newSink.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+
+	rule.result = RuleResult{Value: 3, Threshold: 10}
+	newSink.On("Send", mock.Anything, mock.MatchedBy(func(alert domain.Alert) bool {
+		return alert.Status == domain.AlertResolved && alert.Instance == "processor-1" && alert.StartsAt.Equal(startsAt)
+	})).Return(nil).Once()
+	newLeader.evaluate(context.Background())
+	newSink.AssertExpectations(t)
+
+	// A new incident after the old leader takes over again is reported.
+	rule.result = RuleResult{Firing: true, Value: 15, Threshold: 10}
+	oldSink.On("Send", mock.Anything, mock.MatchedBy(func(alert domain.Alert) bool {
+		return alert.Status == domain.AlertFiring && alert.Value == 15
+	})).Return(nil).Once()
+	oldLeadership.On("IsLeader").Return(true).Once()
+	oldLeader.evaluate(context.Background())
+	oldSink.AssertExpectations(t)
+}
+
+func TestAlertService_FollowerForgetsLeaderRules(t *testing.T) {
+	sink := new(mocks.MockAlertSink)
+	leadership := new(mocks.MockLeadership)
+	rule := &stubRule{name: "dlq_depth", result: RuleResult{Firing: true}}
+	service := NewAlertService(time.Minute, sink).WithLeadership(leadership).WithLeaderRule(rule)
+	sink.On("Send", mock.Anything, mock.Anything).Return(nil)
+
+	leadership.On("IsLeader").Return(true).Once()
+	service.evaluate(context.Background())
+	leadership.On("IsLeader").Return(false).Once()
+	service.evaluate(context.Background())
+	assert.NotContains(t, service.active, "dlq_depth")
+
+	leadership.On("IsLeader").Return(true).Once()
+	service.evaluate(context.Background())
+	sink.AssertNumberOfCalls(t, "Send", 2)
+}
+
+func TestAlertRules(t *testing.T) {
+	t.Run("dlq depth", func(t *testing.T) {
+		queue := new(mocks.MockMessageQueue)
+		queue.On("GetDLQMessageCount").Return(11, nil).Once()
+		queue.On("GetDLQMessageCount").Return(10, nil).Once()
+		rule := NewDLQDepthRule(queue, 10)
+
+		result, err := rule.Evaluate(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, result.Firing)
+		assert.Equal(t, 11.0, result.Value)
+
+		result, err = rule.Evaluate(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, result.Firing)
+	})
+
+	t.Run("failure rate", func(t *testing.T) {
+		window := domain.NewFailureWindow(time.Minute)
+		rule := NewFailureRateRule(window, 0.5, 4)
+
+		window.Record(false)
+		window.Record(false)
+		window.Record(false)
+		result, _ := rule.Evaluate(context.Background())
+		assert.False(t, result.Firing, "too few samples")
+
+		window.Record(true)
+		result, _ = rule.Evaluate(context.Background())
+		assert.True(t, result.Firing)
+		assert.Equal(t, 0.75, result.Value)
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		client := new(mocks.MockWebhookClient)
+		recently := time.Now().Add(-time.Minute)
+		longAgo := time.Now().Add(-5 * time.Minute)
+		client.On("CircuitState").Return(ports.CircuitState{State: "closed"}).Once()
+		client.On("CircuitState").Return(ports.CircuitState{State: "open", OpenSince: &recently}).Once()
+		client.On("CircuitState").Return(ports.CircuitState{State: "half-open", OpenSince: &longAgo}).Once()
+		rule := NewCircuitBreakerRule(client, 2*time.Minute)
+
+		for _, firing := range []bool{false, false, true} {
+			result, err := rule.Evaluate(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, firing, result.Firing, result.Summary)
+		}
+	})
+
+	t.Run("outbox backlog", func(t *testing.T) {
+		outbox := new(mocks.MockOutboxRepository)
+		outbox.On("OldestEvent", mock.Anything).Return(nil, nil).Once()
+		outbox.On("OldestEvent", mock.Anything).Return(&models.OutboxEvent{ID: primitive.NewObjectID(), CreatedAt: time.Now().Add(-10 * time.Minute)}, nil).Once()
+		outbox.On("OldestEvent", mock.Anything).Return(nil, errors.New("mongo down")).Once()
+		rule := NewOutboxBacklogRule(outbox, 5*time.Minute)
+
+		result, err := rule.Evaluate(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, result.Firing)
+
+		result, err = rule.Evaluate(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, result.Firing)
+
+		_, err = rule.Evaluate(context.Background())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	MaxDLQPageSize = 100
+	maxDLQBrowse   = 10000
+	purgeTokenTTL  = 5 * time.Minute
+)
+
+var (
+	ErrInvalidDLQPage    = errors.New("invalid DLQ page")
+	ErrEmptyReplayFilter = errors.New("replay needs message IDs, a filter or all")
+	ErrInvalidPurgeToken = errors.New("invalid or expired purge confirmation token")
+)
+
+// DLQEntry is a DLQ message with its body parsed as a QueueMessage. Message
+// is nil and ParseError set when the body is not a valid QueueMessage.
+// Failure is nil for messages dead-lettered without failure headers.
+type DLQEntry struct {
+	MessageID      string                  `json:"message_id"`
+	Message        *contracts.QueueMessage `json:"message,omitempty"`
+	ParseError     string                  `json:"parse_error,omitempty"`
+	Failure        *rabbitPort.Failure     `json:"failure,omitempty"`
+	Deaths         []rabbitPort.Death      `json:"deaths,omitempty"`
+	Headers        map[string]interface{}  `json:"headers,omitempty"`
+	DeadLetteredAt time.Time               `json:"dead_lettered_at"`
+}
+
+type DLQPage struct {
+	Total    int        `json:"total"`
+	Offset   int        `json:"offset"`
+	Limit    int        `json:"limit"`
+	Messages []DLQEntry `json:"messages"`
+}
+
+// ReplayFilter selects the DLQ messages to replay. A message must match every
+// field that is set; All selects every message.
+type ReplayFilter struct {
+	IDs             []string
+	All             bool
+	Reason          rabbitPort.FailureReason
+	To              string
+	ContentContains string
+	After           *time.Time
+	Before          *time.Time
+}
+
+type ReplayResult struct {
+	Replayed []string          `json:"replayed"`
+	Skipped  map[string]string `json:"skipped,omitempty"`
+}
+
+type PurgeConfirmation struct {
+	Token     string    `json:"confirm_token"`
+	Messages  int       `json:"messages"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DLQService lets operators inspect the DLQ and move messages out of it.
+// Purge confirmation tokens are kept in memory, so the confirming request
+// must reach the instance that issued the token.
+type DLQService struct {
+	queue      rabbitPort.MessageQueue
+	repository interfaces.MessageRepository
+
+	mu          sync.Mutex
+	purgeToken  string
+	purgeExpiry time.Time
+}
+
+func NewDLQService(queue rabbitPort.MessageQueue, repository interfaces.MessageRepository) *DLQService {
+	return &DLQService{
+		queue:      queue,
+		repository: repository,
+	}
+}
+
+func (s *DLQService) List(offset, limit int) (*DLQPage, error) {
+	if offset < 0 || limit < 1 || limit > MaxDLQPageSize || offset+limit > maxDLQBrowse {
+		return nil, fmt.Errorf("%w: offset must be at least 0, limit between 1 and %d, and offset+limit at most %d", ErrInvalidDLQPage, MaxDLQPageSize, maxDLQBrowse)
+	}
+
+	deadLetters, total, err := s.queue.BrowseDLQ(offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to browse DLQ: %v", err)
+	}
+
+	page := &DLQPage{
+		Total:    total,
+		Offset:   offset,
+		Limit:    limit,
+		Messages: make([]DLQEntry, 0, len(deadLetters)),
+	}
+	for _, deadLetter := range deadLetters {
+		page.Messages = append(page.Messages, newDLQEntry(deadLetter))
+	}
+	return page, nil
+}
+
+// Replay moves the matching DLQ messages back to the main queue. Each
+// message's retry count is reset before it is published, so the processor
+// gives it the full number of attempts again. Messages that cannot be parsed
+// or no longer exist stay in the DLQ and are reported as skipped.
+func (s *DLQService) Replay(ctx context.Context, filter ReplayFilter) (*ReplayResult, error) {
+	if !filter.All && len(filter.IDs) == 0 && filter.Reason == "" && filter.To == "" && filter.ContentContains == "" && filter.After == nil && filter.Before == nil {
+		return nil, ErrEmptyReplayFilter
+	}
+
+	result := &ReplayResult{Replayed: []string{}, Skipped: map[string]string{}}
+	replayed, err := s.queue.ReplayDLQ(ctx, func(deadLetter rabbitPort.DeadLetter) (*contracts.QueueMessage, error) {
+		entry := newDLQEntry(deadLetter)
+		if !filter.matches(entry) {
+			return nil, nil
+		}
+		if entry.Message == nil {
+			result.Skipped[entry.MessageID] = "body is not a valid queue message: " + entry.ParseError
+			return nil, nil
+		}
+
+		id, err := primitive.ObjectIDFromHex(entry.Message.ID)
+		if err != nil {
+			result.Skipped[entry.Message.ID] = "invalid message ID"
+			return nil, nil
+		}
+		msg, err := s.repository.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get message %s: %v", entry.Message.ID, err)
+		}
+		if msg == nil {
+			result.Skipped[entry.Message.ID] = "message not found"
+			return nil, nil
+		}
+		if err := s.repository.ResetRetries(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to reset retries of message %s: %v", entry.Message.ID, err)
+		}
+
+		result.Replayed = append(result.Replayed, entry.Message.ID)
+		return &contracts.QueueMessage{
+			ID:            msg.ID.Hex(),
+			Content:       msg.Content,
+			To:            msg.To,
+			CorrelationID: msg.CorrelationID,
+		}, nil
+	})
+	// The last message handed back may have failed to publish.
+	result.Replayed = result.Replayed[:replayed]
+	if len(result.Skipped) == 0 {
+		result.Skipped = nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("replay stopped after %d messages: %v", len(result.Replayed), err)
+	}
+
+	log.Printf("Replayed %d messages from the DLQ", len(result.Replayed))
+	return result, nil
+}
+
+// RequestPurge issues a token that confirms a purge within the next five
+// minutes. A new request replaces the previous token.
+func (s *DLQService) RequestPurge() (*PurgeConfirmation, error) {
+	count, err := s.queue.GetDLQMessageCount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count DLQ messages: %v", err)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeToken = hex.EncodeToString(buf)
+	s.purgeExpiry = time.Now().Add(purgeTokenTTL)
+	return &PurgeConfirmation{Token: s.purgeToken, Messages: count, ExpiresAt: s.purgeExpiry}, nil
+}
+
+// Purge deletes every DLQ message if token is the current confirmation
+// token. A token can be used once.
+func (s *DLQService) Purge(token string) (int, error) {
+	s.mu.Lock()
+	valid := token != "" && token == s.purgeToken && time.Now().Before(s.purgeExpiry)
+	if valid {
+		s.purgeToken = ""
+	}
+	s.mu.Unlock()
+
+	if !valid {
+		return 0, ErrInvalidPurgeToken
+	}
+
+	purged, err := s.queue.PurgeDLQ()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Purged %d messages from the DLQ", purged)
+	return purged, nil
+}
+
+func newDLQEntry(deadLetter rabbitPort.DeadLetter) DLQEntry {
+	entry := DLQEntry{
+		MessageID:      deadLetter.MessageID,
+		Failure:        rabbitPort.ParseFailure(deadLetter.Headers),
+		Deaths:         rabbitPort.ParseDeaths(deadLetter.Headers),
+		Headers:        deadLetter.Headers,
+		DeadLetteredAt: deadLetter.Timestamp,
+	}
+
+	msg, err := rabbitPort.DecodeMessage(deadLetter.ContentType, deadLetter.Headers, deadLetter.Body)
+	if err != nil {
+		entry.ParseError = err.Error()
+		return entry
+	}
+	entry.Message = &msg
+	if entry.MessageID == "" {
+		entry.MessageID = msg.ID
+	}
+	return entry
+}
+
+func (f ReplayFilter) matches(entry DLQEntry) bool {
+	if len(f.IDs) > 0 && !containsString(f.IDs, entry.MessageID) {
+		return false
+	}
+	if f.Reason != "" && (entry.Failure == nil || entry.Failure.Reason != f.Reason) {
+		return false
+	}
+	if f.To != "" && (entry.Message == nil || entry.Message.To != f.To) {
+		return false
+	}
+	if f.ContentContains != "" && (entry.Message == nil || !strings.Contains(entry.Message.Content, f.ContentContains)) {
+		return false
+	}
+	if f.After != nil && !entry.DeadLetteredAt.After(*f.After) {
+		return false
+	}
+	if f.Before != nil && !entry.DeadLetteredAt.Before(*f.Before) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
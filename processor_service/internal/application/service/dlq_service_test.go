@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/mocks"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newDeadLetter(id primitive.ObjectID, to string, at time.Time) rabbitPort.DeadLetter {
+	return rabbitPort.DeadLetter{
+		Body:      []byte(`{"id":"` + id.Hex() + `","content":"hello","to":"` + to + `","retry":5}`),
+		Headers:   amqp.Table{"x-first-death-reason": "rejected"},
+		Timestamp: at,
+	}
+}
+
+func TestDLQService_List(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	service := NewDLQService(mockQueue, new(mocks.MockMessageRepository))
+
+	id := primitive.NewObjectID()
+	at := time.Now()
+	mockQueue.On("BrowseDLQ", 10, 2).Return([]rabbitPort.DeadLetter{
+		newDeadLetter(id, "+905321234567", at),
+		{MessageID: "broken", Body: []byte("not json"), Timestamp: at},
+	}, 12, nil)
+
+	page, err := service.List(10, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, page.Total)
+	assert.Len(t, page.Messages, 2)
+
+	assert.Equal(t, id.Hex(), page.Messages[0].MessageID)
+	assert.Equal(t, &contracts.QueueMessage{ID: id.Hex(), Content: "hello", To: "+905321234567", Retry: 5}, page.Messages[0].Message)
+	assert.Equal(t, "rejected", page.Messages[0].Headers["x-first-death-reason"])
+	assert.Nil(t, page.Messages[0].Failure)
+
+	assert.Equal(t, "broken", page.Messages[1].MessageID)
+	assert.Nil(t, page.Messages[1].Message)
+	assert.NotEmpty(t, page.Messages[1].ParseError)
+}
+
+func TestDLQService_List_InvalidPage(t *testing.T) {
+	service := NewDLQService(new(mocks.MockMessageQueue), new(mocks.MockMessageRepository))
+
+	for _, page := range [][2]int{{-1, 10}, {0, 0}, {0, MaxDLQPageSize + 1}, {maxDLQBrowse, 1}} {
+		_, err := service.List(page[0], page[1])
+		assert.ErrorIs(t, err, ErrInvalidDLQPage)
+	}
+}
+
+func TestDLQService_Replay(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	mockRepo := new(mocks.MockMessageRepository)
+	service := NewDLQService(mockQueue, mockRepo)
+
+	now := time.Now()
+	selected := primitive.NewObjectID()
+	otherRecipient := primitive.NewObjectID()
+	missing := primitive.NewObjectID()
+	mockQueue.SetDeadLetters(
+		newDeadLetter(selected, "+905321234567", now),
+		newDeadLetter(otherRecipient, "+905320000000", now),
+		newDeadLetter(missing, "+905321234567", now),
+		rabbitPort.DeadLetter{MessageID: "broken", Body: []byte("not json")},
+	)
+	mockQueue.On("ReplayDLQ", mock.Anything, mock.Anything).Return(0, nil)
+
+	mockRepo.On("GetByID", mock.Anything, selected).Return(&models.Message{ID: selected, Content: "hello", To: "+905321234567", RetryCount: 5}, nil)
+	mockRepo.On("GetByID", mock.Anything, missing).Return(nil, nil)
+	mockRepo.On("ResetRetries", mock.Anything, selected).Return(nil)
+
+	result, err := service.Replay(context.Background(), ReplayFilter{To: "+905321234567"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{selected.Hex()}, result.Replayed)
+	assert.Equal(t, map[string]string{missing.Hex(): "message not found"}, result.Skipped)
+	assert.Equal(t, []contracts.QueueMessage{{ID: selected.Hex(), Content: "hello", To: "+905321234567"}}, mockQueue.Replayed)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, otherRecipient)
+}
+
+func TestDLQService_Replay_ByIDAndTime(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	mockRepo := new(mocks.MockMessageRepository)
+	service := NewDLQService(mockQueue, mockRepo)
+
+	now := time.Now()
+	old := primitive.NewObjectID()
+	recent := primitive.NewObjectID()
+	mockQueue.SetDeadLetters(
+		newDeadLetter(old, "+905321234567", now.Add(-2*time.Hour)),
+		newDeadLetter(recent, "+905321234567", now),
+	)
+	mockQueue.On("ReplayDLQ", mock.Anything, mock.Anything).Return(0, nil)
+	mockRepo.On("GetByID", mock.Anything, recent).Return(&models.Message{ID: recent}, nil)
+	mockRepo.On("ResetRetries", mock.Anything, recent).Return(nil)
+
+	after := now.Add(-time.Hour)
+	result, err := service.Replay(context.Background(), ReplayFilter{IDs: []string{old.Hex(), recent.Hex()}, After: &after})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{recent.Hex()}, result.Replayed)
+	assert.Nil(t, result.Skipped)
+}
+
+func TestDLQService_Replay_ByReason(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	mockRepo := new(mocks.MockMessageRepository)
+	service := NewDLQService(mockQueue, mockRepo)
+
+	now := time.Now()
+	stale := newDeadLetter(primitive.NewObjectID(), "+905321234567", now)
+	stale.Headers = rabbitPort.Failure{Reason: rabbitPort.FailureStale, FirstSeenAt: now, DeadLetteredAt: now}.Headers()
+	maxRetriesID := primitive.NewObjectID()
+	maxRetries := newDeadLetter(maxRetriesID, "+905321234567", now)
+	maxRetries.Headers = rabbitPort.Failure{Reason: rabbitPort.FailureMaxRetries, Attempts: 5, FirstSeenAt: now, DeadLetteredAt: now}.Headers()
+	mockQueue.SetDeadLetters(stale, maxRetries)
+	mockQueue.On("ReplayDLQ", mock.Anything, mock.Anything).Return(0, nil)
+	mockRepo.On("GetByID", mock.Anything, maxRetriesID).Return(&models.Message{ID: maxRetriesID}, nil)
+	mockRepo.On("ResetRetries", mock.Anything, maxRetriesID).Return(nil)
+
+	result, err := service.Replay(context.Background(), ReplayFilter{Reason: rabbitPort.FailureMaxRetries})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{maxRetriesID.Hex()}, result.Replayed)
+}
+
+func TestDLQService_Replay_StopsOnRepositoryError(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	mockRepo := new(mocks.MockMessageRepository)
+	service := NewDLQService(mockQueue, mockRepo)
+
+	id := primitive.NewObjectID()
+	mockQueue.SetDeadLetters(newDeadLetter(id, "+905321234567", time.Now()))
+	mockQueue.On("ReplayDLQ", mock.Anything, mock.Anything).Return(0, nil)
+	mockRepo.On("GetByID", mock.Anything, id).Return(&models.Message{ID: id}, nil)
+	mockRepo.On("ResetRetries", mock.Anything, id).Return(errors.New("mongo down"))
+
+	result, err := service.Replay(context.Background(), ReplayFilter{All: true})
+	assert.Error(t, err)
+	assert.Empty(t, result.Replayed)
+	assert.Empty(t, mockQueue.Replayed)
+}
+
+func TestDLQService_Replay_RequiresFilter(t *testing.T) {
+	service := NewDLQService(new(mocks.MockMessageQueue), new(mocks.MockMessageRepository))
+
+	_, err := service.Replay(context.Background(), ReplayFilter{})
+	assert.ErrorIs(t, err, ErrEmptyReplayFilter)
+}
+
+func TestDLQService_Purge(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	service := NewDLQService(mockQueue, new(mocks.MockMessageRepository))
+
+	mockQueue.On("GetDLQMessageCount").Return(7, nil)
+	mockQueue.On("PurgeDLQ").Return(7, nil).Once()
+
+	_, err := service.Purge("")
+	assert.ErrorIs(t, err, ErrInvalidPurgeToken)
+
+	confirmation, err := service.RequestPurge()
+	assert.NoError(t, err)
+	assert.Equal(t, 7, confirmation.Messages)
+	assert.NotEmpty(t, confirmation.Token)
+
+	_, err = service.Purge("wrong")
+	assert.ErrorIs(t, err, ErrInvalidPurgeToken)
+
+	purged, err := service.Purge(confirmation.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, purged)
+
+	_, err = service.Purge(confirmation.Token)
+	assert.ErrorIs(t, err, ErrInvalidPurgeToken, "a token can only be used once")
+
+	mockQueue.AssertExpectations(t)
+}
+
+func TestDLQService_Purge_ExpiredToken(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	service := NewDLQService(mockQueue, new(mocks.MockMessageRepository))
+
+	mockQueue.On("GetDLQMessageCount").Return(1, nil)
+	confirmation, err := service.RequestPurge()
+	assert.NoError(t, err)
+
+	service.purgeExpiry = time.Now().Add(-time.Second)
+	_, err = service.Purge(confirmation.Token)
+	assert.ErrorIs(t, err, ErrInvalidPurgeToken)
+	mockQueue.AssertNotCalled(t, "PurgeDLQ")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
 	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
 	redisPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/redis/interfaces"
@@ -14,12 +15,17 @@ type HealthStatus struct {
 	RabbitMQ  bool `json:"rabbitmq"`
 	Redis     bool `json:"redis"`
 	Service   bool `json:"service"`
+	Leader    *leader.Status `json:"leader,omitempty"`
+	RabbitMQConnection *rabbitPort.ConnectionState `json:"rabbitmq_connection,omitempty"`
+	Consumer  *ConsumerStatus `json:"consumer,omitempty"`
 }
 
 type HealthService struct {
 	repository         interfaces.MessageRepository
 	queue             rabbitPort.MessageQueue
 	idempotencyService redisPort.IdempotencyServicePort
+	leadership         leader.Leadership
+	processor          *ProcessorService
 }
 
 func NewHealthService(
@@ -34,6 +40,17 @@ func NewHealthService(
 	}
 }
 
+func (s *HealthService) WithLeadership(leadership leader.Leadership) *HealthService {
+	s.leadership = leadership
+	return s
+}
+
+// WithProcessor adds the processor's worker pool to the health status.
+func (s *HealthService) WithProcessor(processor *ProcessorService) *HealthService {
+	s.processor = processor
+	return s
+}
+
 func (s *HealthService) CheckHealth() HealthStatus {
 	status := HealthStatus{
 		Service: true,
@@ -45,11 +62,23 @@ func (s *HealthService) CheckHealth() HealthStatus {
 	_, err := s.repository.ListMessages(ctx)
 	status.MongoDB = err == nil
 
+	connection := s.queue.ConnectionState()
+	status.RabbitMQConnection = &connection
 	_, err = s.queue.GetDLQMessageCount()
-	status.RabbitMQ = err == nil
+	status.RabbitMQ = err == nil && connection.Connected
 
 	_, err = s.idempotencyService.IsProcessed(ctx, "health-check")
 	status.Redis = err == nil
 
+	if s.leadership != nil {
+		leaderStatus := s.leadership.Status()
+		status.Leader = &leaderStatus
+	}
+
+	if s.processor != nil {
+		consumer := s.processor.ConsumerStatus()
+		status.Consumer = &consumer
+	}
+
 	return status
 } 
\ No newline at end of file
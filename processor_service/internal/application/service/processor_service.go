@@ -2,13 +2,17 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/correlation"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
@@ -19,15 +23,42 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// claimMargin is how much longer the claim on a message lasts than the
+// webhook call it covers, for the work around the call.
+const claimMargin = 30 * time.Second
+
 type ProcessorService struct {
 	processor          *domain.MessageProcessor
 	repository         interfaces.MessageRepository
 	queue             rabbitPort.MessageQueue
 	idempotencyService redisPort.IdempotencyServicePort
 	webhookClient      ports.WebhookClient
+	topology           contracts.Topology
+	backoff            *domain.RetryBackoff
+	failures           *domain.FailureWindow
+	instanceID         string
+	sendTimeout        time.Duration
+	claimTTL           time.Duration
+	claims             uint64
+	leadership         leader.Leadership
+	workers            int
+	prefetch           int
+	busy               int32
+	mu                 sync.Mutex
+	running            sync.WaitGroup
 	done              chan bool
 }
 
+// ConsumerStatus reports how many deliveries the processor handles at once.
+// Concurrency is the number of workers that can be busy at the same time,
+// which the prefetch limits when it is lower than the number of workers.
+type ConsumerStatus struct {
+	Workers     int `json:"workers"`
+	Prefetch    int `json:"prefetch"`
+	Concurrency int `json:"effective_concurrency"`
+	Busy        int `json:"busy"`
+}
+
 func NewProcessorService(
 	processor *domain.MessageProcessor,
 	repository interfaces.MessageRepository,
@@ -41,46 +72,156 @@ func NewProcessorService(
 		queue:             queue,
 		idempotencyService: idempotencyService,
 		webhookClient:      webhookClient,
+		topology:           contracts.DefaultTopology(),
+		backoff:            domain.NewRetryBackoff(contracts.DefaultTopology().RetryBackoff, 0),
+		failures:           domain.NewFailureWindow(5 * time.Minute),
+		sendTimeout:        time.Minute,
+		claimTTL:           time.Minute + claimMargin,
+		leadership:         leader.AlwaysLeader{Name: "processor-stale-monitor"},
+		workers:            1,
 		done:              make(chan bool),
 	}
 }
 
+// WithLeadership makes the stale message monitor run only while this instance
+// holds the given leadership.
+func (s *ProcessorService) WithLeadership(leadership leader.Leadership) *ProcessorService {
+	s.leadership = leadership
+	return s
+}
+
+// WithTopology sets the queue to consume from. It must match the topology the
+// message queue was created with.
+func (s *ProcessorService) WithTopology(topology contracts.Topology) *ProcessorService {
+	s.topology = topology
+	return s
+}
+
+// WithRetryBackoff sets how long failed messages wait before the next
+// attempt. Its tiers must match the retry tiers of the topology.
+func (s *ProcessorService) WithRetryBackoff(backoff *domain.RetryBackoff) *ProcessorService {
+	s.backoff = backoff
+	return s
+}
+
+// WithFailureWindow sets the window that webhook delivery outcomes are
+// recorded in, for the failure rate alert.
+func (s *ProcessorService) WithFailureWindow(window *domain.FailureWindow) *ProcessorService {
+	s.failures = window
+	return s
+}
+
+// WithConcurrency sets the number of workers that process deliveries in
+// parallel and the prefetch count of the consumer; a prefetch of 0 means no
+// limit.
+func (s *ProcessorService) WithConcurrency(workers, prefetch int) *ProcessorService {
+	if workers < 1 {
+		workers = 1
+	}
+	if prefetch < 0 {
+		prefetch = 0
+	}
+	s.workers = workers
+	s.prefetch = prefetch
+	return s
+}
+
+// WithWebhookTimeout bounds each webhook call, including the wait for the
+// rate limiter, by twice the timeout of the webhook request. The claim on a
+// message lasts claimMargin longer, so it cannot expire while the message is
+// being sent.
+func (s *ProcessorService) WithWebhookTimeout(timeout time.Duration) *ProcessorService {
+	s.sendTimeout = 2 * timeout
+	s.claimTTL = s.sendTimeout + claimMargin
+	return s
+}
+
+// WithInstanceID sets the instance ID recorded on messages moved to the DLQ.
+func (s *ProcessorService) WithInstanceID(id string) *ProcessorService {
+	s.instanceID = id
+	return s
+}
+
 func (s *ProcessorService) Start() {
-	log.Println("Message Processor Service started")
+	log.Printf("Message Processor Service started with %d workers and prefetch %d", s.workers, s.prefetch)
 
 	go s.monitorStaleMessages()
 
-	messages, err := s.queue.ConsumeMessages(contracts.MainQueueName)
+	messages, err := s.queue.ConsumeMessages(s.topology.MainQueue(), s.prefetch)
 	if err != nil {
 		log.Fatalf("Failed to start consuming messages: %v", err)
 	}
 
+	s.mu.Lock()
+	select {
+	case <-s.done:
+		s.mu.Unlock()
+		return
+	default:
+	}
+	s.running.Add(s.workers)
+	s.mu.Unlock()
+
+	for i := 0; i < s.workers; i++ {
+		go s.work(messages)
+	}
+	s.running.Wait()
+}
+
+// work processes deliveries until the delivery channel closes or the service
+// stops. A delivery that is being processed when the service stops is
+// finished first.
+func (s *ProcessorService) work(messages <-chan amqp.Delivery) {
+	defer s.running.Done()
+
 	for {
 		select {
-		case msg := <-messages:
+		case msg, ok := <-messages:
+			if !ok {
+				log.Println("Delivery channel closed, stopping worker")
+				return
+			}
+			atomic.AddInt32(&s.busy, 1)
 			if err := s.processMessage(msg); err != nil {
 				log.Printf("Error processing message: %v", err)
 			}
+			atomic.AddInt32(&s.busy, -1)
 		case <-s.done:
 			return
 		}
 	}
 }
 
+// Stop waits for the workers to finish the deliveries they are processing
+// before it closes the queue.
 func (s *ProcessorService) Stop() {
+	s.mu.Lock()
 	close(s.done)
+	s.mu.Unlock()
+
+	s.running.Wait()
 	s.queue.Close()
 }
 
+func (s *ProcessorService) ConsumerStatus() ConsumerStatus {
+	concurrency := s.workers
+	if s.prefetch > 0 && s.prefetch < concurrency {
+		concurrency = s.prefetch
+	}
+	return ConsumerStatus{
+		Workers:     s.workers,
+		Prefetch:    s.prefetch,
+		Concurrency: concurrency,
+		Busy:        int(atomic.LoadInt32(&s.busy)),
+	}
+}
+
+// processMessage handles a delivery and acks it once its outcome is durable:
+// the message is sent and recorded, moved to the retry queue or the DLQ, or
+// found to be a duplicate. It is requeued when that fails.
 func (s *ProcessorService) processMessage(delivery amqp.Delivery) error {
 	var queueMsg contracts.QueueMessage
 
-	if delivery.DeliveryTag > 0 {
-		if err := delivery.Ack(false); err != nil {
-			log.Printf("Failed to ack message: %v", err)
-		}
-	}
-
 	if err := s.handleMessageProcessing(delivery, queueMsg); err != nil {
 		return fmt.Errorf("failed to process message: %v", err)
 	}
@@ -89,29 +230,44 @@ func (s *ProcessorService) processMessage(delivery amqp.Delivery) error {
 }
 
 func (s *ProcessorService) handleMessageProcessing(delivery amqp.Delivery, queueMsg contracts.QueueMessage) error {
-	if err := json.Unmarshal(delivery.Body, &queueMsg); err != nil {
-		return s.handleMalformedMessage(delivery, err)
+	ctx := correlation.WithID(context.Background(), delivery.CorrelationId)
+
+	// The message-id property lets repeated publishes of a processed message
+	// be dropped before the body is decoded. Messages published without it
+	// are checked once decoded.
+	if delivery.MessageId != "" {
+		if duplicate, err := s.checkDuplicate(ctx, delivery, delivery.MessageId); duplicate || err != nil {
+			return err
+		}
 	}
 
-	processed, err := s.idempotencyService.IsProcessed(context.Background(), queueMsg.ID)
+	queueMsg, err := rabbitPort.DecodeMessage(delivery.ContentType, delivery.Headers, delivery.Body)
 	if err != nil {
-		delivery.Nack(false, true)
-		return err
+		return s.handleMalformedMessage(delivery, err)
 	}
 
-	if processed {
-		return s.handleDuplicateMessage(queueMsg)
+	if delivery.MessageId == "" {
+		if duplicate, err := s.checkDuplicate(ctx, delivery, queueMsg.ID); duplicate || err != nil {
+			return err
+		}
+	} else if queueMsg.ID != delivery.MessageId {
+		return s.handleMalformedMessage(delivery, fmt.Errorf("body ID %q does not match message ID %q", queueMsg.ID, delivery.MessageId))
 	}
+	log.Printf("Processing message %s (correlation ID %s)", queueMsg.ID, delivery.CorrelationId)
 
 	msgID, err := primitive.ObjectIDFromHex(queueMsg.ID)
 	if err != nil {
 		return s.handleInvalidID(delivery, err)
 	}
 
+	if expiredIn(delivery.Headers, s.topology.MainQueue()) {
+		return s.handleExpiredMessage(delivery, msgID)
+	}
+
 	msg, err := s.repository.GetByID(context.Background(), msgID)
 	if err != nil {
 		log.Printf("Failed to get message from MongoDB: %v", err)
-		delivery.Nack(false, true)
+		s.requeue(delivery)
 		return err
 	}
 
@@ -123,9 +279,30 @@ func (s *ProcessorService) handleMessageProcessing(delivery amqp.Delivery, queue
 		return s.handleMaxRetriesReached(delivery, msg)
 	}
 
-	webhookResp, err := s.webhookClient.SendMessage(context.Background(), msg.Content, msg.To)
+	// The inbox check above does not stop two deliveries of the same message
+	// from reaching this point together, so the message is claimed before the
+	// webhook is called and only the delivery holding the claim sends it.
+	owner := fmt.Sprintf("%s-%d", s.instanceID, atomic.AddUint64(&s.claims, 1))
+	claimed, err := s.idempotencyService.Claim(ctx, queueMsg.ID, owner, s.claimTTL)
+	if err != nil {
+		log.Printf("Failed to claim message %s: %v", queueMsg.ID, err)
+		s.requeue(delivery)
+		return err
+	}
+	if !claimed {
+		return s.handleClaimedMessage(delivery, queueMsg.ID)
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.sendTimeout)
+	webhookResp, err := s.webhookClient.SendMessage(sendCtx, msg.Content, msg.To)
+	cancel()
+	s.failures.Record(err == nil)
 	if err != nil {
-		return s.handleWebhookError(delivery, msg, err)
+		err = s.handleWebhookError(delivery, msg, err)
+		if releaseErr := s.idempotencyService.Release(context.Background(), queueMsg.ID, owner); releaseErr != nil {
+			log.Printf("Failed to release claim on message %s: %v", queueMsg.ID, releaseErr)
+		}
+		return err
 	}
 
 	if webhookResp != nil && webhookResp.MessageID != "" {
@@ -136,18 +313,69 @@ func (s *ProcessorService) handleMessageProcessing(delivery amqp.Delivery, queue
 		log.Printf("No messageId received from webhook for message %s", queueMsg.ID)
 	}
 
-	return s.handleSuccessfulProcessing(queueMsg, msgID)
+	return s.handleSuccessfulProcessing(delivery, queueMsg, msgID)
+}
+
+// ack acknowledges a delivery whose outcome is durable.
+func (s *ProcessorService) ack(delivery amqp.Delivery) {
+	if err := delivery.Ack(false); err != nil {
+		log.Printf("Failed to ack message: %v", err)
+	}
+}
+
+// requeue returns a delivery to its queue after an infrastructure error, so
+// that it is processed again.
+func (s *ProcessorService) requeue(delivery amqp.Delivery) {
+	if err := delivery.Nack(false, true); err != nil {
+		log.Printf("Failed to requeue message: %v", err)
+	}
+}
+
+// deadLetter moves a delivery to the DLQ and acks it. When msgID is not
+// zero the message is marked failed too. The delivery is requeued if it
+// cannot be moved.
+func (s *ProcessorService) deadLetter(delivery amqp.Delivery, failure rabbitPort.Failure, msgID primitive.ObjectID) error {
+	if err := s.queue.MoveToDeadLetter(&delivery, failure); err != nil {
+		log.Printf("Failed to move message to DLQ: %v", err)
+		s.requeue(delivery)
+		return err
+	}
+	if !msgID.IsZero() {
+		if err := s.repository.UpdateStatus(context.Background(), msgID, models.StatusFailed); err != nil {
+			log.Printf("Failed to update message status to failed: %v", err)
+		}
+	}
+	s.ack(delivery)
+	return nil
 }
 
 func (s *ProcessorService) handleMalformedMessage(delivery amqp.Delivery, err error) error {
-	if err := s.queue.MoveToDeadLetter(&delivery); err != nil {
-		log.Printf("Failed to move malformed message to DLQ: %v", err)
+	if dlqErr := s.deadLetter(delivery, s.failure(rabbitPort.FailureMalformed, err, 0), primitive.NilObjectID); dlqErr != nil {
+		return dlqErr
 	}
 	return err
 }
 
-func (s *ProcessorService) handleDuplicateMessage(queueMsg contracts.QueueMessage) error {
-	msgID, err := primitive.ObjectIDFromHex(queueMsg.ID)
+// checkDuplicate reports whether the message with the given ID has been
+// processed already, and handles it if so. The delivery is requeued when the
+// check fails.
+func (s *ProcessorService) checkDuplicate(ctx context.Context, delivery amqp.Delivery, id string) (bool, error) {
+	processed, err := s.idempotencyService.IsProcessed(ctx, id)
+	if err != nil {
+		s.requeue(delivery)
+		return false, err
+	}
+	if !processed {
+		return false, nil
+	}
+	return true, s.handleDuplicateMessage(delivery, id)
+}
+
+// handleDuplicateMessage acks a message that the inbox records as processed.
+func (s *ProcessorService) handleDuplicateMessage(delivery amqp.Delivery, id string) error {
+	s.ack(delivery)
+
+	msgID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
@@ -156,33 +384,65 @@ func (s *ProcessorService) handleDuplicateMessage(queueMsg contracts.QueueMessag
 		log.Printf("Failed to update message status to duplicate: %v", err)
 	}
 
-	log.Printf("Message %s marked as duplicate", queueMsg.ID)
+	log.Printf("Message %s marked as duplicate", id)
+	return nil
+}
+
+// handleClaimedMessage delays a message that another delivery has claimed.
+// It is moved to the first retry tier without counting an attempt, so it is
+// dropped as a duplicate once the other delivery sends it, or processed again
+// if that delivery fails.
+func (s *ProcessorService) handleClaimedMessage(delivery amqp.Delivery, id string) error {
+	retry := s.backoff.Next(0)
+	if err := s.queue.MoveToRetryQueue(&delivery, retry.Tier, retry.Delay); err != nil {
+		log.Printf("Failed to move message to retry queue: %v", err)
+		s.requeue(delivery)
+		return err
+	}
+	s.ack(delivery)
+
+	log.Printf("Message %s is claimed by another delivery, retrying in %s", id, retry.Delay)
 	return nil
 }
 
 func (s *ProcessorService) handleInvalidID(delivery amqp.Delivery, err error) error {
-	if err := s.queue.MoveToDeadLetter(&delivery); err != nil {
-		log.Printf("Failed to move message with invalid ID to DLQ: %v", err)
+	if dlqErr := s.deadLetter(delivery, s.failure(rabbitPort.FailureInvalidID, err, 0), primitive.NilObjectID); dlqErr != nil {
+		return dlqErr
 	}
 	return err
 }
 
-func (s *ProcessorService) handleMaxRetriesReached(delivery amqp.Delivery, msg *models.Message) error {
-	if err := s.queue.MoveToDeadLetter(&delivery); err != nil {
-		log.Printf("Failed to move message to DLQ: %v", err)
+// expiredIn reports whether the message expired while waiting in queue. The
+// broker then dead-letters it to the retry queue, which returns it.
+func expiredIn(headers amqp.Table, queue string) bool {
+	for _, death := range rabbitPort.ParseDeaths(headers) {
+		if death.Queue == queue && death.Reason == "expired" {
+			return true
+		}
 	}
-	if err := s.repository.UpdateStatus(context.Background(), msg.ID, models.StatusFailed); err != nil {
-		log.Printf("Failed to update message status to failed: %v", err)
+	return false
+}
+
+func (s *ProcessorService) handleExpiredMessage(delivery amqp.Delivery, msgID primitive.ObjectID) error {
+	failure := s.failure(rabbitPort.FailureExpired, fmt.Errorf("message expired after %s in %s", s.topology.MessageTTL, s.topology.MainQueue()), 0)
+	if err := s.deadLetter(delivery, failure, msgID); err != nil {
+		return err
+	}
+	return fmt.Errorf("message expired")
+}
+
+func (s *ProcessorService) handleMaxRetriesReached(delivery amqp.Delivery, msg *models.Message) error {
+	failure := s.failure(rabbitPort.FailureMaxRetries, fmt.Errorf("retry count %d reached the limit of %d", msg.RetryCount, s.processor.GetMaxRetries()), msg.RetryCount)
+	if err := s.deadLetter(delivery, failure, msg.ID); err != nil {
+		return err
 	}
 	return fmt.Errorf("message reached max retry count")
 }
 
 func (s *ProcessorService) handleStaleMessage(delivery amqp.Delivery, msg *models.Message) error {
-	if err := s.queue.MoveToDeadLetter(&delivery); err != nil {
-		log.Printf("Failed to move stale message to DLQ: %v", err)
-	}
-	if err := s.repository.UpdateStatus(context.Background(), msg.ID, models.StatusFailed); err != nil {
-		log.Printf("Failed to update stale message status to failed: %v", err)
+	failure := s.failure(rabbitPort.FailureStale, fmt.Errorf("message was last updated at %s", msg.UpdatedAt.Format(time.RFC3339)), msg.RetryCount)
+	if err := s.deadLetter(delivery, failure, msg.ID); err != nil {
+		return err
 	}
 	return fmt.Errorf("message is stale")
 }
@@ -192,47 +452,75 @@ func (s *ProcessorService) handleWebhookError(delivery amqp.Delivery, msg *model
 	
 	if err := s.repository.IncrementRetryCount(context.Background(), msg.ID); err != nil {
 		log.Printf("Failed to increment retry count: %v", err)
+		s.requeue(delivery)
 		return err
 	}
 
 	if err := s.repository.UpdateStatus(context.Background(), msg.ID, models.StatusProcessing); err != nil {
 		log.Printf("Failed to update message status: %v", err)
+		s.requeue(delivery)
 		return err
 	}
 
 	updatedMsg, getErr := s.repository.GetByID(context.Background(), msg.ID)
 	if getErr != nil {
 		log.Printf("Failed to get updated message: %v", getErr)
+		s.requeue(delivery)
 		return getErr
 	}
 
 	if updatedMsg.RetryCount >= s.processor.GetMaxRetries() {
-		if err := s.queue.MoveToDeadLetter(&delivery); err != nil {
-			log.Printf("Failed to move message to DLQ: %v", err)
-		}
-		if err := s.repository.UpdateStatus(context.Background(), msg.ID, models.StatusFailed); err != nil {
-			log.Printf("Failed to update message status to failed: %v", err)
+		if dlqErr := s.deadLetter(delivery, s.failure(rabbitPort.FailureMaxRetries, err, updatedMsg.RetryCount), msg.ID); dlqErr != nil {
+			return dlqErr
 		}
 		return fmt.Errorf("message reached max retry count: %v", err)
 	}
 
-	if err := s.queue.MoveToRetryQueue(&delivery); err != nil {
+	retry := s.backoff.Next(updatedMsg.RetryCount)
+	if err := s.repository.ScheduleRetry(context.Background(), msg.ID, retry.Tier, time.Now().Add(retry.Latest)); err != nil {
+		log.Printf("Failed to record retry tier: %v", err)
+		s.requeue(delivery)
+		return err
+	}
+
+	if err := s.queue.MoveToRetryQueue(&delivery, retry.Tier, retry.Delay); err != nil {
 		log.Printf("Failed to move message to retry queue: %v", err)
+		s.requeue(delivery)
 		return err
 	}
+	s.ack(delivery)
 
-	log.Printf("Message %s moved to retry queue (attempt %d)", msg.ID.Hex(), updatedMsg.RetryCount)
+	log.Printf("Message %s moved to retry tier %d, retrying in %s (attempt %d)", msg.ID.Hex(), retry.Tier, retry.Delay, updatedMsg.RetryCount)
 	return err
 }
 
-func (s *ProcessorService) handleSuccessfulProcessing(queueMsg contracts.QueueMessage, msgID primitive.ObjectID) error {
-	if err := s.idempotencyService.MarkAsProcessed(context.Background(), queueMsg.ID); err != nil {
-		return err
+func (s *ProcessorService) failure(reason rabbitPort.FailureReason, err error, attempts int) rabbitPort.Failure {
+	failure := rabbitPort.Failure{
+		Reason:   reason,
+		Attempts: attempts,
+		Instance: s.instanceID,
+	}
+	if err != nil {
+		failure.Error = err.Error()
 	}
+	return failure
+}
 
+// handleSuccessfulProcessing records a sent message in MongoDB and the inbox
+// and acks it. The delivery is requeued if either fails. The status is
+// written first, so a redelivery the inbox drops as a duplicate is one whose
+// status is already sent.
+func (s *ProcessorService) handleSuccessfulProcessing(delivery amqp.Delivery, queueMsg contracts.QueueMessage, msgID primitive.ObjectID) error {
 	if err := s.repository.UpdateStatus(context.Background(), msgID, models.StatusSent); err != nil {
+		s.requeue(delivery)
+		return err
+	}
+
+	if err := s.idempotencyService.MarkAsProcessed(context.Background(), queueMsg.ID); err != nil {
+		s.requeue(delivery)
 		return err
 	}
+	s.ack(delivery)
 
 	log.Printf("Successfully processed message %s", queueMsg.ID)
 	return nil
@@ -256,6 +544,14 @@ func (s *ProcessorService) monitorStaleMessages() {
 
 func (s *ProcessorService) checkStaleMessages() error {
 	staleDuration := 4 * time.Minute
+
+	if err := s.leadership.Fence(context.Background()); err != nil {
+		if errors.Is(err, leader.ErrNotLeader) {
+			log.Println("Skipping stale message check, this instance is not the leader")
+			return nil
+		}
+		return err
+	}
 	
 	messages, err := s.repository.FindStaleProcessingMessages(context.Background(), staleDuration)
 	if err != nil {
@@ -274,17 +570,32 @@ func (s *ProcessorService) checkStaleMessages() error {
 func (s *ProcessorService) handleStaleMessageRecovery(msg *models.Message) error {
 	log.Printf("Found stale message %s in processing state", msg.ID.Hex())
 
-	msgBody, err := json.Marshal(contracts.QueueMessage{
+	msgBody, err := contracts.EncodeQueueMessage(contracts.QueueMessage{
 		ID:      msg.ID.Hex(),
 		Content: msg.Content,
+		To:      msg.To,
 		Retry:   msg.RetryCount,
-	})
+	}, contracts.ContentTypeJSON, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to marshal message for DLQ: %v", err)
 	}
 
-	delivery := amqp.Delivery{Body: msgBody}
-	if err := s.queue.MoveToDeadLetter(&delivery); err != nil {
+	correlationID := msg.CorrelationID
+	if correlationID == "" {
+		correlationID = msg.ID.Hex()
+	}
+	delivery := amqp.Delivery{
+		Headers:       amqp.Table{contracts.SchemaVersionHeader: contracts.CurrentSchemaVersion},
+		MessageId:     msg.ID.Hex(),
+		CorrelationId: correlationID,
+		AppId:         contracts.AppID,
+		Type:          contracts.QueueMessageType,
+		ContentType:   contracts.ContentTypeJSON,
+		Body:          msgBody,
+		Timestamp:     msg.CreatedAt,
+	}
+	failure := s.failure(rabbitPort.FailureStaleRecovery, fmt.Errorf("message was processing since %s", msg.UpdatedAt.Format(time.RFC3339)), msg.RetryCount)
+	if err := s.queue.MoveToDeadLetter(&delivery, failure); err != nil {
 		return fmt.Errorf("failed to move stale message to DLQ: %v", err)
 	}
 
@@ -1,15 +1,21 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/mocks"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/adapters"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/correlation"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
 
 	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/assert"
@@ -47,6 +53,7 @@ func TestProcessorService_ProcessMessage(t *testing.T) {
 
 				mockIdempotency.On("IsProcessed", mock.Anything, msgID).Return(false, nil)
 				mockRepo.On("GetByID", mock.Anything, id).Return(msg, nil)
+				mockIdempotency.On("Claim", mock.Anything, msgID, mock.Anything, 90*time.Second).Return(true, nil)
 				mockWebhook.On("SendMessage", mock.Anything, msg.Content, msg.To).Return(&ports.WebhookResponse{MessageID: "webhook-123"}, nil)
 				mockIdempotency.On("StoreWebhookMessageID", mock.Anything, msgID, "webhook-123", 24*time.Hour).Return(nil)
 				mockIdempotency.On("MarkAsProcessed", mock.Anything, msgID).Return(nil)
@@ -105,7 +112,7 @@ func TestProcessorService_HandleStaleMessages(t *testing.T) {
 	mockWebhook := new(mocks.MockWebhookClient)
 	processor := domain.NewMessageProcessor(3, 4*time.Minute)
 
-	service := NewProcessorService(processor, mockRepo, mockQueue, mockIdempotency, mockWebhook)
+	service := NewProcessorService(processor, mockRepo, mockQueue, mockIdempotency, mockWebhook).WithInstanceID("processor-1")
 
 	staleDuration := 4 * time.Minute
 	staleMessages := []models.Message{
@@ -119,7 +126,11 @@ func TestProcessorService_HandleStaleMessages(t *testing.T) {
 	}
 
 	mockRepo.On("FindStaleProcessingMessages", mock.Anything, staleDuration).Return(staleMessages, nil)
-	mockQueue.On("MoveToDeadLetter", mock.Anything).Return(nil)
+	mockQueue.On("MoveToDeadLetter", mock.MatchedBy(func(delivery *amqp.Delivery) bool {
+		return delivery.MessageId == staleMessages[0].ID.Hex()
+	}), mock.MatchedBy(func(failure rabbitPort.Failure) bool {
+		return failure.Reason == rabbitPort.FailureStaleRecovery && failure.Attempts == 1 && failure.Instance == "processor-1" && failure.Error != ""
+	})).Return(nil)
 	mockRepo.On("UpdateStatus", mock.Anything, staleMessages[0].ID, models.StatusFailed).Return(nil)
 
 	err := service.checkStaleMessages()
@@ -129,6 +140,283 @@ func TestProcessorService_HandleStaleMessages(t *testing.T) {
 	mockQueue.AssertExpectations(t)
 }
 
+func TestProcessorService_DeadLettersWithFailureReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		headers    amqp.Table
+		setupMocks func(mockRepo *mocks.MockMessageRepository, mockIdempotency *mocks.MockIdempotencyService)
+		reason     rabbitPort.FailureReason
+		attempts   int
+	}{
+		{
+			name:   "malformed message",
+			body:   "not json",
+			reason: rabbitPort.FailureMalformed,
+		},
+		{
+			name: "invalid message ID",
+			body: `{"id":"not-an-object-id"}`,
+			setupMocks: func(mockRepo *mocks.MockMessageRepository, mockIdempotency *mocks.MockIdempotencyService) {
+				mockIdempotency.On("IsProcessed", mock.Anything, "not-an-object-id").Return(false, nil)
+			},
+			reason: rabbitPort.FailureInvalidID,
+		},
+		{
+			name: "max retries reached",
+			body: `{"id":"65f1a2b3c4d5e6f708091a2b"}`,
+			setupMocks: func(mockRepo *mocks.MockMessageRepository, mockIdempotency *mocks.MockIdempotencyService) {
+				id, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f708091a2b")
+				mockIdempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				mockRepo.On("GetByID", mock.Anything, id).Return(&models.Message{ID: id, RetryCount: 3, UpdatedAt: time.Now()}, nil)
+				mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusFailed).Return(nil)
+			},
+			reason:   rabbitPort.FailureMaxRetries,
+			attempts: 3,
+		},
+		{
+			name: "stale message",
+			body: `{"id":"65f1a2b3c4d5e6f708091a2b"}`,
+			setupMocks: func(mockRepo *mocks.MockMessageRepository, mockIdempotency *mocks.MockIdempotencyService) {
+				id, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f708091a2b")
+				mockIdempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				mockRepo.On("GetByID", mock.Anything, id).Return(&models.Message{ID: id, RetryCount: 1, UpdatedAt: time.Now().Add(-time.Hour)}, nil)
+				mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusFailed).Return(nil)
+			},
+			reason:   rabbitPort.FailureStale,
+			attempts: 1,
+		},
+		{
+			name: "expired in the main queue",
+			body: `{"id":"65f1a2b3c4d5e6f708091a2b"}`,
+			headers: amqp.Table{"x-death": []interface{}{
+				amqp.Table{"queue": "messages", "reason": "expired", "count": int64(1)},
+			}},
+			setupMocks: func(mockRepo *mocks.MockMessageRepository, mockIdempotency *mocks.MockIdempotencyService) {
+				id, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f708091a2b")
+				mockIdempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusFailed).Return(nil)
+			},
+			reason: rabbitPort.FailureExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockMessageRepository)
+			mockQueue := new(mocks.MockMessageQueue)
+			mockIdempotency := new(mocks.MockIdempotencyService)
+			processor := domain.NewMessageProcessor(3, 4*time.Minute)
+			service := NewProcessorService(processor, mockRepo, mockQueue, mockIdempotency, new(mocks.MockWebhookClient)).WithInstanceID("processor-1")
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockRepo, mockIdempotency)
+			}
+			mockQueue.On("MoveToDeadLetter", mock.Anything, mock.MatchedBy(func(failure rabbitPort.Failure) bool {
+				return failure.Reason == tt.reason && failure.Attempts == tt.attempts && failure.Instance == "processor-1" && failure.Error != ""
+			})).Return(nil)
+
+			err := service.handleMessageProcessing(amqp.Delivery{Headers: tt.headers, Body: []byte(tt.body)}, contracts.QueueMessage{})
+			assert.Error(t, err)
+
+			mockQueue.AssertExpectations(t)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProcessorService_AcceptsCurrentAndPreviousSchemaVersions(t *testing.T) {
+	msg := contracts.QueueMessage{ID: "65f1a2b3c4d5e6f708091a2b", Content: "test content", To: "+905321234567"}
+	v1, _ := json.Marshal(msg)
+	v2JSON, _ := contracts.EncodeQueueMessage(msg, contracts.ContentTypeJSON, time.Now())
+	v2Protobuf, _ := contracts.EncodeQueueMessage(msg, contracts.ContentTypeProtobuf, time.Now())
+	v2 := amqp.Table{contracts.SchemaVersionHeader: contracts.SchemaVersion2}
+
+	tests := []struct {
+		name     string
+		delivery amqp.Delivery
+	}{
+		{name: "version 1", delivery: amqp.Delivery{ContentType: contracts.ContentTypeJSON, Body: v1}},
+		{name: "version 2 JSON", delivery: amqp.Delivery{ContentType: contracts.ContentTypeJSON, Headers: v2, Body: v2JSON}},
+		{name: "version 2 protobuf", delivery: amqp.Delivery{ContentType: contracts.ContentTypeProtobuf, Headers: v2, Body: v2Protobuf}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockMessageRepository)
+			mockIdempotency := new(mocks.MockIdempotencyService)
+			service := NewProcessorService(domain.NewMessageProcessor(3, 4*time.Minute), mockRepo, new(mocks.MockMessageQueue), mockIdempotency, new(mocks.MockWebhookClient))
+
+			id, _ := primitive.ObjectIDFromHex(msg.ID)
+			mockIdempotency.On("IsProcessed", mock.Anything, msg.ID).Return(true, nil)
+			mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusDuplicate).Return(nil)
+
+			assert.NoError(t, service.handleMessageProcessing(tt.delivery, contracts.QueueMessage{}))
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProcessorService_DeadLettersUnsupportedSchemaVersion(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	service := NewProcessorService(domain.NewMessageProcessor(3, 4*time.Minute), new(mocks.MockMessageRepository), mockQueue, new(mocks.MockIdempotencyService), new(mocks.MockWebhookClient))
+
+	mockQueue.On("MoveToDeadLetter", mock.Anything, mock.MatchedBy(func(failure rabbitPort.Failure) bool {
+		return failure.Reason == rabbitPort.FailureMalformed
+	})).Return(nil)
+
+	delivery := amqp.Delivery{
+		ContentType: contracts.ContentTypeJSON,
+		Headers:     amqp.Table{contracts.SchemaVersionHeader: 3},
+		Body:        []byte(`{"type":"message.send","version":3,"id":"65f1a2b3c4d5e6f708091a2b"}`),
+	}
+	err := service.handleMessageProcessing(delivery, contracts.QueueMessage{})
+	assert.ErrorIs(t, err, contracts.ErrUnsupportedSchema)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestProcessorService_DropsDuplicateByMessageIDBeforeDecoding(t *testing.T) {
+	mockRepo := new(mocks.MockMessageRepository)
+	mockIdempotency := new(mocks.MockIdempotencyService)
+	service := NewProcessorService(domain.NewMessageProcessor(3, 4*time.Minute), mockRepo, new(mocks.MockMessageQueue), mockIdempotency, new(mocks.MockWebhookClient))
+
+	id := primitive.NewObjectID()
+	mockIdempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(true, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusDuplicate).Return(nil)
+
+	// The body is never decoded, so even an unreadable one is dropped.
+	delivery := amqp.Delivery{MessageId: id.Hex(), Body: []byte("not json")}
+	assert.NoError(t, service.handleMessageProcessing(delivery, contracts.QueueMessage{}))
+	mockIdempotency.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessorService_DeadLettersMessageIDMismatch(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	mockIdempotency := new(mocks.MockIdempotencyService)
+	service := NewProcessorService(domain.NewMessageProcessor(3, 4*time.Minute), new(mocks.MockMessageRepository), mockQueue, mockIdempotency, new(mocks.MockWebhookClient))
+
+	mockIdempotency.On("IsProcessed", mock.Anything, "65f1a2b3c4d5e6f708091a2b").Return(false, nil)
+	mockQueue.On("MoveToDeadLetter", mock.Anything, mock.MatchedBy(func(failure rabbitPort.Failure) bool {
+		return failure.Reason == rabbitPort.FailureMalformed
+	})).Return(nil)
+
+	delivery := amqp.Delivery{MessageId: "65f1a2b3c4d5e6f708091a2b", Body: []byte(`{"id":"65f1a2b3c4d5e6f708091a2c"}`)}
+	assert.Error(t, service.handleMessageProcessing(delivery, contracts.QueueMessage{}))
+	mockQueue.AssertExpectations(t)
+}
+
+func TestProcessorService_PassesCorrelationIDToWebhook(t *testing.T) {
+	mockRepo := new(mocks.MockMessageRepository)
+	mockIdempotency := new(mocks.MockIdempotencyService)
+	mockWebhook := new(mocks.MockWebhookClient)
+	service := NewProcessorService(domain.NewMessageProcessor(3, 4*time.Minute), mockRepo, new(mocks.MockMessageQueue), mockIdempotency, mockWebhook)
+
+	id := primitive.NewObjectID()
+	msg := &models.Message{ID: id, Content: "test content", To: "+905321234567", UpdatedAt: time.Now()}
+	mockIdempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+	mockRepo.On("GetByID", mock.Anything, id).Return(msg, nil)
+	mockIdempotency.On("Claim", mock.Anything, id.Hex(), mock.Anything, 90*time.Second).Return(true, nil)
+	mockWebhook.On("SendMessage", mock.MatchedBy(func(ctx context.Context) bool {
+		return correlation.FromContext(ctx) == "request-1"
+	}), msg.Content, msg.To).Return(&ports.WebhookResponse{MessageID: "webhook-123"}, nil)
+	mockIdempotency.On("StoreWebhookMessageID", mock.Anything, id.Hex(), "webhook-123", 24*time.Hour).Return(nil)
+	mockIdempotency.On("MarkAsProcessed", mock.Anything, id.Hex()).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusSent).Return(nil)
+
+	body, _ := json.Marshal(contracts.QueueMessage{ID: id.Hex(), Content: msg.Content, To: msg.To})
+	delivery := amqp.Delivery{MessageId: id.Hex(), CorrelationId: "request-1", Body: body}
+	assert.NoError(t, service.handleMessageProcessing(delivery, contracts.QueueMessage{}))
+	mockWebhook.AssertExpectations(t)
+}
+
+func TestProcessorService_ClaimOutlastsWebhookCall(t *testing.T) {
+	mockRepo := new(mocks.MockMessageRepository)
+	mockIdempotency := new(mocks.MockIdempotencyService)
+	mockWebhook := new(mocks.MockWebhookClient)
+	service := NewProcessorService(domain.NewMessageProcessor(3, 4*time.Minute), mockRepo, new(mocks.MockMessageQueue), mockIdempotency, mockWebhook).
+		WithWebhookTimeout(2 * time.Minute)
+
+	id := primitive.NewObjectID()
+	msg := &models.Message{ID: id, Content: "test content", To: "+905321234567", UpdatedAt: time.Now()}
+	mockIdempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+	mockRepo.On("GetByID", mock.Anything, id).Return(msg, nil)
+	mockIdempotency.On("Claim", mock.Anything, id.Hex(), mock.Anything, 4*time.Minute+30*time.Second).Return(true, nil)
+	mockWebhook.On("SendMessage", mock.MatchedBy(func(ctx context.Context) bool {
+		deadline, ok := ctx.Deadline()
+		return ok && time.Until(deadline) > 3*time.Minute && time.Until(deadline) <= 4*time.Minute
+	}), msg.Content, msg.To).Return(&ports.WebhookResponse{}, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusSent).Return(nil)
+	mockIdempotency.On("MarkAsProcessed", mock.Anything, id.Hex()).Return(nil)
+
+	body, _ := json.Marshal(contracts.QueueMessage{ID: id.Hex(), Content: msg.Content, To: msg.To})
+	assert.NoError(t, service.handleMessageProcessing(amqp.Delivery{MessageId: id.Hex(), Body: body}, contracts.QueueMessage{}))
+	mockIdempotency.AssertExpectations(t)
+	mockWebhook.AssertExpectations(t)
+}
+
+func TestProcessorService_HandleStaleMessages_NotLeader(t *testing.T) {
+	mockRepo := new(mocks.MockMessageRepository)
+	mockQueue := new(mocks.MockMessageQueue)
+	mockIdempotency := new(mocks.MockIdempotencyService)
+	mockWebhook := new(mocks.MockWebhookClient)
+	mockLeadership := new(mocks.MockLeadership)
+	processor := domain.NewMessageProcessor(3, 4*time.Minute)
+
+	service := NewProcessorService(processor, mockRepo, mockQueue, mockIdempotency, mockWebhook).WithLeadership(mockLeadership)
+
+	mockLeadership.On("Fence", mock.Anything).Return(leader.ErrNotLeader)
+
+	err := service.checkStaleMessages()
+	assert.NoError(t, err)
+
+	mockLeadership.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "FindStaleProcessingMessages", mock.Anything, mock.Anything)
+}
+
+func TestProcessorService_Start_StopsWhenDeliveriesClose(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	processor := domain.NewMessageProcessor(3, 4*time.Minute)
+	service := NewProcessorService(processor, new(mocks.MockMessageRepository), mockQueue, new(mocks.MockIdempotencyService), new(mocks.MockWebhookClient))
+
+	deliveries := make(chan amqp.Delivery)
+	close(deliveries)
+	mockQueue.On("ConsumeMessages", contracts.MainQueueName, 0).Return((<-chan amqp.Delivery)(deliveries), nil)
+	mockQueue.On("Close").Return()
+
+	stopped := make(chan struct{})
+	go func() {
+		service.Start()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Start kept running after the delivery channel closed")
+	}
+	service.Stop()
+	mockQueue.AssertExpectations(t)
+}
+
+func TestProcessorService_Start_ConsumesConfiguredQueue(t *testing.T) {
+	mockQueue := new(mocks.MockMessageQueue)
+	processor := domain.NewMessageProcessor(3, 4*time.Minute)
+	topology := contracts.DefaultTopology()
+	topology.Prefix = "staging"
+	service := NewProcessorService(processor, new(mocks.MockMessageRepository), mockQueue, new(mocks.MockIdempotencyService), new(mocks.MockWebhookClient)).
+		WithTopology(topology)
+
+	deliveries := make(chan amqp.Delivery)
+	close(deliveries)
+	mockQueue.On("ConsumeMessages", "staging.messages", 0).Return((<-chan amqp.Delivery)(deliveries), nil)
+	mockQueue.On("Close").Return()
+
+	service.Start()
+	service.Stop()
+	mockQueue.AssertExpectations(t)
+}
+
 func TestProcessorService_HandleWebhookError(t *testing.T) {
 	mockRepo := new(mocks.MockMessageRepository)
 	mockQueue := new(mocks.MockMessageQueue)
@@ -153,7 +441,8 @@ func TestProcessorService_HandleWebhookError(t *testing.T) {
 	mockRepo.On("IncrementRetryCount", mock.Anything, msgID).Return(nil)
 	mockRepo.On("UpdateStatus", mock.Anything, msgID, models.StatusProcessing).Return(nil)
 	mockRepo.On("GetByID", mock.Anything, msgID).Return(msg, nil)
-	mockQueue.On("MoveToRetryQueue", &delivery).Return(nil)
+	mockRepo.On("ScheduleRetry", mock.Anything, msgID, 0, mock.AnythingOfType("time.Time")).Return(nil)
+	mockQueue.On("MoveToRetryQueue", &delivery, 0, 10*time.Second).Return(nil)
 
 	err := service.handleWebhookError(delivery, msg, testErr)
 	assert.Error(t, err)
@@ -161,4 +450,595 @@ func TestProcessorService_HandleWebhookError(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 	mockQueue.AssertExpectations(t)
+}
+
+func TestProcessorService_HandleWebhookError_UsesBackoffTier(t *testing.T) {
+	mockRepo := new(mocks.MockMessageRepository)
+	mockQueue := new(mocks.MockMessageQueue)
+	processor := domain.NewMessageProcessor(5, 4*time.Minute)
+	backoff := domain.NewRetryBackoff([]time.Duration{10 * time.Second, time.Minute, 10 * time.Minute}, 0)
+
+	service := NewProcessorService(processor, mockRepo, mockQueue, new(mocks.MockIdempotencyService), new(mocks.MockWebhookClient)).
+		WithRetryBackoff(backoff)
+
+	msgID := primitive.NewObjectID()
+	msg := &models.Message{ID: msgID, RetryCount: 1, UpdatedAt: time.Now()}
+	updated := &models.Message{ID: msgID, RetryCount: 2, UpdatedAt: time.Now()}
+	delivery := amqp.Delivery{}
+
+	mockRepo.On("IncrementRetryCount", mock.Anything, msgID).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, msgID, models.StatusProcessing).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, msgID).Return(updated, nil)
+	mockRepo.On("ScheduleRetry", mock.Anything, msgID, 1, mock.MatchedBy(func(next time.Time) bool {
+		return next.After(time.Now().Add(50*time.Second)) && !next.After(time.Now().Add(time.Minute))
+	})).Return(nil)
+	mockQueue.On("MoveToRetryQueue", &delivery, 1, time.Minute).Return(nil)
+
+	err := service.handleWebhookError(delivery, msg, assert.AnError)
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+// settlement records how a delivery was settled.
+type settlement struct {
+	outcome string
+}
+
+func (s *settlement) Ack(tag uint64, multiple bool) error {
+	s.outcome = "ack"
+	return nil
+}
+
+func (s *settlement) Nack(tag uint64, multiple bool, requeue bool) error {
+	if requeue {
+		s.outcome = "requeue"
+	} else {
+		s.outcome = "reject"
+	}
+	return nil
+}
+
+func (s *settlement) Reject(tag uint64, requeue bool) error {
+	return s.Nack(tag, false, requeue)
+}
+
+type processingMocks struct {
+	repo        *mocks.MockMessageRepository
+	queue       *mocks.MockMessageQueue
+	idempotency *mocks.MockIdempotencyService
+	webhook     *mocks.MockWebhookClient
+}
+
+func TestProcessorService_HandleMessageProcessing_SettlesDelivery(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f708091a2b")
+	body, _ := json.Marshal(contracts.QueueMessage{ID: id.Hex(), Content: "test content", To: "+905321234567"})
+	fresh := &models.Message{ID: id, Content: "test content", To: "+905321234567", UpdatedAt: time.Now()}
+	retried := &models.Message{ID: id, Content: "test content", To: "+905321234567", RetryCount: 1, UpdatedAt: time.Now()}
+	exhausted := &models.Message{ID: id, RetryCount: 3, UpdatedAt: time.Now()}
+	expired := amqp.Table{"x-death": []interface{}{
+		amqp.Table{"queue": "messages", "reason": "expired", "count": int64(1)},
+	}}
+
+	// sendable sets up a message that is not processed yet and is due.
+	sendable := func(m processingMocks) {
+		m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+		m.repo.On("GetByID", mock.Anything, id).Return(fresh, nil).Once()
+	}
+	// claimed sets up a sendable message that this delivery claims.
+	claimed := func(m processingMocks) {
+		sendable(m)
+		m.idempotency.On("Claim", mock.Anything, id.Hex(), mock.Anything, 90*time.Second).Return(true, nil)
+	}
+	// sendFails sets up a claimed message whose webhook call fails, after
+	// which the claim is released.
+	sendFails := func(m processingMocks) {
+		claimed(m)
+		m.webhook.On("SendMessage", mock.Anything, fresh.Content, fresh.To).Return(nil, assert.AnError)
+		m.idempotency.On("Release", mock.Anything, id.Hex(), mock.Anything).Return(nil)
+	}
+	// webhookFails sets up a failed webhook call and the retry bookkeeping
+	// up to reading the message back.
+	webhookFails := func(m processingMocks) {
+		sendFails(m)
+		m.repo.On("IncrementRetryCount", mock.Anything, id).Return(nil)
+		m.repo.On("UpdateStatus", mock.Anything, id, models.StatusProcessing).Return(nil)
+	}
+	webhookSucceeds := func(m processingMocks) {
+		claimed(m)
+		m.webhook.On("SendMessage", mock.Anything, fresh.Content, fresh.To).Return(&ports.WebhookResponse{MessageID: "webhook-123"}, nil)
+		m.idempotency.On("StoreWebhookMessageID", mock.Anything, id.Hex(), "webhook-123", 24*time.Hour).Return(nil)
+	}
+	deadLetters := func(m processingMocks, reason rabbitPort.FailureReason, err error) {
+		m.queue.On("MoveToDeadLetter", mock.Anything, mock.MatchedBy(func(failure rabbitPort.Failure) bool {
+			return failure.Reason == reason
+		})).Return(err)
+	}
+
+	tests := []struct {
+		name      string
+		messageID string
+		body      []byte
+		headers   amqp.Table
+		setup     func(m processingMocks)
+		outcome   string
+		wantErr   bool
+	}{
+		{
+			name:      "inbox check by message ID fails",
+			messageID: id.Hex(),
+			body:      body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name:      "duplicate by message ID",
+			messageID: id.Hex(),
+			body:      []byte("not json"),
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(true, nil)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusDuplicate).Return(nil)
+			},
+			outcome: "ack",
+		},
+		{
+			name: "duplicate without message ID",
+			body: body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(true, nil)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusDuplicate).Return(nil)
+			},
+			outcome: "ack",
+		},
+		{
+			name: "inbox check without message ID fails",
+			body: body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "malformed message",
+			body: []byte("not json"),
+			setup: func(m processingMocks) {
+				deadLetters(m, rabbitPort.FailureMalformed, nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name: "malformed message cannot be dead-lettered",
+			body: []byte("not json"),
+			setup: func(m processingMocks) {
+				deadLetters(m, rabbitPort.FailureMalformed, assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name:      "message ID does not match body",
+			messageID: "65f1a2b3c4d5e6f708091a2c",
+			body:      body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, "65f1a2b3c4d5e6f708091a2c").Return(false, nil)
+				deadLetters(m, rabbitPort.FailureMalformed, nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name: "invalid message ID",
+			body: []byte(`{"id":"not-an-object-id"}`),
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, "not-an-object-id").Return(false, nil)
+				deadLetters(m, rabbitPort.FailureInvalidID, nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name:    "expired in the main queue",
+			body:    body,
+			headers: expired,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				deadLetters(m, rabbitPort.FailureExpired, nil)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusFailed).Return(nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name: "message lookup fails",
+			body: body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				m.repo.On("GetByID", mock.Anything, id).Return(nil, assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "stale message",
+			body: body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				m.repo.On("GetByID", mock.Anything, id).Return(&models.Message{ID: id, RetryCount: 1, UpdatedAt: time.Now().Add(-time.Hour)}, nil)
+				deadLetters(m, rabbitPort.FailureStale, nil)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusFailed).Return(nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name: "max retries reached",
+			body: body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				m.repo.On("GetByID", mock.Anything, id).Return(exhausted, nil)
+				deadLetters(m, rabbitPort.FailureMaxRetries, nil)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusFailed).Return(nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name: "max retries reached and cannot be dead-lettered",
+			body: body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				m.repo.On("GetByID", mock.Anything, id).Return(exhausted, nil)
+				deadLetters(m, rabbitPort.FailureMaxRetries, assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "stale message and the broker nacks the move to the DLQ",
+			body: body,
+			setup: func(m processingMocks) {
+				m.idempotency.On("IsProcessed", mock.Anything, id.Hex()).Return(false, nil)
+				m.repo.On("GetByID", mock.Anything, id).Return(&models.Message{ID: id, RetryCount: 1, UpdatedAt: time.Now().Add(-time.Hour)}, nil)
+				deadLetters(m, rabbitPort.FailureStale, &rabbitPort.PublishError{Reason: rabbitPort.ErrPublishNacked})
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "claim fails",
+			body: body,
+			setup: func(m processingMocks) {
+				sendable(m)
+				m.idempotency.On("Claim", mock.Anything, id.Hex(), mock.Anything, 90*time.Second).Return(false, assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "claimed by another delivery",
+			body: body,
+			setup: func(m processingMocks) {
+				sendable(m)
+				m.idempotency.On("Claim", mock.Anything, id.Hex(), mock.Anything, 90*time.Second).Return(false, nil)
+				m.queue.On("MoveToRetryQueue", mock.Anything, 0, 10*time.Second).Return(nil)
+			},
+			outcome: "ack",
+		},
+		{
+			name: "claimed by another delivery and cannot be moved to the retry queue",
+			body: body,
+			setup: func(m processingMocks) {
+				sendable(m)
+				m.idempotency.On("Claim", mock.Anything, id.Hex(), mock.Anything, 90*time.Second).Return(false, nil)
+				m.queue.On("MoveToRetryQueue", mock.Anything, 0, 10*time.Second).Return(assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails and the message is retried",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookFails(m)
+				m.repo.On("GetByID", mock.Anything, id).Return(retried, nil).Once()
+				m.repo.On("ScheduleRetry", mock.Anything, id, 0, mock.AnythingOfType("time.Time")).Return(nil)
+				m.queue.On("MoveToRetryQueue", mock.Anything, 0, 10*time.Second).Return(nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails and the retry count cannot be incremented",
+			body: body,
+			setup: func(m processingMocks) {
+				sendFails(m)
+				m.repo.On("IncrementRetryCount", mock.Anything, id).Return(assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails and the status cannot be updated",
+			body: body,
+			setup: func(m processingMocks) {
+				sendFails(m)
+				m.repo.On("IncrementRetryCount", mock.Anything, id).Return(nil)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusProcessing).Return(assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails and the message cannot be read back",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookFails(m)
+				m.repo.On("GetByID", mock.Anything, id).Return(nil, assert.AnError).Once()
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails on the last attempt",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookFails(m)
+				m.repo.On("GetByID", mock.Anything, id).Return(exhausted, nil).Once()
+				deadLetters(m, rabbitPort.FailureMaxRetries, nil)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusFailed).Return(nil)
+			},
+			outcome: "ack",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails and the retry cannot be scheduled",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookFails(m)
+				m.repo.On("GetByID", mock.Anything, id).Return(retried, nil).Once()
+				m.repo.On("ScheduleRetry", mock.Anything, id, 0, mock.AnythingOfType("time.Time")).Return(assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails and the broker nacks the move to the retry queue",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookFails(m)
+				m.repo.On("GetByID", mock.Anything, id).Return(retried, nil).Once()
+				m.repo.On("ScheduleRetry", mock.Anything, id, 0, mock.AnythingOfType("time.Time")).Return(nil)
+				m.queue.On("MoveToRetryQueue", mock.Anything, 0, 10*time.Second).Return(&rabbitPort.PublishError{Reason: rabbitPort.ErrPublishNacked})
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "webhook fails and the message cannot be moved to the retry queue",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookFails(m)
+				m.repo.On("GetByID", mock.Anything, id).Return(retried, nil).Once()
+				m.repo.On("ScheduleRetry", mock.Anything, id, 0, mock.AnythingOfType("time.Time")).Return(nil)
+				m.queue.On("MoveToRetryQueue", mock.Anything, 0, 10*time.Second).Return(assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "message sent",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookSucceeds(m)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusSent).Return(nil)
+				m.idempotency.On("MarkAsProcessed", mock.Anything, id.Hex()).Return(nil)
+			},
+			outcome: "ack",
+		},
+		{
+			name: "message sent and the status cannot be updated",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookSucceeds(m)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusSent).Return(assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+		{
+			name: "message sent and the inbox cannot record it",
+			body: body,
+			setup: func(m processingMocks) {
+				webhookSucceeds(m)
+				m.repo.On("UpdateStatus", mock.Anything, id, models.StatusSent).Return(nil)
+				m.idempotency.On("MarkAsProcessed", mock.Anything, id.Hex()).Return(assert.AnError)
+			},
+			outcome: "requeue",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := processingMocks{
+				repo:        new(mocks.MockMessageRepository),
+				queue:       new(mocks.MockMessageQueue),
+				idempotency: new(mocks.MockIdempotencyService),
+				webhook:     new(mocks.MockWebhookClient),
+			}
+			service := NewProcessorService(domain.NewMessageProcessor(3, 4*time.Minute), m.repo, m.queue, m.idempotency, m.webhook)
+			tt.setup(m)
+
+			settled := &settlement{}
+			delivery := amqp.Delivery{Acknowledger: settled, DeliveryTag: 1, MessageId: tt.messageID, Headers: tt.headers, Body: tt.body}
+			err := service.processMessage(delivery)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.outcome, settled.outcome)
+
+			m.repo.AssertExpectations(t)
+			m.queue.AssertExpectations(t)
+			m.idempotency.AssertExpectations(t)
+			m.webhook.AssertExpectations(t)
+		})
+	}
+}
+
+func duplicateDelivery() amqp.Delivery {
+	body, _ := json.Marshal(contracts.QueueMessage{ID: primitive.NewObjectID().Hex(), Content: "test content", To: "+905321234567"})
+	return amqp.Delivery{Body: body}
+}
+
+func TestProcessorService_Start_ProcessesInParallel(t *testing.T) {
+	mockRepo := new(mocks.MockMessageRepository)
+	mockQueue := new(mocks.MockMessageQueue)
+	mockIdempotency := new(mocks.MockIdempotencyService)
+	processor := domain.NewMessageProcessor(3, 4*time.Minute)
+	service := NewProcessorService(processor, mockRepo, mockQueue, mockIdempotency, new(mocks.MockWebhookClient)).
+		WithConcurrency(3, 6)
+
+	assert.Equal(t, ConsumerStatus{Workers: 3, Prefetch: 6, Concurrency: 3}, service.ConsumerStatus())
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	allBusy := make(chan struct{})
+	mockIdempotency.On("IsProcessed", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		if inFlight == 3 {
+			close(allBusy)
+		}
+		mu.Unlock()
+
+		select {
+		case <-allBusy:
+		case <-time.After(time.Second):
+		}
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}).Return(true, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, mock.Anything, models.StatusDuplicate).Return(nil)
+
+	deliveries := make(chan amqp.Delivery, 3)
+	for i := 0; i < 3; i++ {
+		deliveries <- duplicateDelivery()
+	}
+	close(deliveries)
+	mockQueue.On("ConsumeMessages", contracts.MainQueueName, 6).Return((<-chan amqp.Delivery)(deliveries), nil)
+	mockQueue.On("Close").Return()
+
+	service.Start()
+	service.Stop()
+
+	assert.Equal(t, 3, peak)
+	mockRepo.AssertNumberOfCalls(t, "UpdateStatus", 3)
+
+	t.Run("duplicate deliveries in parallel", func(t *testing.T) {
+		mockRepo := new(mocks.MockMessageRepository)
+		mockQueue := new(mocks.MockMessageQueue)
+		mockWebhook := new(mocks.MockWebhookClient)
+		service := NewProcessorService(processor, mockRepo, mockQueue, adapters.NewMemoryIdempotencyService(), mockWebhook).
+			WithConcurrency(2, 2)
+
+		id := primitive.NewObjectID()
+		msg := &models.Message{ID: id, Content: "test content", To: "+905321234567", UpdatedAt: time.Now()}
+		body, _ := json.Marshal(contracts.QueueMessage{ID: id.Hex(), Content: msg.Content, To: msg.To})
+
+		// Both deliveries pass the inbox check. The webhook call of the one
+		// holding the claim waits until the other has been moved aside.
+		moved := make(chan struct{})
+		mockRepo.On("GetByID", mock.Anything, id).Return(msg, nil)
+		mockWebhook.On("SendMessage", mock.Anything, msg.Content, msg.To).Run(func(mock.Arguments) {
+			select {
+			case <-moved:
+			case <-time.After(time.Second):
+			}
+		}).Return(&ports.WebhookResponse{MessageID: "webhook-123"}, nil)
+		mockQueue.On("MoveToRetryQueue", mock.Anything, 0, 10*time.Second).Run(func(mock.Arguments) {
+			close(moved)
+		}).Return(nil)
+		mockRepo.On("UpdateStatus", mock.Anything, id, models.StatusSent).Return(nil)
+
+		settled := []*settlement{{}, {}}
+		deliveries := make(chan amqp.Delivery, 2)
+		for i := range settled {
+			deliveries <- amqp.Delivery{Acknowledger: settled[i], DeliveryTag: uint64(i + 1), MessageId: id.Hex(), Body: body}
+		}
+		close(deliveries)
+		mockQueue.On("ConsumeMessages", contracts.MainQueueName, 2).Return((<-chan amqp.Delivery)(deliveries), nil)
+		mockQueue.On("Close").Return()
+
+		service.Start()
+		service.Stop()
+
+		mockWebhook.AssertNumberOfCalls(t, "SendMessage", 1)
+		mockQueue.AssertNumberOfCalls(t, "MoveToRetryQueue", 1)
+		assert.Equal(t, "ack", settled[0].outcome)
+		assert.Equal(t, "ack", settled[1].outcome)
+	})
+}
+
+func TestProcessorService_Stop_WaitsForInFlightDeliveries(t *testing.T) {
+	mockRepo := new(mocks.MockMessageRepository)
+	mockQueue := new(mocks.MockMessageQueue)
+	mockIdempotency := new(mocks.MockIdempotencyService)
+	processor := domain.NewMessageProcessor(3, 4*time.Minute)
+	service := NewProcessorService(processor, mockRepo, mockQueue, mockIdempotency, new(mocks.MockWebhookClient)).
+		WithConcurrency(2, 1)
+
+	assert.Equal(t, 1, service.ConsumerStatus().Concurrency, "prefetch limits the effective concurrency")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockIdempotency.On("IsProcessed", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		close(started)
+		<-release
+	}).Return(true, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, mock.Anything, models.StatusDuplicate).Return(nil)
+
+	deliveries := make(chan amqp.Delivery, 1)
+	deliveries <- duplicateDelivery()
+	mockQueue.On("ConsumeMessages", contracts.MainQueueName, 1).Return((<-chan amqp.Delivery)(deliveries), nil)
+	mockQueue.On("Close").Return()
+
+	go service.Start()
+	<-started
+	assert.Equal(t, 1, service.ConsumerStatus().Busy)
+
+	stopped := make(chan struct{})
+	go func() {
+		service.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a delivery was being processed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	mockQueue.AssertNotCalled(t, "Close")
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the delivery was processed")
+	}
+	mockRepo.AssertNumberOfCalls(t, "UpdateStatus", 1)
+	mockQueue.AssertExpectations(t)
 } 
\ No newline at end of file
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type AlertStatus string
+
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// Alert is a notification about a rule. A rule that keeps failing is
+// reported once when it starts firing and once more when it resolves.
+type Alert struct {
+	Rule      string      `json:"rule"`
+	Status    AlertStatus `json:"status"`
+	Summary   string      `json:"summary"`
+	Value     float64     `json:"value"`
+	Threshold float64     `json:"threshold"`
+	Instance  string      `json:"instance,omitempty"`
+	StartsAt  time.Time   `json:"starts_at"`
+	EndsAt    *time.Time  `json:"ends_at,omitempty"`
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+const failureWindowBuckets = 60
+
+type failureBucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+// FailureWindow counts webhook outcomes over a sliding window. Outcomes are
+// grouped into buckets, so the window slides one bucket at a time.
+type FailureWindow struct {
+	mu      sync.Mutex
+	width   time.Duration
+	buckets []failureBucket
+	now     func() time.Time
+}
+
+func NewFailureWindow(window time.Duration) *FailureWindow {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	width := window / failureWindowBuckets
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+	return &FailureWindow{
+		width:   width,
+		buckets: make([]failureBucket, failureWindowBuckets),
+		now:     time.Now,
+	}
+}
+
+func (w *FailureWindow) Record(success bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	start := now.Truncate(w.width)
+	bucket := &w.buckets[int(start.UnixNano()/int64(w.width))%len(w.buckets)]
+	if !bucket.start.Equal(start) {
+		*bucket = failureBucket{start: start}
+	}
+	if success {
+		bucket.successes++
+	} else {
+		bucket.failures++
+	}
+}
+
+// Rate returns the share of failed outcomes in the window and the number of
+// outcomes it is based on.
+func (w *FailureWindow) Rate() (float64, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	oldest := w.now().Truncate(w.width).Add(-time.Duration(len(w.buckets)-1) * w.width)
+	var successes, failures int
+	for _, bucket := range w.buckets {
+		if bucket.start.Before(oldest) {
+			continue
+		}
+		successes += bucket.successes
+		failures += bucket.failures
+	}
+
+	total := successes + failures
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(total), total
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureWindow_Rate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := NewFailureWindow(time.Minute)
+	window.now = func() time.Time { return now }
+
+	rate, samples := window.Rate()
+	assert.Equal(t, 0.0, rate)
+	assert.Equal(t, 0, samples)
+
+	window.Record(false)
+	window.Record(false)
+	window.Record(true)
+	window.Record(true)
+
+	rate, samples = window.Rate()
+	assert.Equal(t, 0.5, rate)
+	assert.Equal(t, 4, samples)
+
+	now = now.Add(30 * time.Second)
+	window.Record(false)
+
+	rate, samples = window.Rate()
+	assert.Equal(t, 0.6, rate)
+	assert.Equal(t, 5, samples)
+
+	now = now.Add(45 * time.Second)
+	rate, samples = window.Rate()
+	assert.Equal(t, 1.0, rate, "outcomes older than the window are dropped")
+	assert.Equal(t, 1, samples)
+
+	now = now.Add(2 * time.Minute)
+	rate, samples = window.Rate()
+	assert.Equal(t, 0.0, rate)
+	assert.Equal(t, 0, samples)
+}
@@ -39,7 +39,7 @@ func (p *MessageProcessor) ShouldProcessMessage(msg *models.Message) ProcessingR
 		}
 	}
 
-	if time.Since(msg.UpdatedAt) > p.staleDuration {
+	if p.IsMessageStale(lastActivity(msg)) {
 		return ProcessingResult{
 			Success:  false,
 			Error:    nil,
@@ -53,6 +53,15 @@ func (p *MessageProcessor) ShouldProcessMessage(msg *models.Message) ProcessingR
 	}
 }
 
+// lastActivity is when the message was last updated or, while it waits in a
+// retry tier, when that retry is due.
+func lastActivity(msg *models.Message) time.Time {
+	if msg.NextRetryAt != nil && msg.NextRetryAt.After(msg.UpdatedAt) {
+		return *msg.NextRetryAt
+	}
+	return msg.UpdatedAt
+}
+
 func (p *MessageProcessor) IsMessageStale(lastUpdateTime time.Time) bool {
 	return time.Since(lastUpdateTime) > p.staleDuration
 } 
\ No newline at end of file
@@ -56,6 +56,32 @@ func TestMessageProcessor_ShouldProcessMessage(t *testing.T) {
 				IsStale: true,
 			},
 		},
+		{
+			name: "should process message returning from a long retry tier",
+			message: &models.Message{
+				ID:          primitive.NewObjectID(),
+				RetryCount:  1,
+				UpdatedAt:   time.Now().Add(-time.Hour),
+				NextRetryAt: timePtr(time.Now().Add(-time.Minute)),
+			},
+			expected: ProcessingResult{
+				Success:     true,
+				ShouldRetry: true,
+			},
+		},
+		{
+			name: "should not process message long past its retry",
+			message: &models.Message{
+				ID:          primitive.NewObjectID(),
+				RetryCount:  1,
+				UpdatedAt:   time.Now().Add(-time.Hour),
+				NextRetryAt: timePtr(time.Now().Add(-5 * time.Minute)),
+			},
+			expected: ProcessingResult{
+				Success: false,
+				IsStale: true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,4 +127,8 @@ func TestMessageProcessor_GetMaxRetries(t *testing.T) {
 	processor := NewMessageProcessor(maxRetries, 4*time.Minute)
 
 	assert.Equal(t, maxRetries, processor.GetMaxRetries())
-} 
\ No newline at end of file
+} 
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
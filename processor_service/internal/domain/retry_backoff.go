@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryDelay is the retry tier chosen for a failed attempt and the delay,
+// with jitter applied, before the message is delivered again. Latest is the
+// longest the message can wait: a tier queue only expires the message at its
+// head, so a message with a longer jittered delay ahead of it holds it back
+// until up to the tier's delay plus the full jitter.
+type RetryDelay struct {
+	Tier   int
+	Delay  time.Duration
+	Latest time.Duration
+}
+
+// RetryBackoff picks a retry tier from the attempt count. The first failed
+// attempt uses the first tier, and attempts beyond the last tier keep using
+// the last one. Jitter spreads each delay by up to that fraction in either
+// direction, so messages that failed together are not retried together.
+type RetryBackoff struct {
+	tiers  []time.Duration
+	jitter float64
+	random func() float64
+}
+
+func NewRetryBackoff(tiers []time.Duration, jitter float64) *RetryBackoff {
+	if len(tiers) == 0 {
+		tiers = []time.Duration{10 * time.Second}
+	}
+	if jitter < 0 {
+		jitter = 0
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return &RetryBackoff{
+		tiers:  tiers,
+		jitter: jitter,
+		random: rand.Float64,
+	}
+}
+
+func (b *RetryBackoff) Next(attempt int) RetryDelay {
+	tier := attempt - 1
+	if tier < 0 {
+		tier = 0
+	}
+	if tier >= len(b.tiers) {
+		tier = len(b.tiers) - 1
+	}
+
+	delay := b.tiers[tier]
+	latest := delay
+	if b.jitter > 0 {
+		spread := b.jitter * (2*b.random() - 1)
+		delay += time.Duration(float64(b.tiers[tier]) * spread)
+		latest += time.Duration(float64(b.tiers[tier]) * b.jitter)
+	}
+	return RetryDelay{Tier: tier, Delay: delay, Latest: latest}
+}
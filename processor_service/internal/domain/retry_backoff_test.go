@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRetryBackoff_Next(t *testing.T) {
+	tiers := []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute, time.Hour}
+	backoff := NewRetryBackoff(tiers, 0)
+
+	tests := []struct {
+		attempt  int
+		expected RetryDelay
+	}{
+		{attempt: 0, expected: RetryDelay{Tier: 0, Delay: 10 * time.Second, Latest: 10 * time.Second}},
+		{attempt: 1, expected: RetryDelay{Tier: 0, Delay: 10 * time.Second, Latest: 10 * time.Second}},
+		{attempt: 2, expected: RetryDelay{Tier: 1, Delay: time.Minute, Latest: time.Minute}},
+		{attempt: 3, expected: RetryDelay{Tier: 2, Delay: 10 * time.Minute, Latest: 10 * time.Minute}},
+		{attempt: 4, expected: RetryDelay{Tier: 3, Delay: time.Hour, Latest: time.Hour}},
+		{attempt: 7, expected: RetryDelay{Tier: 3, Delay: time.Hour, Latest: time.Hour}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, backoff.Next(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func TestRetryBackoff_Jitter(t *testing.T) {
+	backoff := NewRetryBackoff([]time.Duration{time.Minute}, 0.2)
+
+	backoff.random = func() float64 { return 0 }
+	assert.Equal(t, 48*time.Second, backoff.Next(1).Delay)
+
+	backoff.random = func() float64 { return 0.5 }
+	assert.Equal(t, time.Minute, backoff.Next(1).Delay)
+
+	backoff.random = func() float64 { return 1 }
+	assert.Equal(t, 72*time.Second, backoff.Next(1).Delay)
+	assert.Equal(t, 72*time.Second, backoff.Next(1).Latest)
+}
+
+// A tier queue only expires its head, so a message with a short delay that
+// enters behind one with a long delay returns when the long one does. Its
+// Latest still covers that wait, so it is not stale when it returns.
+func TestRetryBackoff_LatestCoversMessageHeldBackInTier(t *testing.T) {
+	backoff := NewRetryBackoff([]time.Duration{time.Hour}, 0.2)
+	processor := NewMessageProcessor(3, 4*time.Minute)
+
+	backoff.random = func() float64 { return 1 }
+	long := backoff.Next(1)
+	backoff.random = func() float64 { return 0 }
+	short := backoff.Next(1)
+	assert.Equal(t, 72*time.Minute, long.Delay)
+	assert.Equal(t, 48*time.Minute, short.Delay)
+
+	// The long message entered the tier a minute before the short one, and
+	// both return now, when the long one expires.
+	shortEntered := time.Now().Add(-long.Delay + time.Minute)
+	held := func(delay time.Duration) *models.Message {
+		next := shortEntered.Add(delay)
+		return &models.Message{ID: primitive.NewObjectID(), RetryCount: 1, UpdatedAt: shortEntered, NextRetryAt: &next}
+	}
+
+	assert.True(t, processor.ShouldProcessMessage(held(short.Latest)).Success)
+	assert.True(t, processor.ShouldProcessMessage(held(short.Delay)).IsStale, "the jittered delay alone does not cover the wait")
+}
+
+func TestNewRetryBackoff_Defaults(t *testing.T) {
+	backoff := NewRetryBackoff(nil, 2)
+
+	assert.Equal(t, []time.Duration{10 * time.Second}, backoff.tiers)
+	assert.Equal(t, 1.0, backoff.jitter)
+}
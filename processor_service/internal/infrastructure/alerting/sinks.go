@@ -0,0 +1,98 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+)
+
+type webhookSink struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhookSink posts each alert as JSON to url.
+func NewWebhookSink(url string, timeout time.Duration) ports.AlertSink {
+	return &webhookSink{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+	}
+}
+
+func (s *webhookSink) Send(ctx context.Context, alert domain.Alert) error {
+	return postJSON(ctx, s.client, s.url, alert)
+}
+
+type slackSink struct {
+	client *http.Client
+	url    string
+}
+
+// NewSlackSink posts each alert to a Slack incoming webhook, or any service
+// that accepts the same {"text": "..."} payload.
+func NewSlackSink(url string, timeout time.Duration) ports.AlertSink {
+	return &slackSink{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+	}
+}
+
+func (s *slackSink) Send(ctx context.Context, alert domain.Alert) error {
+	return postJSON(ctx, s.client, s.url, map[string]string{"text": FormatAlert(alert)})
+}
+
+type logSink struct{}
+
+func NewLogSink() ports.AlertSink {
+	return logSink{}
+}
+
+func (logSink) Send(ctx context.Context, alert domain.Alert) error {
+	log.Printf("ALERT %s", FormatAlert(alert))
+	return nil
+}
+
+// FormatAlert renders an alert as a single line of text.
+func FormatAlert(alert domain.Alert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s: %s", strings.ToUpper(string(alert.Status)), alert.Rule, alert.Summary)
+	if alert.Instance != "" {
+		fmt.Fprintf(&b, " (instance %s)", alert.Instance)
+	}
+	if alert.Status == domain.AlertResolved && alert.EndsAt != nil {
+		fmt.Fprintf(&b, ", firing for %s", alert.EndsAt.Sub(alert.StartsAt).Round(time.Second))
+	}
+	return b.String()
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alert request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("alert endpoint returned error status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSink_Send(t *testing.T) {
+	var received domain.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	startsAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	alert := domain.Alert{Rule: "dlq_depth", Status: domain.AlertFiring, Summary: "DLQ holds 12 messages", Value: 12, Threshold: 10, StartsAt: startsAt}
+
+	err := NewWebhookSink(server.URL, time.Second).Send(context.Background(), alert)
+	assert.NoError(t, err)
+	assert.Equal(t, alert, received)
+}
+
+func TestWebhookSink_Send_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewWebhookSink(server.URL, time.Second).Send(context.Background(), domain.Alert{Rule: "dlq_depth"})
+	assert.Error(t, err)
+}
+
+func TestSlackSink_Send(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	startsAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(90 * time.Second)
+	alert := domain.Alert{Rule: "circuit_breaker_open", Status: domain.AlertResolved, Summary: "webhook circuit breaker is closed", Instance: "processor-1", StartsAt: startsAt, EndsAt: &endsAt}
+
+	err := NewSlackSink(server.URL, time.Second).Send(context.Background(), alert)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"text": "[RESOLVED] circuit_breaker_open: webhook circuit breaker is closed (instance processor-1), firing for 1m30s"}, received)
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyAuth rejects requests without a valid key in the X-API-Key header.
+// It lets every request through when no keys are configured.
+func APIKeyAuth(keys *auth.APIKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !keys.Valid(c.GetHeader(auth.APIKeyHeader)) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or missing API key",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
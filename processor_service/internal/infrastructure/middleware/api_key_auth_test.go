@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		keys         []string
+		header       string
+		expectedCode int
+	}{
+		{
+			name:         "auth disabled",
+			keys:         nil,
+			header:       "",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "valid key",
+			keys:         []string{"secret"},
+			header:       "secret",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "missing key",
+			keys:         []string{"secret"},
+			header:       "",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "wrong key",
+			keys:         []string{"secret"},
+			header:       "other",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(APIKeyAuth(auth.NewAPIKeys(tt.keys)))
+			router.POST("/dlq/purge", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/dlq/purge", nil)
+			if tt.header != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.header)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
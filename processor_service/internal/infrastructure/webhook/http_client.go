@@ -8,9 +8,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/correlation"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
 
 	"github.com/sony/gobreaker"
@@ -19,12 +21,32 @@ import (
 type httpWebhookClient struct {
 	client      *http.Client
 	baseURL     string
-	rateLimiter *ratelimit.RateLimiter
+	rateLimiter ratelimit.Limiter
 	cb          *gobreaker.CircuitBreaker
+
+	mu        sync.Mutex
+	openSince *time.Time
 }
 
 func NewHTTPWebhookClient(webhookURL string, timeout time.Duration) ports.WebhookClient {
-	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+	return NewHTTPWebhookClientWithLimiter(webhookURL, timeout, ratelimit.NewRateLimiter(50, 100))
+}
+
+func NewHTTPWebhookClientWithLimiter(webhookURL string, timeout time.Duration, limiter ratelimit.Limiter) ports.WebhookClient {
+	c := &httpWebhookClient{
+		baseURL: webhookURL,
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			},
+		},
+		rateLimiter: limiter,
+	}
+
+	c.cb = gobreaker.NewCircuitBreaker(gobreaker.Settings{
 		Name:        "webhook-client",
 		MaxRequests: 3,
 		Interval:    10 * time.Second,  
@@ -35,21 +57,33 @@ func NewHTTPWebhookClient(webhookURL string, timeout time.Duration) ports.Webhoo
 		},
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			fmt.Printf("Circuit breaker %s state changed from %s to %s\n", name, from, to)
+			c.stateChanged(from, to)
 		},
 	})
 
-	return &httpWebhookClient{
-		baseURL: webhookURL,
-		client: &http.Client{
-			Timeout: timeout,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
-		},
-		rateLimiter: ratelimit.NewRateLimiter(50, 100), 
-		cb:          cb,
+	return c
+}
+
+func (c *httpWebhookClient) stateChanged(from, to gobreaker.State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case to == gobreaker.StateClosed:
+		c.openSince = nil
+	case from == gobreaker.StateClosed:
+		now := time.Now()
+		c.openSince = &now
+	}
+}
+
+func (c *httpWebhookClient) CircuitState() ports.CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return ports.CircuitState{
+		State:     c.cb.State().String(),
+		OpenSince: c.openSince,
 	}
 }
 
@@ -75,6 +109,9 @@ func (c *httpWebhookClient) SendMessage(ctx context.Context, content string, to
 		}
 		
 		req.Header.Set("Content-Type", "application/json")
+		if id := correlation.FromContext(ctx); id != "" {
+			req.Header.Set(correlation.Header, id)
+		}
 
 		resp, err := c.client.Do(req)
 		if err != nil {
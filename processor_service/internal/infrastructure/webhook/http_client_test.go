@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/correlation"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -105,6 +106,7 @@ func TestHTTPWebhookClient_SendMessage_CircuitBreaker(t *testing.T) {
 	defer server.Close()
 
 	client := NewHTTPWebhookClient(server.URL, 5*time.Second)
+	assert.Equal(t, ports.CircuitState{State: "closed"}, client.CircuitState())
 
 	for i := 0; i < 5; i++ {
 		_, err := client.SendMessage(context.Background(), "test", "+905321234569")
@@ -114,5 +116,21 @@ func TestHTTPWebhookClient_SendMessage_CircuitBreaker(t *testing.T) {
 	_, err := client.SendMessage(context.Background(), "test", "+905321234569")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "circuit breaker")
+
+	state := client.CircuitState()
+	assert.Equal(t, "open", state.State)
+	assert.NotNil(t, state.OpenSince)
+}
+
+func TestHTTPWebhookClient_SendMessage_PropagatesCorrelationID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "request-1", r.Header.Get(correlation.Header))
+		json.NewEncoder(w).Encode(ports.WebhookResponse{MessageID: "test-message-id"})
+	}))
+	defer server.Close()
+
+	client := NewHTTPWebhookClient(server.URL, 5*time.Second)
+	_, err := client.SendMessage(correlation.WithID(context.Background(), "request-1"), "test", "+905321234569")
+	assert.NoError(t, err)
 }
 
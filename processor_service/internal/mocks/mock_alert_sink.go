@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/processor_service/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAlertSink struct {
+	mock.Mock
+}
+
+func (m *MockAlertSink) Send(ctx context.Context, alert domain.Alert) error {
+	args := m.Called(ctx, alert)
+	return args.Error(0)
+}
@@ -24,4 +24,14 @@ func (m *MockIdempotencyService) MarkAsProcessed(ctx context.Context, messageID
 func (m *MockIdempotencyService) StoreWebhookMessageID(ctx context.Context, messageID string, webhookMessageID string, ttl time.Duration) error {
 	args := m.Called(ctx, messageID, webhookMessageID, ttl)
 	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) Claim(ctx context.Context, messageID string, owner string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, messageID, owner, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyService) Release(ctx context.Context, messageID string, owner string) error {
+	args := m.Called(ctx, messageID, owner)
+	return args.Error(0)
 } 
\ No newline at end of file
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLeadership struct {
+	mock.Mock
+}
+
+func (m *MockLeadership) IsLeader() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockLeadership) Fence(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockLeadership) Status() leader.Status {
+	args := m.Called()
+	return args.Get(0).(leader.Status)
+}
@@ -2,8 +2,10 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
 
 	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/mock"
@@ -11,23 +13,25 @@ import (
 
 type MockMessageQueue struct {
 	mock.Mock
+	deadLetters []interfaces.DeadLetter
+	Replayed    []contracts.QueueMessage
 }
 
-func (m *MockMessageQueue) ConsumeMessages(queueName string) (<-chan amqp.Delivery, error) {
-	args := m.Called(queueName)
+func (m *MockMessageQueue) ConsumeMessages(queueName string, prefetch int) (<-chan amqp.Delivery, error) {
+	args := m.Called(queueName, prefetch)
 	if ch, ok := args.Get(0).(<-chan amqp.Delivery); ok {
 		return ch, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockMessageQueue) MoveToDeadLetter(delivery *amqp.Delivery) error {
-	args := m.Called(delivery)
+func (m *MockMessageQueue) MoveToDeadLetter(delivery *amqp.Delivery, failure interfaces.Failure) error {
+	args := m.Called(delivery, failure)
 	return args.Error(0)
 }
 
-func (m *MockMessageQueue) MoveToRetryQueue(delivery *amqp.Delivery) error {
-	args := m.Called(delivery)
+func (m *MockMessageQueue) MoveToRetryQueue(delivery *amqp.Delivery, tier int, delay time.Duration) error {
+	args := m.Called(delivery, tier, delay)
 	return args.Error(0)
 }
 
@@ -36,6 +40,53 @@ func (m *MockMessageQueue) GetDLQMessageCount() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockMessageQueue) BrowseDLQ(offset, limit int) ([]interfaces.DeadLetter, int, error) {
+	args := m.Called(offset, limit)
+	messages, _ := args.Get(0).([]interfaces.DeadLetter)
+	return messages, args.Int(1), args.Error(2)
+}
+
+// ReplayDLQ passes the DeadLetters set with SetDeadLetters to replay, like
+// the adapter does, and counts the messages replay returns.
+func (m *MockMessageQueue) ReplayDLQ(ctx context.Context, replay interfaces.ReplayFunc) (int, error) {
+	args := m.Called(ctx, mock.Anything)
+	if err := args.Error(1); err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, deadLetter := range m.deadLetters {
+		msg, err := replay(deadLetter)
+		if err != nil {
+			return replayed, err
+		}
+		if msg != nil {
+			m.Replayed = append(m.Replayed, *msg)
+			replayed++
+		}
+	}
+	return replayed, nil
+}
+
+func (m *MockMessageQueue) SetDeadLetters(deadLetters ...interfaces.DeadLetter) {
+	m.deadLetters = deadLetters
+}
+
+func (m *MockMessageQueue) PurgeDLQ() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageQueue) GetQueueStats(queueName string) (interfaces.QueueStats, error) {
+	args := m.Called(queueName)
+	return args.Get(0).(interfaces.QueueStats), args.Error(1)
+}
+
+func (m *MockMessageQueue) ConnectionState() interfaces.ConnectionState {
+	args := m.Called()
+	return args.Get(0).(interfaces.ConnectionState)
+}
+
 func (m *MockMessageQueue) Close() {
 	m.Called()
 }
@@ -43,4 +94,12 @@ func (m *MockMessageQueue) Close() {
 func (m *MockMessageQueue) PublishMessage(ctx context.Context, message contracts.QueueMessage) error {
 	args := m.Called(ctx, message)
 	return args.Error(0)
+}
+
+func (m *MockMessageQueue) NewPublisher() (interfaces.Publisher, error) {
+	args := m.Called()
+	if publisher, ok := args.Get(0).(interfaces.Publisher); ok {
+		return publisher, args.Error(1)
+	}
+	return nil, args.Error(1)
 } 
\ No newline at end of file
@@ -19,6 +19,11 @@ func (m *MockMessageRepository) CreateMessage(ctx context.Context, message *mode
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) CreateMessages(ctx context.Context, messages []*models.Message) error {
+	args := m.Called(ctx, messages)
+	return args.Error(0)
+}
+
 func (m *MockMessageRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
 	args := m.Called(ctx, id)
 	if msg, ok := args.Get(0).(*models.Message); ok {
@@ -40,11 +45,31 @@ func (m *MockMessageRepository) UpdateStatus(ctx context.Context, id primitive.O
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (bool, error) {
+	args := m.Called(ctx, id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) TransitionStatuses(ctx context.Context, ids []primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (int, error) {
+	args := m.Called(ctx, ids, from, to)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockMessageRepository) IncrementRetryCount(ctx context.Context, id primitive.ObjectID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) ResetRetries(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) ScheduleRetry(ctx context.Context, id primitive.ObjectID, tier int, nextRetryAt time.Time) error {
+	args := m.Called(ctx, id, tier, nextRetryAt)
+	return args.Error(0)
+}
+
 func (m *MockMessageRepository) FindStaleProcessingMessages(ctx context.Context, staleDuration time.Duration) ([]models.Message, error) {
 	args := m.Called(ctx, staleDuration)
 	if msgs, ok := args.Get(0).([]models.Message); ok {
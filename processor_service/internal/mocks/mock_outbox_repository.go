@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) ClaimEvents(ctx context.Context, types []string, owner string, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	args := m.Called(ctx, types, owner, limit, lease)
+	if events, ok := args.Get(0).([]models.OutboxEvent); ok {
+		return events, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOutboxRepository) ClaimEvent(ctx context.Context, id primitive.ObjectID, types []string, owner string, lease time.Duration) (*models.OutboxEvent, error) {
+	args := m.Called(ctx, id, types, owner, lease)
+	if event, ok := args.Get(0).(*models.OutboxEvent); ok {
+		return event, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOutboxRepository) CompleteEvent(ctx context.Context, id primitive.ObjectID, owner string) error {
+	args := m.Called(ctx, id, owner)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) CompleteEvents(ctx context.Context, ids []primitive.ObjectID, owner string) (int, error) {
+	args := m.Called(ctx, ids, owner)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxRepository) ReleaseEvent(ctx context.Context, id primitive.ObjectID, owner string, lastErr string) error {
+	args := m.Called(ctx, id, owner, lastErr)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) EnqueueUnsentMessages(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxRepository) OldestEvent(ctx context.Context) (*models.OutboxEvent, error) {
+	args := m.Called(ctx)
+	if event, ok := args.Get(0).(*models.OutboxEvent); ok {
+		return event, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOutboxRepository) WatchInserts(ctx context.Context, stream string, handle func(ctx context.Context, id primitive.ObjectID) error) error {
+	args := m.Called(ctx, stream, handle)
+	return args.Error(0)
+}
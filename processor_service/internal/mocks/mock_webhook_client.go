@@ -18,4 +18,9 @@ func (m *MockWebhookClient) SendMessage(ctx context.Context, content string, to
 		return resp, args.Error(1)
 	}
 	return nil, args.Error(1)
-} 
\ No newline at end of file
+}
+
+func (m *MockWebhookClient) CircuitState() ports.CircuitState {
+	args := m.Called()
+	return args.Get(0).(ports.CircuitState)
+}
\ No newline at end of file
@@ -0,0 +1,321 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: sender.proto
+
+package senderv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SenderService_CreateMessage_FullMethodName       = "/sender.v1.SenderService/CreateMessage"
+	SenderService_BatchCreateMessages_FullMethodName = "/sender.v1.SenderService/BatchCreateMessages"
+	SenderService_GetMessage_FullMethodName          = "/sender.v1.SenderService/GetMessage"
+	SenderService_ListMessages_FullMethodName        = "/sender.v1.SenderService/ListMessages"
+	SenderService_StartScheduler_FullMethodName      = "/sender.v1.SenderService/StartScheduler"
+	SenderService_StopScheduler_FullMethodName       = "/sender.v1.SenderService/StopScheduler"
+)
+
+// SenderServiceClient is the client API for SenderService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SenderService is the gRPC counterpart of the sender REST API. Calls require
+// the "x-api-key" metadata entry when API keys are configured.
+type SenderServiceClient interface {
+	CreateMessage(ctx context.Context, in *CreateMessageRequest, opts ...grpc.CallOption) (*CreateMessageResponse, error)
+	BatchCreateMessages(ctx context.Context, in *BatchCreateMessagesRequest, opts ...grpc.CallOption) (*BatchCreateMessagesResponse, error)
+	GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*Message, error)
+	ListMessages(ctx context.Context, in *ListMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error)
+	StartScheduler(ctx context.Context, in *StartSchedulerRequest, opts ...grpc.CallOption) (*SchedulerResponse, error)
+	StopScheduler(ctx context.Context, in *StopSchedulerRequest, opts ...grpc.CallOption) (*SchedulerResponse, error)
+}
+
+type senderServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSenderServiceClient(cc grpc.ClientConnInterface) SenderServiceClient {
+	return &senderServiceClient{cc}
+}
+
+func (c *senderServiceClient) CreateMessage(ctx context.Context, in *CreateMessageRequest, opts ...grpc.CallOption) (*CreateMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateMessageResponse)
+	err := c.cc.Invoke(ctx, SenderService_CreateMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *senderServiceClient) BatchCreateMessages(ctx context.Context, in *BatchCreateMessagesRequest, opts ...grpc.CallOption) (*BatchCreateMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCreateMessagesResponse)
+	err := c.cc.Invoke(ctx, SenderService_BatchCreateMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *senderServiceClient) GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*Message, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Message)
+	err := c.cc.Invoke(ctx, SenderService_GetMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *senderServiceClient) ListMessages(ctx context.Context, in *ListMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SenderService_ServiceDesc.Streams[0], SenderService_ListMessages_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListMessagesRequest, Message]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SenderService_ListMessagesClient = grpc.ServerStreamingClient[Message]
+
+func (c *senderServiceClient) StartScheduler(ctx context.Context, in *StartSchedulerRequest, opts ...grpc.CallOption) (*SchedulerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerResponse)
+	err := c.cc.Invoke(ctx, SenderService_StartScheduler_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *senderServiceClient) StopScheduler(ctx context.Context, in *StopSchedulerRequest, opts ...grpc.CallOption) (*SchedulerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerResponse)
+	err := c.cc.Invoke(ctx, SenderService_StopScheduler_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SenderServiceServer is the server API for SenderService service.
+// All implementations must embed UnimplementedSenderServiceServer
+// for forward compatibility.
+//
+// SenderService is the gRPC counterpart of the sender REST API. Calls require
+// the "x-api-key" metadata entry when API keys are configured.
+type SenderServiceServer interface {
+	CreateMessage(context.Context, *CreateMessageRequest) (*CreateMessageResponse, error)
+	BatchCreateMessages(context.Context, *BatchCreateMessagesRequest) (*BatchCreateMessagesResponse, error)
+	GetMessage(context.Context, *GetMessageRequest) (*Message, error)
+	ListMessages(*ListMessagesRequest, grpc.ServerStreamingServer[Message]) error
+	StartScheduler(context.Context, *StartSchedulerRequest) (*SchedulerResponse, error)
+	StopScheduler(context.Context, *StopSchedulerRequest) (*SchedulerResponse, error)
+	mustEmbedUnimplementedSenderServiceServer()
+}
+
+// UnimplementedSenderServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSenderServiceServer struct{}
+
+func (UnimplementedSenderServiceServer) CreateMessage(context.Context, *CreateMessageRequest) (*CreateMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMessage not implemented")
+}
+func (UnimplementedSenderServiceServer) BatchCreateMessages(context.Context, *BatchCreateMessagesRequest) (*BatchCreateMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateMessages not implemented")
+}
+func (UnimplementedSenderServiceServer) GetMessage(context.Context, *GetMessageRequest) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessage not implemented")
+}
+func (UnimplementedSenderServiceServer) ListMessages(*ListMessagesRequest, grpc.ServerStreamingServer[Message]) error {
+	return status.Errorf(codes.Unimplemented, "method ListMessages not implemented")
+}
+func (UnimplementedSenderServiceServer) StartScheduler(context.Context, *StartSchedulerRequest) (*SchedulerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartScheduler not implemented")
+}
+func (UnimplementedSenderServiceServer) StopScheduler(context.Context, *StopSchedulerRequest) (*SchedulerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopScheduler not implemented")
+}
+func (UnimplementedSenderServiceServer) mustEmbedUnimplementedSenderServiceServer() {}
+func (UnimplementedSenderServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeSenderServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SenderServiceServer will
+// result in compilation errors.
+type UnsafeSenderServiceServer interface {
+	mustEmbedUnimplementedSenderServiceServer()
+}
+
+func RegisterSenderServiceServer(s grpc.ServiceRegistrar, srv SenderServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSenderServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SenderService_ServiceDesc, srv)
+}
+
+func _SenderService_CreateMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SenderServiceServer).CreateMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SenderService_CreateMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SenderServiceServer).CreateMessage(ctx, req.(*CreateMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SenderService_BatchCreateMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SenderServiceServer).BatchCreateMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SenderService_BatchCreateMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SenderServiceServer).BatchCreateMessages(ctx, req.(*BatchCreateMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SenderService_GetMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SenderServiceServer).GetMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SenderService_GetMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SenderServiceServer).GetMessage(ctx, req.(*GetMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SenderService_ListMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListMessagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SenderServiceServer).ListMessages(m, &grpc.GenericServerStream[ListMessagesRequest, Message]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SenderService_ListMessagesServer = grpc.ServerStreamingServer[Message]
+
+func _SenderService_StartScheduler_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartSchedulerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SenderServiceServer).StartScheduler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SenderService_StartScheduler_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SenderServiceServer).StartScheduler(ctx, req.(*StartSchedulerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SenderService_StopScheduler_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopSchedulerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SenderServiceServer).StopScheduler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SenderService_StopScheduler_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SenderServiceServer).StopScheduler(ctx, req.(*StopSchedulerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SenderService_ServiceDesc is the grpc.ServiceDesc for SenderService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SenderService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sender.v1.SenderService",
+	HandlerType: (*SenderServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateMessage",
+			Handler:    _SenderService_CreateMessage_Handler,
+		},
+		{
+			MethodName: "BatchCreateMessages",
+			Handler:    _SenderService_BatchCreateMessages_Handler,
+		},
+		{
+			MethodName: "GetMessage",
+			Handler:    _SenderService_GetMessage_Handler,
+		},
+		{
+			MethodName: "StartScheduler",
+			Handler:    _SenderService_StartScheduler_Handler,
+		},
+		{
+			MethodName: "StopScheduler",
+			Handler:    _SenderService_StopScheduler_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListMessages",
+			Handler:       _SenderService_ListMessages_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sender.proto",
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"log"
+
+	_ "github.com/Furkan-Gulsen/reliable_messaging_system/docs"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/handlers"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/service"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/infrastructure/grpcserver"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/infrastructure/middleware"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/auth"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/config"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
+	mongoPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	redisClient "github.com/go-redis/redis/v8"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
+)
+
+// Dependencies are the adapters the sender service runs against. Redis is
+// only used by the redis rate limit backend and may be nil.
+type Dependencies struct {
+	Messages       mongoPort.MessageRepository
+	Outbox         mongoPort.OutboxRepository
+	Recurring      mongoPort.RecurringMessageRepository
+	SchedulerState mongoPort.SchedulerStateStore
+	Leases         mongoPort.LeaseStore
+	Queue          rabbitPort.MessageQueue
+	Redis          *redisClient.Client
+}
+
+// App is the sender service wired to its dependencies. The caller serves
+// Router and GRPC and closes the dependencies after Stop.
+type App struct {
+	Router *gin.Engine
+	GRPC   *grpc.Server
+
+	outbox       mongoPort.OutboxRepository
+	sender       *service.SenderService
+	recurring    *service.RecurringService
+	stopElection func()
+}
+
+func New(cfg *config.Config, deps Dependencies) *App {
+	sender := domain.NewMessageSender(cfg.MessageProcessor.BatchSize, cfg.MessageProcessor.PollInterval).
+		WithClaim(cfg.Instance.ID, cfg.MessageProcessor.ClaimLease)
+	leadership, stopElection := leader.Start(cfg.LeaderElection.Enabled, deps.Leases, "sender-scheduler", cfg.Instance.ID, cfg.LeaderElection.LeaseTTL)
+	senderService := service.NewSenderService(sender, deps.Messages, deps.Outbox, deps.Queue).
+		WithLeadership(leadership).
+		WithChangeStream(cfg.MessageProcessor.ChangeStream).
+		WithPublisherPool(cfg.MessageProcessor.PublisherPool).
+		WithTopology(cfg.RabbitMQ.Topology).
+		WithSchedulerState(deps.SchedulerState)
+	if batching := cfg.MessageProcessor.AdaptiveBatch; batching.Enabled {
+		senderService.WithAdaptiveBatching(domain.NewBatchSizer(batching.MinSize, batching.MaxSize, batching.BacklogPerConsumer))
+	}
+	recurringService := service.NewRecurringService(sender, deps.Recurring).
+		WithLeadership(leadership)
+
+	messageHandler := handlers.NewMessageHandler(senderService)
+	recurringHandler := handlers.NewRecurringHandler(recurringService)
+	messageHandlerV2 := handlers.NewMessageHandlerV2(senderService)
+
+	healthService := service.NewHealthService(deps.Messages, deps.Queue).WithLeadership(leadership)
+	healthHandler := handlers.NewHealthHandler(healthService)
+
+	apiLimiter := ratelimit.NewLimiter(cfg.RateLimit.Backend, deps.Redis, "sender-api", cfg.RateLimit.APIRPS, cfg.RateLimit.APIBurst)
+	apiKeys := auth.NewAPIKeys(cfg.Auth.APIKeys)
+
+	router := gin.Default()
+	router.Use(middleware.Correlation())
+
+	apiGroup := router.Group("/api/v1")
+	apiGroup.Use(middleware.RateLimitWithLimiter(apiLimiter))
+
+	// API routes
+	apiGroup.POST("/messages", messageHandler.SendMessage)
+	apiGroup.GET("/messages", messageHandler.ListMessages)
+	apiGroup.POST("/scheduler/start", messageHandler.StartScheduler)
+	apiGroup.POST("/scheduler/stop", messageHandler.StopScheduler)
+	apiGroup.GET("/scheduler", messageHandler.GetScheduler)
+	apiGroup.PATCH("/scheduler", messageHandler.UpdateScheduler)
+	apiGroup.POST("/recurring-messages", recurringHandler.CreateRecurringMessage)
+	apiGroup.GET("/recurring-messages", recurringHandler.ListRecurringMessages)
+	apiGroup.POST("/recurring-messages/:id/pause", recurringHandler.PauseRecurringMessage)
+	apiGroup.POST("/recurring-messages/:id/resume", recurringHandler.ResumeRecurringMessage)
+	apiGroup.DELETE("/recurring-messages/:id", recurringHandler.DeleteRecurringMessage)
+	apiGroup.GET("/recurring-messages/:id/occurrences", recurringHandler.PreviewRecurringMessage)
+	apiGroup.GET("/status", healthHandler.GetStatus)
+
+	apiV2Group := router.Group("/api/v2")
+	apiV2Group.Use(middleware.ProblemRateLimit(apiLimiter))
+	apiV2Group.POST("/messages", messageHandlerV2.SendMessage)
+	apiV2Group.GET("/messages", messageHandlerV2.ListMessages)
+	apiV2Group.POST("/scheduler/start", messageHandlerV2.StartScheduler)
+	apiV2Group.POST("/scheduler/stop", messageHandlerV2.StopScheduler)
+	apiV2Group.GET("/scheduler", messageHandlerV2.GetScheduler)
+	apiV2Group.PATCH("/scheduler", messageHandlerV2.UpdateScheduler)
+	apiV2Group.GET("/status", healthHandler.GetStatus)
+	router.NoRoute(handlers.NoRoute)
+
+	// Swagger documentation
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	return &App{
+		Router:       router,
+		GRPC:         grpcserver.NewGRPCServer(senderService, apiKeys, apiLimiter),
+		outbox:       deps.Outbox,
+		sender:       senderService,
+		recurring:    recurringService,
+		stopElection: stopElection,
+	}
+}
+
+// Start enqueues unsent messages written before the outbox existed, restores
+// the scheduler's saved state and starts the recurring message scheduler.
+func (a *App) Start(ctx context.Context) {
+	if enqueued, err := a.outbox.EnqueueUnsentMessages(ctx); err != nil {
+		log.Printf("Failed to enqueue unsent messages into the outbox: %v", err)
+	} else if enqueued > 0 {
+		log.Printf("Enqueued %d unsent messages into the outbox", enqueued)
+	}
+	if err := a.sender.RestoreScheduler(ctx); err != nil {
+		log.Printf("Failed to restore scheduler state: %v", err)
+	}
+	a.recurring.Start()
+}
+
+func (a *App) Stop() {
+	a.GRPC.GracefulStop()
+	a.sender.Close()
+	a.recurring.Stop()
+	a.stopElection()
+}
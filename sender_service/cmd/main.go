@@ -3,22 +3,20 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
-	_ "github.com/Furkan-Gulsen/reliable_messaging_system/docs"
-	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/handlers"
-	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/service"
-	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/domain"
-	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/infrastructure/middleware"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/app"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/adapters"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/config"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
 
-	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	redisClient "github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -40,48 +38,68 @@ func main() {
 	defer mongodbDisconnect(ctx, mongoClient)
 
 	db := mongoClient.Database(cfg.MongoDB.Database)
-	messageRepo := adapters.NewMessageRepository(db)
-	messageQueue, err := adapters.NewMessageQueue(cfg.RabbitMQ.URI)
-	if err != nil {
-		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
-	}
-
-	sender := domain.NewMessageSender(2, 2*time.Minute)
-	senderService := service.NewSenderService(sender, messageRepo, messageQueue)
-	messageHandler := handlers.NewMessageHandler(senderService)
-
-	healthService := service.NewHealthService(messageRepo, messageQueue)
-	healthHandler := handlers.NewHealthHandler(healthService)
-
-	router := gin.Default()
-
-	apiGroup := router.Group("/api/v1")
-	apiGroup.Use(middleware.RateLimit(50, 100)) 
 
+	var redisConn *redisClient.Client
+	if cfg.RateLimit.Backend == ratelimit.BackendRedis || cfg.MessageQueue.Backend == adapters.QueueBackendRedis {
+		redisConn = redisClient.NewClient(&redisClient.Options{
+			Addr:     cfg.Redis.URI,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+	}
 
-	// API routes
-	apiGroup.POST("/messages", messageHandler.SendMessage)
-	apiGroup.GET("/messages", messageHandler.ListMessages)
-	apiGroup.POST("/scheduler/start", messageHandler.StartScheduler)
-	apiGroup.POST("/scheduler/stop", messageHandler.StopScheduler)
-	apiGroup.GET("/status", healthHandler.GetStatus)
+	var messageQueue rabbitPort.MessageQueue
+	switch cfg.MessageQueue.Backend {
+	case adapters.QueueBackendRabbitMQ:
+		messageQueue, err = adapters.NewMessageQueue(cfg.RabbitMQ.URI, cfg.RabbitMQ.Topology)
+	case adapters.QueueBackendRedis:
+		messageQueue, err = adapters.NewRedisMessageQueue(redisConn, cfg.RabbitMQ.Topology, cfg.Instance.ID, cfg.MessageQueue.ClaimIdle)
+	default:
+		log.Fatalf("Unknown message queue backend %q", cfg.MessageQueue.Backend)
+	}
+	if err != nil {
+		log.Fatalf("Failed to connect to message queue: %v", err)
+	}
 
-	// Swagger documentation
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	senderApp := app.New(cfg, app.Dependencies{
+		Messages:       adapters.NewMessageRepository(db),
+		Outbox:         adapters.NewOutboxRepository(db),
+		Recurring:      adapters.NewRecurringMessageRepository(db),
+		SchedulerState: adapters.NewSchedulerStateStore(db),
+		Leases:         adapters.NewLeaseStore(db),
+		Queue:          messageQueue,
+		Redis:          redisConn,
+	})
+	senderApp.Start(ctx)
 
 	go func() {
-		if err := router.Run(":8080"); err != nil {
+		if err := senderApp.Router.Run(":8080"); err != nil {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
+	grpcListener, err := net.Listen("tcp", cfg.GRPC.Addr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", cfg.GRPC.Addr, err)
+	}
+	go func() {
+		if err := senderApp.GRPC.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	log.Println("Shutting down Message Sender Service...")
-	senderService.StopScheduler(ctx)
+	senderApp.Stop()
 	messageQueue.Close()
+	if redisConn != nil {
+		if err := redisConn.Close(); err != nil {
+			log.Printf("Error closing Redis connection: %v", err)
+		}
+	}
 	if err := mongoClient.Disconnect(nil); err != nil {
 		log.Printf("Error disconnecting from MongoDB: %v", err)
 	}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/problems"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 
 	"github.com/gin-gonic/gin"
@@ -30,6 +31,82 @@ type ListMessagesResponse struct {
 	Messages []models.Message `json:"messages"`
 }
 
+type UpdateSchedulerRequest struct {
+	BatchSize       *int `json:"batch_size" binding:"omitempty,min=1,max=1000" error:"batch_size must be between 1 and 1000" example:"10"`
+	IntervalSeconds *int `json:"interval_seconds" binding:"omitempty,min=1,max=86400" error:"interval_seconds must be between 1 and 86400" example:"30"`
+}
+
+type BatchResultResponse struct {
+	Claimed   int      `json:"claimed"`
+	Published int      `json:"published"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+type SchedulerResponse struct {
+	Running           bool                 `json:"running"`
+	Leader            bool                 `json:"leader"`
+	BatchSize         int                  `json:"batch_size" example:"2"`
+	IntervalSeconds   int                  `json:"interval_seconds" example:"120"`
+	LastRunAt         *time.Time           `json:"last_run_at,omitempty"`
+	NextRunAt         *time.Time           `json:"next_run_at,omitempty"`
+	LastResult        *BatchResultResponse `json:"last_result,omitempty"`
+	LastError         string               `json:"last_error,omitempty"`
+	Paused            bool                 `json:"paused"`
+	BatchDecision     string               `json:"batch_decision,omitempty"`
+	AdaptiveBatchSize int                  `json:"adaptive_batch_size,omitempty" example:"2"`
+}
+
+func (r UpdateSchedulerRequest) settings() ports.SchedulerSettings {
+	var settings ports.SchedulerSettings
+	if r.BatchSize != nil {
+		settings.BatchSize = *r.BatchSize
+	}
+	if r.IntervalSeconds != nil {
+		settings.Interval = time.Duration(*r.IntervalSeconds) * time.Second
+	}
+	return settings
+}
+
+func newSchedulerResponse(status ports.SchedulerStatus) SchedulerResponse {
+	resp := SchedulerResponse{
+		Running:           status.Running,
+		Leader:            status.Leader,
+		BatchSize:         status.BatchSize,
+		IntervalSeconds:   int(status.Interval / time.Second),
+		LastRunAt:         status.LastRunAt,
+		NextRunAt:         status.NextRunAt,
+		LastError:         status.LastError,
+		Paused:            status.Paused,
+		BatchDecision:     status.Decision,
+		AdaptiveBatchSize: status.AdaptiveBatchSize,
+	}
+	if status.LastResult != nil {
+		resp.LastResult = &BatchResultResponse{
+			Claimed:   status.LastResult.Claimed,
+			Published: status.LastResult.Published,
+			Failed:    status.LastResult.Failed,
+			Errors:    status.LastResult.Errors,
+		}
+	}
+	return resp
+}
+
+// bindErrorResponse reports validation errors per field, using the messages
+// from req's `error` tags, and any other binding error as a single error.
+func bindErrorResponse(err error, req interface{}) gin.H {
+	problem := problems.FromBindError(err, req)
+	if len(problem.Errors) == 0 {
+		return gin.H{"error": err.Error()}
+	}
+
+	errors := make(map[string]string)
+	for _, fe := range problem.Errors {
+		errors[fe.Field] = fe.Message
+	}
+	return gin.H{"errors": errors}
+}
+
 func NewMessageHandler(service ports.MessageService) *MessageHandler {
 	return &MessageHandler{
 		service: service,
@@ -133,4 +210,48 @@ func (h *MessageHandler) StopScheduler(c *gin.Context) {
 
 	h.service.StopScheduler(ctx)
 	c.JSON(http.StatusOK, gin.H{"status": "scheduler stopped"})
-} 
\ No newline at end of file
+}
+
+// GetScheduler handles scheduler status requests
+// @Summary Get scheduler status
+// @Description Get the scheduler's running state, settings and last batch result
+// @Tags scheduler
+// @Produce json
+// @Success 200 {object} SchedulerResponse
+// @Router /scheduler [get]
+func (h *MessageHandler) GetScheduler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	c.JSON(http.StatusOK, newSchedulerResponse(h.service.SchedulerStatus(ctx)))
+}
+
+// UpdateScheduler handles scheduler settings changes
+// @Summary Update scheduler settings
+// @Description Change the batch size and interval without a restart
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Param settings body UpdateSchedulerRequest true "Settings to change"
+// @Success 200 {object} SchedulerResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /scheduler [patch]
+func (h *MessageHandler) UpdateScheduler(c *gin.Context) {
+	var req UpdateSchedulerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorResponse(err, req))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := h.service.UpdateScheduler(ctx, req.settings())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newSchedulerResponse(status))
+}
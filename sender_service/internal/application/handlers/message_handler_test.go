@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 )
 
@@ -26,6 +27,22 @@ func (m *MockSenderService) CreateMessage(ctx context.Context, content string, t
 	return args.Get(0).(primitive.ObjectID), args.Error(1)
 }
 
+func (m *MockSenderService) BatchCreateMessages(ctx context.Context, messages []ports.NewMessage) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx, messages)
+	if ids, ok := args.Get(0).([]primitive.ObjectID); ok {
+		return ids, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockSenderService) GetMessage(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
+	args := m.Called(ctx, id)
+	if msg, ok := args.Get(0).(*models.Message); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockSenderService) ListMessages(ctx context.Context) ([]models.Message, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]models.Message), args.Error(1)
@@ -39,6 +56,16 @@ func (m *MockSenderService) StopScheduler(ctx context.Context) {
 	m.Called(ctx)
 }
 
+func (m *MockSenderService) SchedulerStatus(ctx context.Context) ports.SchedulerStatus {
+	args := m.Called(ctx)
+	return args.Get(0).(ports.SchedulerStatus)
+}
+
+func (m *MockSenderService) UpdateScheduler(ctx context.Context, settings ports.SchedulerSettings) (ports.SchedulerStatus, error) {
+	args := m.Called(ctx, settings)
+	return args.Get(0).(ports.SchedulerStatus), args.Error(1)
+}
+
 func TestMessageHandler_SendMessage(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -216,4 +243,73 @@ func TestMessageHandler_Scheduler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("get scheduler status", func(t *testing.T) {
+		lastRunAt := time.Now()
+		mockService := new(MockSenderService)
+		mockService.On("SchedulerStatus", mock.Anything).Return(ports.SchedulerStatus{
+			Running:    true,
+			Leader:     true,
+			BatchSize:  5,
+			Interval:   30 * time.Second,
+			LastRunAt:  &lastRunAt,
+			LastResult: &ports.BatchResult{Claimed: 2, Published: 1, Failed: 1, Errors: []string{"publish failed"}},
+		})
+
+		handler := NewMessageHandler(mockService)
+		router := gin.New()
+		router.GET("/scheduler", handler.GetScheduler)
+
+		req := httptest.NewRequest(http.MethodGet, "/scheduler", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp SchedulerResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Running)
+		assert.Equal(t, 30, resp.IntervalSeconds)
+		assert.Equal(t, 1, resp.LastResult.Failed)
+		assert.Equal(t, []string{"publish failed"}, resp.LastResult.Errors)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("update scheduler settings", func(t *testing.T) {
+		mockService := new(MockSenderService)
+		mockService.On("UpdateScheduler", mock.Anything, ports.SchedulerSettings{BatchSize: 20, Interval: time.Minute}).
+			Return(ports.SchedulerStatus{BatchSize: 20, Interval: time.Minute}, nil)
+
+		handler := NewMessageHandler(mockService)
+		router := gin.New()
+		router.PATCH("/scheduler", handler.UpdateScheduler)
+
+		req := httptest.NewRequest(http.MethodPatch, "/scheduler", bytes.NewBufferString(`{"batch_size":20,"interval_seconds":60}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp SchedulerResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 20, resp.BatchSize)
+		assert.Equal(t, 60, resp.IntervalSeconds)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("update scheduler rejects invalid settings", func(t *testing.T) {
+		mockService := new(MockSenderService)
+
+		handler := NewMessageHandler(mockService)
+		router := gin.New()
+		router.PATCH("/scheduler", handler.UpdateScheduler)
+
+		req := httptest.NewRequest(http.MethodPatch, "/scheduler", bytes.NewBufferString(`{"batch_size":0}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "batch_size must be between 1 and 1000")
+		mockService.AssertNotCalled(t, "UpdateScheduler", mock.Anything, mock.Anything)
+	})
 } 
\ No newline at end of file
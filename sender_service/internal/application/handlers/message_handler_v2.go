@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/problems"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MessageHandlerV2 serves the /api/v2 routes. Every error is reported as an
+// application/problem+json document; internal error text is only logged.
+type MessageHandlerV2 struct {
+	service ports.MessageService
+}
+
+func NewMessageHandlerV2(service ports.MessageService) *MessageHandlerV2 {
+	return &MessageHandlerV2{
+		service: service,
+	}
+}
+
+// SendMessage handles message creation requests
+func (h *MessageHandlerV2) SendMessage(c *gin.Context) {
+	var req SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problems.Write(c, problems.FromBindError(err, req))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := h.service.CreateMessage(ctx, req.Content, req.To)
+	if err != nil {
+		log.Printf("v2: failed to create message: %v", err)
+		problems.Write(c, problems.Internal())
+		return
+	}
+
+	c.JSON(http.StatusOK, SendMessageResponse{Message: "Accepted", MessageId: id.Hex()})
+}
+
+// ListMessages handles message listing requests
+func (h *MessageHandlerV2) ListMessages(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	messages, err := h.service.ListMessages(ctx)
+	if err != nil {
+		log.Printf("v2: failed to list messages: %v", err)
+		problems.Write(c, problems.Internal())
+		return
+	}
+
+	c.JSON(http.StatusOK, ListMessagesResponse{Messages: messages})
+}
+
+// StartScheduler handles scheduler start requests
+func (h *MessageHandlerV2) StartScheduler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	h.service.StartScheduler(ctx)
+	c.JSON(http.StatusOK, gin.H{"status": "scheduler started"})
+}
+
+// StopScheduler handles scheduler stop requests
+func (h *MessageHandlerV2) StopScheduler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	h.service.StopScheduler(ctx)
+	c.JSON(http.StatusOK, gin.H{"status": "scheduler stopped"})
+}
+
+// GetScheduler handles scheduler status requests
+func (h *MessageHandlerV2) GetScheduler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	c.JSON(http.StatusOK, newSchedulerResponse(h.service.SchedulerStatus(ctx)))
+}
+
+// UpdateScheduler handles scheduler settings changes
+func (h *MessageHandlerV2) UpdateScheduler(c *gin.Context) {
+	var req UpdateSchedulerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problems.Write(c, problems.FromBindError(err, req))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := h.service.UpdateScheduler(ctx, req.settings())
+	if err != nil {
+		log.Printf("v2: failed to update scheduler: %v", err)
+		problems.Write(c, problems.Internal())
+		return
+	}
+
+	c.JSON(http.StatusOK, newSchedulerResponse(status))
+}
+
+// NoRoute reports unknown /api/v2 paths as problems and leaves other paths to
+// gin's default 404 response.
+func NoRoute(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/v2/") {
+		problems.Write(c, problems.NotFound("No route matches "+c.Request.Method+" "+c.Request.URL.Path))
+	}
+}
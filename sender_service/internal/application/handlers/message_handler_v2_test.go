@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/problems"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+)
+
+func TestMessageHandlerV2_SendMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockSenderService)
+		expectedStatus int
+		expectedCode   problems.Code
+		expectedFields map[string]string
+	}{
+		{
+			name: "successful message creation",
+			body: `{"to":"+905321234567","content":"test content"}`,
+			setupMock: func(m *MockSenderService) {
+				m.On("CreateMessage", mock.Anything, "test content", "+905321234567").Return(primitive.NewObjectID(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "validation error - field details from request struct",
+			body:           `{"to":"05321234111","content":""}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   problems.CodeValidation,
+			expectedFields: map[string]string{
+				"to":      "Phone number must be in E.164 format (e.g., +90111111111)",
+				"content": "content is required",
+			},
+		},
+		{
+			name:           "validation error - content too long",
+			body:           `{"to":"+905321234567","content":"` + string(bytes.Repeat([]byte("a"), 251)) + `"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   problems.CodeValidation,
+			expectedFields: map[string]string{
+				"content": "Content must not exceed 250 characters",
+			},
+		},
+		{
+			name:           "malformed body",
+			body:           `{"to":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   problems.CodeMalformedBody,
+		},
+		{
+			name: "service error does not leak internals",
+			body: `{"to":"+905321234567","content":"test content"}`,
+			setupMock: func(m *MockSenderService) {
+				m.On("CreateMessage", mock.Anything, "test content", "+905321234567").Return(primitive.NilObjectID, errors.New("failed to create message: connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   problems.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSenderService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			handler := NewMessageHandlerV2(mockService)
+			router := gin.New()
+			router.POST("/api/v2/messages", handler.SendMessage)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/messages", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+
+			if tt.expectedCode == "" {
+				return
+			}
+
+			assert.Equal(t, problems.ContentType, w.Header().Get("Content-Type"))
+			assert.NotContains(t, w.Body.String(), "connection refused")
+
+			var problem problems.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+			assert.Equal(t, problems.TypeURI(tt.expectedCode), problem.Type)
+			assert.Equal(t, tt.expectedStatus, problem.Status)
+			assert.Equal(t, "/api/v2/messages", problem.Instance)
+
+			fields := make(map[string]string)
+			for _, fe := range problem.Errors {
+				fields[fe.Field] = fe.Message
+			}
+			for field, message := range tt.expectedFields {
+				assert.Equal(t, message, fields[field])
+			}
+		})
+	}
+}
+
+func TestMessageHandlerV2_ListMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful messages listing", func(t *testing.T) {
+		mockService := new(MockSenderService)
+		mockService.On("ListMessages", mock.Anything).Return([]models.Message{{ID: primitive.NewObjectID()}}, nil)
+
+		handler := NewMessageHandlerV2(mockService)
+		router := gin.New()
+		router.GET("/api/v2/messages", handler.ListMessages)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/messages", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("repository error returns internal problem", func(t *testing.T) {
+		mockService := new(MockSenderService)
+		mockService.On("ListMessages", mock.Anything).Return([]models.Message{}, errors.New("mongo: no reachable servers"))
+
+		handler := NewMessageHandlerV2(mockService)
+		router := gin.New()
+		router.GET("/api/v2/messages", handler.ListMessages)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/messages", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, problems.ContentType, w.Header().Get("Content-Type"))
+		assert.NotContains(t, w.Body.String(), "mongo")
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestNoRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.NoRoute(NoRoute)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/unknown", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problems.ContentType, w.Header().Get("Content-Type"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotEqual(t, problems.ContentType, w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RecurringHandler struct {
+	service ports.RecurringMessageService
+}
+
+type CreateRecurringMessageRequest struct {
+	Name     string `json:"name" binding:"required,max=100" error:"name must not exceed 100 characters" example:"Weekday standup reminder"`
+	Schedule string `json:"schedule" binding:"required" example:"0 9 * * 1-5"`
+	Timezone string `json:"timezone" example:"Europe/Istanbul"`
+	Content  string `json:"content" binding:"required,max=250" error:"Content must not exceed 250 characters" example:"Standup starts at 9:15 on {{.Time.Format \"Monday\"}}"`
+	To       string `json:"to" binding:"required,e164" error:"Phone number must be in E.164 format (e.g., +90111111111)" example:"+90111111111"`
+}
+
+type ListRecurringMessagesResponse struct {
+	RecurringMessages []models.RecurringMessage `json:"recurring_messages"`
+}
+
+type PreviewRecurringMessageQuery struct {
+	Count int `form:"count,default=5" binding:"min=1,max=100" error:"count must be between 1 and 100"`
+}
+
+type PreviewRecurringMessageResponse struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+func NewRecurringHandler(service ports.RecurringMessageService) *RecurringHandler {
+	return &RecurringHandler{
+		service: service,
+	}
+}
+
+// CreateRecurringMessage handles recurring message creation requests
+// @Summary Create a recurring message
+// @Description Create a message that is sent on every occurrence of a cron schedule. The content is a Go template rendered with .Time and .To.
+// @Tags recurring-messages
+// @Accept json
+// @Produce json
+// @Param recurring body CreateRecurringMessageRequest true "Recurring message to create"
+// @Success 201 {object} models.RecurringMessage
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /recurring-messages [post]
+func (h *RecurringHandler) CreateRecurringMessage(c *gin.Context) {
+	var req CreateRecurringMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorResponse(err, req))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	recurring, err := h.service.CreateRecurringMessage(ctx, ports.NewRecurringMessage{
+		Name:     req.Name,
+		Schedule: req.Schedule,
+		Timezone: req.Timezone,
+		Content:  req.Content,
+		To:       req.To,
+	})
+	if err != nil {
+		writeRecurringError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, recurring)
+}
+
+// ListRecurringMessages handles recurring message listing requests
+// @Summary List recurring messages
+// @Description Get all recurring message definitions
+// @Tags recurring-messages
+// @Produce json
+// @Success 200 {object} ListRecurringMessagesResponse
+// @Failure 500 {object} map[string]string
+// @Router /recurring-messages [get]
+func (h *RecurringHandler) ListRecurringMessages(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	recurring, err := h.service.ListRecurringMessages(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListRecurringMessagesResponse{RecurringMessages: recurring})
+}
+
+// PauseRecurringMessage handles recurring message pause requests
+// @Summary Pause a recurring message
+// @Description Stop creating messages for a recurring definition
+// @Tags recurring-messages
+// @Produce json
+// @Param id path string true "Recurring message ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /recurring-messages/{id}/pause [post]
+func (h *RecurringHandler) PauseRecurringMessage(c *gin.Context) {
+	h.update(c, h.service.PauseRecurringMessage, "recurring message paused")
+}
+
+// ResumeRecurringMessage handles recurring message resume requests
+// @Summary Resume a recurring message
+// @Description Resume a paused definition from its next occurrence
+// @Tags recurring-messages
+// @Produce json
+// @Param id path string true "Recurring message ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /recurring-messages/{id}/resume [post]
+func (h *RecurringHandler) ResumeRecurringMessage(c *gin.Context) {
+	h.update(c, h.service.ResumeRecurringMessage, "recurring message resumed")
+}
+
+// DeleteRecurringMessage handles recurring message deletion requests
+// @Summary Delete a recurring message
+// @Description Delete a recurring definition; messages already created are kept
+// @Tags recurring-messages
+// @Produce json
+// @Param id path string true "Recurring message ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /recurring-messages/{id} [delete]
+func (h *RecurringHandler) DeleteRecurringMessage(c *gin.Context) {
+	h.update(c, h.service.DeleteRecurringMessage, "recurring message deleted")
+}
+
+// PreviewRecurringMessage handles occurrence preview requests
+// @Summary Preview occurrences
+// @Description Get the next occurrences of a recurring message
+// @Tags recurring-messages
+// @Produce json
+// @Param id path string true "Recurring message ID"
+// @Param count query int false "Number of occurrences (1-100)" default(5)
+// @Success 200 {object} PreviewRecurringMessageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /recurring-messages/{id}/occurrences [get]
+func (h *RecurringHandler) PreviewRecurringMessage(c *gin.Context) {
+	id, ok := recurringID(c)
+	if !ok {
+		return
+	}
+
+	var query PreviewRecurringMessageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, bindErrorResponse(err, query))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	occurrences, err := h.service.PreviewRecurringMessage(ctx, id, query.Count)
+	if err != nil {
+		writeRecurringError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, PreviewRecurringMessageResponse{Occurrences: occurrences})
+}
+
+func (h *RecurringHandler) update(c *gin.Context, apply func(context.Context, primitive.ObjectID) error, status string) {
+	id, ok := recurringID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := apply(ctx, id); err != nil {
+		writeRecurringError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
+
+func recurringID(c *gin.Context) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring message id"})
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
+
+func writeRecurringError(c *gin.Context, err error) {
+	var invalid *ports.InvalidRecurringMessageError
+	switch {
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Error()})
+	case errors.Is(err, ports.ErrRecurringMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
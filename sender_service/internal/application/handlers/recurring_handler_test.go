@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+)
+
+type MockRecurringService struct {
+	mock.Mock
+}
+
+func (m *MockRecurringService) CreateRecurringMessage(ctx context.Context, recurring ports.NewRecurringMessage) (*models.RecurringMessage, error) {
+	args := m.Called(ctx, recurring)
+	if r, ok := args.Get(0).(*models.RecurringMessage); ok {
+		return r, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRecurringService) ListRecurringMessages(ctx context.Context) ([]models.RecurringMessage, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.RecurringMessage), args.Error(1)
+}
+
+func (m *MockRecurringService) PauseRecurringMessage(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRecurringService) ResumeRecurringMessage(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRecurringService) DeleteRecurringMessage(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRecurringService) PreviewRecurringMessage(ctx context.Context, id primitive.ObjectID, count int) ([]time.Time, error) {
+	args := m.Called(ctx, id, count)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func newRecurringRouter(service *MockRecurringService) *gin.Engine {
+	handler := NewRecurringHandler(service)
+	router := gin.New()
+	router.POST("/recurring-messages", handler.CreateRecurringMessage)
+	router.GET("/recurring-messages", handler.ListRecurringMessages)
+	router.POST("/recurring-messages/:id/pause", handler.PauseRecurringMessage)
+	router.POST("/recurring-messages/:id/resume", handler.ResumeRecurringMessage)
+	router.DELETE("/recurring-messages/:id", handler.DeleteRecurringMessage)
+	router.GET("/recurring-messages/:id/occurrences", handler.PreviewRecurringMessage)
+	return router
+}
+
+func TestRecurringHandler_CreateRecurringMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockRecurringService)
+		expectedStatus int
+	}{
+		{
+			name: "created",
+			body: `{"name":"standup","schedule":"0 9 * * 1-5","timezone":"Europe/Istanbul","content":"Standup","to":"+905321234567"}`,
+			setupMock: func(m *MockRecurringService) {
+				m.On("CreateRecurringMessage", mock.Anything, ports.NewRecurringMessage{
+					Name:     "standup",
+					Schedule: "0 9 * * 1-5",
+					Timezone: "Europe/Istanbul",
+					Content:  "Standup",
+					To:       "+905321234567",
+				}).Return(&models.RecurringMessage{ID: primitive.NewObjectID()}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "validation error",
+			body:           `{"name":"standup","schedule":"0 9 * * *","content":"Standup","to":"05321234567"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid schedule",
+			body: `{"name":"standup","schedule":"daily","content":"Standup","to":"+905321234567"}`,
+			setupMock: func(m *MockRecurringService) {
+				m.On("CreateRecurringMessage", mock.Anything, mock.Anything).
+					Return(nil, &ports.InvalidRecurringMessageError{Reason: "invalid cron expression"})
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRecurringService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/recurring-messages", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newRecurringRouter(mockService).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRecurringHandler_PauseResumeDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		serviceMethod  string
+		err            error
+		expectedStatus int
+	}{
+		{name: "pause", method: http.MethodPost, path: "/pause", serviceMethod: "PauseRecurringMessage", expectedStatus: http.StatusOK},
+		{name: "resume", method: http.MethodPost, path: "/resume", serviceMethod: "ResumeRecurringMessage", expectedStatus: http.StatusOK},
+		{name: "delete", method: http.MethodDelete, serviceMethod: "DeleteRecurringMessage", expectedStatus: http.StatusOK},
+		{name: "not found", method: http.MethodDelete, serviceMethod: "DeleteRecurringMessage", err: ports.ErrRecurringMessageNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRecurringService)
+			mockService.On(tt.serviceMethod, mock.Anything, id).Return(tt.err)
+
+			req := httptest.NewRequest(tt.method, "/recurring-messages/"+id.Hex()+tt.path, nil)
+			w := httptest.NewRecorder()
+			newRecurringRouter(mockService).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("invalid id", func(t *testing.T) {
+		mockService := new(MockRecurringService)
+
+		req := httptest.NewRequest(http.MethodPost, "/recurring-messages/not-an-id/pause", nil)
+		w := httptest.NewRecorder()
+		newRecurringRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRecurringHandler_PreviewRecurringMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := primitive.NewObjectID()
+	occurrences := []time.Time{time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)}
+
+	mockService := new(MockRecurringService)
+	mockService.On("PreviewRecurringMessage", mock.Anything, id, 5).Return(occurrences, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/recurring-messages/"+id.Hex()+"/occurrences", nil)
+	w := httptest.NewRecorder()
+	newRecurringRouter(mockService).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp PreviewRecurringMessageResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Occurrences, 1)
+	mockService.AssertExpectations(t)
+
+	req = httptest.NewRequest(http.MethodGet, "/recurring-messages/"+id.Hex()+"/occurrences?count=500", nil)
+	w = httptest.NewRecorder()
+	newRecurringRouter(mockService).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "count must be between 1 and 100")
+}
@@ -8,9 +8,18 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type NewMessage struct {
+	Content string
+	To      string
+}
+
 type MessageService interface {
 	CreateMessage(ctx context.Context, content string, to string) (primitive.ObjectID, error)
+	BatchCreateMessages(ctx context.Context, messages []NewMessage) ([]primitive.ObjectID, error)
+	GetMessage(ctx context.Context, id primitive.ObjectID) (*models.Message, error)
 	ListMessages(ctx context.Context) ([]models.Message, error)
 	StartScheduler(ctx context.Context)
 	StopScheduler(ctx context.Context)
+	SchedulerStatus(ctx context.Context) SchedulerStatus
+	UpdateScheduler(ctx context.Context, settings SchedulerSettings) (SchedulerStatus, error)
 } 
\ No newline at end of file
@@ -0,0 +1,40 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrRecurringMessageNotFound = errors.New("recurring message not found")
+
+// InvalidRecurringMessageError reports a definition whose schedule,
+// timezone or content template cannot be used.
+type InvalidRecurringMessageError struct {
+	Reason string
+}
+
+func (e *InvalidRecurringMessageError) Error() string {
+	return "invalid recurring message: " + e.Reason
+}
+
+type NewRecurringMessage struct {
+	Name     string
+	Schedule string
+	Timezone string
+	Content  string
+	To       string
+}
+
+type RecurringMessageService interface {
+	CreateRecurringMessage(ctx context.Context, recurring NewRecurringMessage) (*models.RecurringMessage, error)
+	ListRecurringMessages(ctx context.Context) ([]models.RecurringMessage, error)
+	PauseRecurringMessage(ctx context.Context, id primitive.ObjectID) error
+	ResumeRecurringMessage(ctx context.Context, id primitive.ObjectID) error
+	DeleteRecurringMessage(ctx context.Context, id primitive.ObjectID) error
+	PreviewRecurringMessage(ctx context.Context, id primitive.ObjectID, count int) ([]time.Time, error)
+}
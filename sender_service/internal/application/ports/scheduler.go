@@ -0,0 +1,33 @@
+package ports
+
+import "time"
+
+// SchedulerSettings changes the scheduler at runtime. Zero fields are left
+// unchanged.
+type SchedulerSettings struct {
+	BatchSize int
+	Interval  time.Duration
+}
+
+type BatchResult struct {
+	Claimed   int
+	Published int
+	Failed    int
+	Errors    []string
+}
+
+type SchedulerStatus struct {
+	Running    bool
+	Leader     bool
+	BatchSize  int
+	Interval   time.Duration
+	LastRunAt  *time.Time
+	NextRunAt  *time.Time
+	LastResult *BatchResult
+	LastError  string
+	// Paused, Decision and AdaptiveBatchSize report the last adaptive batch
+	// sizing decision. AdaptiveBatchSize is zero without adaptive batching.
+	Paused            bool
+	Decision          string
+	AdaptiveBatchSize int
+}
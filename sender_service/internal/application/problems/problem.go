@@ -0,0 +1,131 @@
+package problems
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const ContentType = "application/problem+json"
+
+const typeBaseURI = "https://github.com/Furkan-Gulsen/reliable_messaging_system/blob/main/docs/problems.md#"
+
+type Code string
+
+const (
+	CodeValidation    Code = "validation-error"
+	CodeMalformedBody Code = "malformed-request"
+	CodeNotFound      Code = "not-found"
+	CodeRateLimited   Code = "rate-limited"
+	CodeInternal      Code = "internal-error"
+)
+
+var titles = map[Code]string{
+	CodeValidation:    "Request validation failed",
+	CodeMalformedBody: "Malformed request body",
+	CodeNotFound:      "Resource not found",
+	CodeRateLimited:   "Rate limit exceeded",
+	CodeInternal:      "Internal server error",
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details document extended with a stable
+// machine-readable code and optional field-level validation errors.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     Code         `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+func New(status int, code Code, detail string) *Problem {
+	return &Problem{
+		Type:   TypeURI(code),
+		Title:  titles[code],
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func TypeURI(code Code) string {
+	return typeBaseURI + string(code)
+}
+
+func Write(c *gin.Context, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+func Internal() *Problem {
+	return New(http.StatusInternalServerError, CodeInternal, "An unexpected error occurred while handling the request")
+}
+
+func RateLimited() *Problem {
+	return New(http.StatusTooManyRequests, CodeRateLimited, "Too many requests, please retry later")
+}
+
+func NotFound(detail string) *Problem {
+	return New(http.StatusNotFound, CodeNotFound, detail)
+}
+
+// FromBindError converts a binding error for req into a problem. Field
+// messages come from the struct's `error` tags and names from its `json` tags.
+func FromBindError(err error, req interface{}) *Problem {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return New(http.StatusBadRequest, CodeMalformedBody, "The request body could not be parsed as JSON")
+	}
+
+	p := New(http.StatusBadRequest, CodeValidation, "One or more fields are invalid")
+	for _, fe := range ve {
+		p.Errors = append(p.Errors, fieldError(fe, req))
+	}
+	return p
+}
+
+func fieldError(fe validator.FieldError, req interface{}) FieldError {
+	name := fe.Field()
+	message := ""
+
+	t := reflect.TypeOf(req)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct {
+		if sf, ok := t.FieldByName(fe.StructField()); ok {
+			if jsonName := strings.Split(sf.Tag.Get("json"), ",")[0]; jsonName != "" && jsonName != "-" {
+				name = jsonName
+			}
+			message = sf.Tag.Get("error")
+		}
+	}
+
+	if fe.Tag() == "required" {
+		message = fmt.Sprintf("%s is required", name)
+	} else if message == "" {
+		message = fmt.Sprintf("%s failed the %s validation", name, fe.Tag())
+	}
+
+	return FieldError{
+		Field:   name,
+		Code:    fe.Tag(),
+		Message: message,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
 	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
 )
@@ -13,12 +14,15 @@ type HealthStatus struct {
 	MongoDB   bool `json:"mongodb"`
 	RabbitMQ  bool `json:"rabbitmq"`
 	Service   bool `json:"service"`
+	Leader    *leader.Status `json:"leader,omitempty"`
+	RabbitMQConnection *rabbitPort.ConnectionState `json:"rabbitmq_connection,omitempty"`
 }
 
 type HealthService struct {
 	repository   interfaces.MessageRepository
 	queue        rabbitPort.MessageQueue
 	processorURL string
+	leadership   leader.Leadership
 }
 
 func NewHealthService(repository interfaces.MessageRepository, queue rabbitPort.MessageQueue) *HealthService {
@@ -29,14 +33,21 @@ func NewHealthService(repository interfaces.MessageRepository, queue rabbitPort.
 	}
 }
 
+func (s *HealthService) WithLeadership(leadership leader.Leadership) *HealthService {
+	s.leadership = leadership
+	return s
+}
+
 func (s *HealthService) CheckHealth() HealthStatus {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	var status HealthStatus
 
+	connection := s.queue.ConnectionState()
+	status.RabbitMQConnection = &connection
 	_, err := s.queue.GetDLQMessageCount()
-	status.RabbitMQ = err == nil
+	status.RabbitMQ = err == nil && connection.Connected
 
 	req, _ := http.NewRequestWithContext(ctx, "GET", s.processorURL, nil)
 	client := &http.Client{}
@@ -51,5 +62,10 @@ func (s *HealthService) CheckHealth() HealthStatus {
 	_, err = s.repository.ListMessages(ctx)
 	status.MongoDB = err == nil
 
+	if s.leadership != nil {
+		leaderStatus := s.leadership.Status()
+		status.Leader = &leaderStatus
+	}
+
 	return status
 }
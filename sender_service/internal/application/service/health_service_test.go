@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 	mongoInterfaces "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
 	rabbitInterfaces "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
@@ -35,7 +36,15 @@ func (m *mockHealthQueue) GetDLQMessageCount() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *mockHealthQueue) ConnectionState() rabbitInterfaces.ConnectionState {
+	args := m.Called()
+	return args.Get(0).(rabbitInterfaces.ConnectionState)
+}
+
 func TestHealthService_CheckHealth(t *testing.T) {
+	connected := rabbitInterfaces.ConnectionState{Connected: true}
+	disconnected := rabbitInterfaces.ConnectionState{Connected: false, Reconnects: 2, LastError: "connection reset"}
+
 	tests := []struct {
 		name                string
 		setupMocks         func(*mockHealthRepository, *mockHealthQueue)
@@ -46,6 +55,7 @@ func TestHealthService_CheckHealth(t *testing.T) {
 			name: "all services healthy",
 			setupMocks: func(repo *mockHealthRepository, queue *mockHealthQueue) {
 				repo.On("ListMessages", mock.Anything).Return([]models.Message{}, nil)
+				queue.On("ConnectionState").Return(connected)
 				queue.On("GetDLQMessageCount").Return(0, nil)
 			},
 			setupProcessorMock: func(server *httptest.Server) {
@@ -54,15 +64,17 @@ func TestHealthService_CheckHealth(t *testing.T) {
 				})
 			},
 			expectedStatus: HealthStatus{
-				MongoDB:   true,
-				RabbitMQ:  true,
-				Service:   true,
+				MongoDB:            true,
+				RabbitMQ:           true,
+				Service:            true,
+				RabbitMQConnection: &connected,
 			},
 		},
 		{
 			name: "mongodb unhealthy",
 			setupMocks: func(repo *mockHealthRepository, queue *mockHealthQueue) {
 				repo.On("ListMessages", mock.Anything).Return([]models.Message{}, assert.AnError)
+				queue.On("ConnectionState").Return(connected)
 				queue.On("GetDLQMessageCount").Return(0, nil)
 			},
 			setupProcessorMock: func(server *httptest.Server) {
@@ -71,15 +83,17 @@ func TestHealthService_CheckHealth(t *testing.T) {
 				})
 			},
 			expectedStatus: HealthStatus{
-				MongoDB:   false,
-				RabbitMQ:  true,
-				Service:   true,
+				MongoDB:            false,
+				RabbitMQ:           true,
+				Service:            true,
+				RabbitMQConnection: &connected,
 			},
 		},
 		{
 			name: "rabbitmq unhealthy",
 			setupMocks: func(repo *mockHealthRepository, queue *mockHealthQueue) {
 				repo.On("ListMessages", mock.Anything).Return([]models.Message{}, nil)
+				queue.On("ConnectionState").Return(connected)
 				queue.On("GetDLQMessageCount").Return(0, assert.AnError)
 			},
 			setupProcessorMock: func(server *httptest.Server) {
@@ -88,15 +102,36 @@ func TestHealthService_CheckHealth(t *testing.T) {
 				})
 			},
 			expectedStatus: HealthStatus{
-				MongoDB:   true,
-				RabbitMQ:  false,
-				Service:   true,
+				MongoDB:            true,
+				RabbitMQ:           false,
+				Service:            true,
+				RabbitMQConnection: &connected,
+			},
+		},
+		{
+			name: "rabbitmq reconnecting",
+			setupMocks: func(repo *mockHealthRepository, queue *mockHealthQueue) {
+				repo.On("ListMessages", mock.Anything).Return([]models.Message{}, nil)
+				queue.On("ConnectionState").Return(disconnected)
+				queue.On("GetDLQMessageCount").Return(0, rabbitInterfaces.ErrNotConnected)
+			},
+			setupProcessorMock: func(server *httptest.Server) {
+				server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})
+			},
+			expectedStatus: HealthStatus{
+				MongoDB:            true,
+				RabbitMQ:           false,
+				Service:            true,
+				RabbitMQConnection: &disconnected,
 			},
 		},
 		{
 			name: "processor service unhealthy",
 			setupMocks: func(repo *mockHealthRepository, queue *mockHealthQueue) {
 				repo.On("ListMessages", mock.Anything).Return([]models.Message{}, nil)
+				queue.On("ConnectionState").Return(connected)
 				queue.On("GetDLQMessageCount").Return(0, nil)
 			},
 			setupProcessorMock: func(server *httptest.Server) {
@@ -105,9 +140,10 @@ func TestHealthService_CheckHealth(t *testing.T) {
 				})
 			},
 			expectedStatus: HealthStatus{
-				MongoDB:   true,
-				RabbitMQ:  true,
-				Service:   false,
+				MongoDB:            true,
+				RabbitMQ:           true,
+				Service:            false,
+				RabbitMQConnection: &connected,
 			},
 		},
 	}
@@ -138,4 +174,23 @@ func TestHealthService_CheckHealth(t *testing.T) {
 			mockQueue.AssertExpectations(t)
 		})
 	}
-} 
\ No newline at end of file
+}
+
+func TestHealthService_CheckHealth_ReportsLeadership(t *testing.T) {
+	mockRepo := &mockHealthRepository{}
+	mockQueue := &mockHealthQueue{}
+	mockRepo.On("ListMessages", mock.Anything).Return([]models.Message{}, nil)
+	mockQueue.On("ConnectionState").Return(rabbitInterfaces.ConnectionState{Connected: true})
+	mockQueue.On("GetDLQMessageCount").Return(0, nil)
+
+	processorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer processorServer.Close()
+
+	service := NewHealthService(mockRepo, mockQueue).WithLeadership(stubLeadership{})
+	service.processorURL = fmt.Sprintf("%s/status", processorServer.URL)
+	status := service.CheckHealth()
+
+	assert.Equal(t, &leader.Status{Name: "sender-scheduler", Holder: "sender-1", IsLeader: true}, status.Leader)
+}
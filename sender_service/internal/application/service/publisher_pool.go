@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	rabbitPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// publishBatch publishes events on up to s.publishers workers and returns
+// the events the broker confirmed. Events that fail are released and
+// reported in result.
+func (s *MessageScheduler) publishBatch(ctx context.Context, events []models.OutboxEvent, result *ports.BatchResult) []*models.OutboxEvent {
+	workers := s.publishers
+	if workers > len(events) {
+		workers = len(events)
+	}
+
+	errs := make([]error, len(events))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			publisher, closePublisher := s.openPublisher()
+			defer closePublisher()
+
+			for i := range jobs {
+				event := &events[i]
+				errs[i] = s.handlers[event.Type].publish(ctx, publisher, event)
+			}
+		}()
+	}
+	for i := range events {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	published := make([]*models.OutboxEvent, 0, len(events))
+	for i := range events {
+		if errs[i] != nil {
+			s.failEvent(ctx, &events[i], errs[i], result)
+			continue
+		}
+		published = append(published, &events[i])
+	}
+	return published
+}
+
+// openPublisher gives a pool worker its own channel. A pool of one, or a
+// worker whose channel cannot be opened, publishes through the shared queue.
+func (s *MessageScheduler) openPublisher() (rabbitPort.Publisher, func()) {
+	if s.publishers <= 1 {
+		return s.service.queue, func() {}
+	}
+
+	publisher, err := s.service.queue.NewPublisher()
+	if err != nil {
+		log.Printf("Failed to open a publisher channel, using the shared channel: %v", err)
+		return s.service.queue, func() {}
+	}
+	return publisher, publisher.Close
+}
+
+// commitBatch applies the effects of the published events with one write
+// per event type, then deletes the events from the outbox in bulk.
+func (s *MessageScheduler) commitBatch(ctx context.Context, published []*models.OutboxEvent, result *ports.BatchResult) {
+	byType := make(map[string][]*models.OutboxEvent)
+	for _, event := range published {
+		byType[event.Type] = append(byType[event.Type], event)
+	}
+
+	completed := make([]primitive.ObjectID, 0, len(published))
+	for _, eventType := range s.eventTypes {
+		events := byType[eventType]
+		if len(events) == 0 {
+			continue
+		}
+		if err := s.handlers[eventType].commit(ctx, events); err != nil {
+			for _, event := range events {
+				s.failEvent(ctx, event, err, result)
+			}
+			continue
+		}
+		for _, event := range events {
+			completed = append(completed, event.ID)
+		}
+	}
+	if len(completed) == 0 {
+		return
+	}
+
+	deleted, err := s.service.outbox.CompleteEvents(ctx, completed, s.service.sender.GetInstanceID())
+	if err != nil {
+		log.Printf("Failed to complete %d published outbox events: %v", len(completed), err)
+		s.recordFailures(result, len(completed), fmt.Errorf("failed to complete outbox events: %v", err))
+		return
+	}
+
+	result.Published += deleted
+	if lost := len(completed) - deleted; lost > 0 {
+		log.Printf("Claims on %d outbox events expired before they were completed; another instance may publish them again", lost)
+		s.recordFailures(result, lost, fmt.Errorf("%d claims expired before completion", lost))
+	}
+}
+
+func (s *MessageScheduler) failEvent(ctx context.Context, event *models.OutboxEvent, err error, result *ports.BatchResult) {
+	log.Printf("Failed to handle outbox event %s: %v", event.ID.Hex(), err)
+	s.releaseEvent(ctx, event, err)
+	result.Failed++
+	if len(result.Errors) < maxReportedErrors {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", event.ID.Hex(), err))
+	}
+}
+
+func (s *MessageScheduler) recordFailures(result *ports.BatchResult, count int, err error) {
+	result.Failed += count
+	if len(result.Errors) < maxReportedErrors {
+		result.Errors = append(result.Errors, err.Error())
+	}
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	localDomain "github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	mongoPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	recurringCheckInterval = 15 * time.Second
+	recurringBatchLimit    = 100
+	maxContentLength       = 250
+)
+
+// RecurringService manages recurring message definitions and, while this
+// instance is the leader, creates a message for every due occurrence.
+type RecurringService struct {
+	sender     *localDomain.MessageSender
+	repository mongoPort.RecurringMessageRepository
+	leadership leader.Leadership
+	now        func() time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRecurringService(sender *localDomain.MessageSender, repository mongoPort.RecurringMessageRepository) *RecurringService {
+	return &RecurringService{
+		sender:     sender,
+		repository: repository,
+		leadership: leader.AlwaysLeader{Name: "sender-recurring", Holder: sender.GetInstanceID()},
+		now:        time.Now,
+	}
+}
+
+// WithLeadership makes the job create messages only while this instance
+// holds the given leadership.
+func (s *RecurringService) WithLeadership(leadership leader.Leadership) *RecurringService {
+	s.leadership = leadership
+	return s
+}
+
+func (s *RecurringService) CreateRecurringMessage(ctx context.Context, recurring ports.NewRecurringMessage) (*models.RecurringMessage, error) {
+	if recurring.Timezone == "" {
+		recurring.Timezone = "UTC"
+	}
+
+	schedule, err := localDomain.ParseRecurringSchedule(recurring.Schedule, recurring.Timezone)
+	if err != nil {
+		return nil, &ports.InvalidRecurringMessageError{Reason: err.Error()}
+	}
+
+	now := s.now()
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return nil, &ports.InvalidRecurringMessageError{Reason: "schedule has no future occurrences"}
+	}
+
+	// Render the first occurrence so template errors surface now rather
+	// than when the job runs.
+	content, err := localDomain.RenderContent(recurring.Content, localDomain.Occurrence{Time: next, To: recurring.To})
+	if err != nil {
+		return nil, &ports.InvalidRecurringMessageError{Reason: err.Error()}
+	}
+	if len(content) > maxContentLength {
+		return nil, &ports.InvalidRecurringMessageError{Reason: fmt.Sprintf("rendered content must not exceed %d characters", maxContentLength)}
+	}
+
+	definition := &models.RecurringMessage{
+		Name:      recurring.Name,
+		Schedule:  recurring.Schedule,
+		Timezone:  recurring.Timezone,
+		Content:   recurring.Content,
+		To:        recurring.To,
+		NextRunAt: next,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repository.Create(ctx, definition); err != nil {
+		return nil, fmt.Errorf("failed to create recurring message: %v", err)
+	}
+	return definition, nil
+}
+
+func (s *RecurringService) ListRecurringMessages(ctx context.Context) ([]models.RecurringMessage, error) {
+	return s.repository.List(ctx)
+}
+
+func (s *RecurringService) PauseRecurringMessage(ctx context.Context, id primitive.ObjectID) error {
+	recurring, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.setPaused(ctx, id, true, recurring.NextRunAt)
+}
+
+// ResumeRecurringMessage reactivates a definition from its next occurrence
+// after now; occurrences missed while it was paused are not sent.
+func (s *RecurringService) ResumeRecurringMessage(ctx context.Context, id primitive.ObjectID) error {
+	recurring, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := localDomain.ParseRecurringSchedule(recurring.Schedule, recurring.Timezone)
+	if err != nil {
+		return &ports.InvalidRecurringMessageError{Reason: err.Error()}
+	}
+	return s.setPaused(ctx, id, false, schedule.Next(s.now()))
+}
+
+func (s *RecurringService) DeleteRecurringMessage(ctx context.Context, id primitive.ObjectID) error {
+	found, err := s.repository.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurring message: %v", err)
+	}
+	if !found {
+		return ports.ErrRecurringMessageNotFound
+	}
+	return nil
+}
+
+func (s *RecurringService) PreviewRecurringMessage(ctx context.Context, id primitive.ObjectID, count int) ([]time.Time, error) {
+	recurring, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := localDomain.ParseRecurringSchedule(recurring.Schedule, recurring.Timezone)
+	if err != nil {
+		return nil, &ports.InvalidRecurringMessageError{Reason: err.Error()}
+	}
+	return schedule.NextN(s.now(), count), nil
+}
+
+func (s *RecurringService) get(ctx context.Context, id primitive.ObjectID) (*models.RecurringMessage, error) {
+	recurring, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring message: %v", err)
+	}
+	if recurring == nil {
+		return nil, ports.ErrRecurringMessageNotFound
+	}
+	return recurring, nil
+}
+
+func (s *RecurringService) setPaused(ctx context.Context, id primitive.ObjectID, paused bool, nextRunAt time.Time) error {
+	found, err := s.repository.SetPaused(ctx, id, paused, nextRunAt)
+	if err != nil {
+		return fmt.Errorf("failed to update recurring message: %v", err)
+	}
+	if !found {
+		return ports.ErrRecurringMessageNotFound
+	}
+	return nil
+}
+
+func (s *RecurringService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+}
+
+func (s *RecurringService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+}
+
+func (s *RecurringService) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(recurringCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.runDue(ctx); err != nil {
+				log.Printf("Error creating recurring messages: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runDue creates a message for every due definition and returns how many
+// were created.
+func (s *RecurringService) runDue(ctx context.Context) (int, error) {
+	if err := s.leadership.Fence(ctx); err != nil {
+		if errors.Is(err, leader.ErrNotLeader) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	now := s.now()
+	due, err := s.repository.FindDue(ctx, now, recurringBatchLimit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due recurring messages: %v", err)
+	}
+
+	created := 0
+	for i := range due {
+		ok, err := s.createOccurrence(ctx, &due[i], now)
+		if err != nil {
+			log.Printf("Failed to create message for recurring message %s: %v", due[i].ID.Hex(), err)
+			continue
+		}
+		if ok {
+			created++
+		}
+	}
+	if created > 0 {
+		log.Printf("Created %d messages from recurring definitions", created)
+	}
+	return created, nil
+}
+
+// createOccurrence creates the message for a definition's due occurrence and
+// moves it to its first occurrence after now, so occurrences missed while
+// the job was not running are collapsed into one message.
+func (s *RecurringService) createOccurrence(ctx context.Context, recurring *models.RecurringMessage, now time.Time) (bool, error) {
+	schedule, err := localDomain.ParseRecurringSchedule(recurring.Schedule, recurring.Timezone)
+	if err != nil {
+		return false, err
+	}
+
+	occurrence := recurring.NextRunAt
+	content, err := localDomain.RenderContent(recurring.Content, localDomain.Occurrence{
+		Time: occurrence.In(schedule.Location()),
+		To:   recurring.To,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	msg := s.sender.PrepareMessage(content, recurring.To)
+	recorded, err := s.repository.RecordOccurrence(ctx, recurring.ID, occurrence, schedule.Next(now), msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to record occurrence: %v", err)
+	}
+	return recorded, nil
+}
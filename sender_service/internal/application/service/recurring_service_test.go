@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+)
+
+type MockRecurringMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecurringMessageRepository) Create(ctx context.Context, recurring *models.RecurringMessage) error {
+	args := m.Called(ctx, recurring)
+	return args.Error(0)
+}
+
+func (m *MockRecurringMessageRepository) List(ctx context.Context) ([]models.RecurringMessage, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.RecurringMessage), args.Error(1)
+}
+
+func (m *MockRecurringMessageRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.RecurringMessage, error) {
+	args := m.Called(ctx, id)
+	if recurring, ok := args.Get(0).(*models.RecurringMessage); ok {
+		return recurring, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRecurringMessageRepository) SetPaused(ctx context.Context, id primitive.ObjectID, paused bool, nextRunAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, paused, nextRunAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecurringMessageRepository) Delete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecurringMessageRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]models.RecurringMessage, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]models.RecurringMessage), args.Error(1)
+}
+
+func (m *MockRecurringMessageRepository) RecordOccurrence(ctx context.Context, id primitive.ObjectID, occurrence time.Time, next time.Time, msg *models.Message) (bool, error) {
+	args := m.Called(ctx, id, occurrence, next, msg)
+	return args.Bool(0), args.Error(1)
+}
+
+// Monday 2024-05-06 08:00 UTC.
+var recurringNow = time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+
+func newTestRecurringService(repo *MockRecurringMessageRepository) *RecurringService {
+	service := NewRecurringService(domain.NewMessageSender(5, 10*time.Second), repo)
+	service.now = func() time.Time { return recurringNow }
+	return service
+}
+
+func TestRecurringService_CreateRecurringMessage(t *testing.T) {
+	repo := new(MockRecurringMessageRepository)
+	service := newTestRecurringService(repo)
+	ctx := context.Background()
+
+	repo.On("Create", ctx, mock.MatchedBy(func(r *models.RecurringMessage) bool {
+		return r.Timezone == "UTC" && r.NextRunAt.Equal(time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)) && !r.Paused
+	})).Return(nil)
+
+	recurring, err := service.CreateRecurringMessage(ctx, ports.NewRecurringMessage{
+		Name:     "standup",
+		Schedule: "0 9 * * 1-5",
+		Content:  `Standup on {{.Time.Format "Monday"}}`,
+		To:       "+905321234567",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "standup", recurring.Name)
+	repo.AssertExpectations(t)
+}
+
+func TestRecurringService_CreateRecurringMessage_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		recurring ports.NewRecurringMessage
+	}{
+		{name: "bad cron expression", recurring: ports.NewRecurringMessage{Schedule: "every day", Content: "hi"}},
+		{name: "bad timezone", recurring: ports.NewRecurringMessage{Schedule: "@daily", Timezone: "Nowhere/City", Content: "hi"}},
+		{name: "bad template", recurring: ports.NewRecurringMessage{Schedule: "@daily", Content: "{{.Nope}}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRecurringMessageRepository)
+			service := newTestRecurringService(repo)
+
+			_, err := service.CreateRecurringMessage(context.Background(), tt.recurring)
+
+			var invalid *ports.InvalidRecurringMessageError
+			assert.ErrorAs(t, err, &invalid)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRecurringService_PauseResumeDelete(t *testing.T) {
+	repo := new(MockRecurringMessageRepository)
+	service := newTestRecurringService(repo)
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+	missing := primitive.NewObjectID()
+	recurring := &models.RecurringMessage{ID: id, Schedule: "0 9 * * *", Timezone: "Europe/Istanbul", NextRunAt: recurringNow}
+
+	repo.On("GetByID", ctx, id).Return(recurring, nil)
+	repo.On("GetByID", ctx, missing).Return(nil, nil)
+	repo.On("SetPaused", ctx, id, true, recurringNow).Return(true, nil)
+	// 09:00 in Istanbul is 06:00 UTC, so the next run after 08:00 UTC is tomorrow.
+	repo.On("SetPaused", ctx, id, false, mock.MatchedBy(func(next time.Time) bool {
+		return next.Equal(time.Date(2024, 5, 7, 6, 0, 0, 0, time.UTC))
+	})).Return(true, nil)
+	repo.On("Delete", ctx, id).Return(true, nil)
+	repo.On("Delete", ctx, missing).Return(false, nil)
+
+	assert.NoError(t, service.PauseRecurringMessage(ctx, id))
+	assert.NoError(t, service.ResumeRecurringMessage(ctx, id))
+	assert.NoError(t, service.DeleteRecurringMessage(ctx, id))
+	assert.ErrorIs(t, service.PauseRecurringMessage(ctx, missing), ports.ErrRecurringMessageNotFound)
+	assert.ErrorIs(t, service.DeleteRecurringMessage(ctx, missing), ports.ErrRecurringMessageNotFound)
+	repo.AssertExpectations(t)
+}
+
+func TestRecurringService_PreviewRecurringMessage(t *testing.T) {
+	repo := new(MockRecurringMessageRepository)
+	service := newTestRecurringService(repo)
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+
+	repo.On("GetByID", ctx, id).Return(&models.RecurringMessage{ID: id, Schedule: "0 9 * * 1-5", Timezone: "UTC"}, nil)
+
+	occurrences, err := service.PreviewRecurringMessage(ctx, id, 3)
+
+	assert.NoError(t, err)
+	assert.Len(t, occurrences, 3)
+	assert.True(t, occurrences[0].Equal(time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, occurrences[2].Equal(time.Date(2024, 5, 8, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestRecurringService_RunDue(t *testing.T) {
+	repo := new(MockRecurringMessageRepository)
+	service := newTestRecurringService(repo)
+	ctx := context.Background()
+
+	// Due at 07:00 but the job only ran at 08:00; the next run is after now.
+	occurrence := time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC)
+	due := []models.RecurringMessage{
+		{ID: primitive.NewObjectID(), Schedule: "0 * * * *", Timezone: "UTC", Content: `Hourly {{.Time.Format "15:04"}}`, To: "+905321234567", NextRunAt: occurrence},
+		{ID: primitive.NewObjectID(), Schedule: "0 * * * *", Timezone: "UTC", Content: "already recorded", To: "+905321234567", NextRunAt: occurrence},
+	}
+	next := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	repo.On("FindDue", ctx, recurringNow, recurringBatchLimit).Return(due, nil)
+	repo.On("RecordOccurrence", ctx, due[0].ID, occurrence, next, mock.MatchedBy(func(msg *models.Message) bool {
+		return msg.Content == "Hourly 07:00" && msg.To == "+905321234567" && msg.Status == models.StatusUnsent
+	})).Return(true, nil)
+	repo.On("RecordOccurrence", ctx, due[1].ID, occurrence, next, mock.Anything).Return(false, nil)
+
+	created, err := service.runDue(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+	repo.AssertExpectations(t)
+}
+
+func TestRecurringService_RunDue_NotLeader(t *testing.T) {
+	repo := new(MockRecurringMessageRepository)
+	service := newTestRecurringService(repo).WithLeadership(stubLeadership{err: leader.ErrNotLeader})
+
+	created, err := service.runDue(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created)
+	repo.AssertNotCalled(t, "FindDue", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,11 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
 	localDomain "github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/correlation"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 	mongoPort "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
@@ -18,26 +24,42 @@ import (
 type SenderService struct {
 	sender     *localDomain.MessageSender
 	repository mongoPort.MessageRepository
+	outbox     mongoPort.OutboxRepository
 	queue      rabbitPort.MessageQueue
+	topology   contracts.Topology
 	scheduler  *MessageScheduler
+	leadership leader.Leadership
+	stateStore mongoPort.SchedulerStateStore
 }
 
 func NewSenderService(
 	sender *localDomain.MessageSender,
 	repository mongoPort.MessageRepository,
+	outbox mongoPort.OutboxRepository,
 	queue rabbitPort.MessageQueue,
 ) *SenderService {
 	service := &SenderService{
 		sender:     sender,
 		repository: repository,
+		outbox:     outbox,
 		queue:      queue,
+		topology:   contracts.DefaultTopology(),
+		leadership: leader.AlwaysLeader{Name: "sender-scheduler", Holder: sender.GetInstanceID()},
 	}
 	service.scheduler = NewMessageScheduler(service)
 	return service
 }
 
+// WithLeadership makes the scheduler publish only while this instance holds
+// the given leadership.
+func (s *SenderService) WithLeadership(leadership leader.Leadership) *SenderService {
+	s.leadership = leadership
+	return s
+}
+
 func (s *SenderService) CreateMessage(ctx context.Context, content string, to string) (primitive.ObjectID, error) {
 	msg := s.sender.PrepareMessage(content, to)
+	msg.CorrelationID = correlation.FromContext(ctx)
 	
 	if err := s.repository.CreateMessage(ctx, msg); err != nil {
 		return primitive.NilObjectID, fmt.Errorf("failed to create message: %v", err)
@@ -46,106 +68,616 @@ func (s *SenderService) CreateMessage(ctx context.Context, content string, to st
 	return msg.ID, nil
 }
 
+func (s *SenderService) BatchCreateMessages(ctx context.Context, messages []ports.NewMessage) ([]primitive.ObjectID, error) {
+	msgs := make([]*models.Message, 0, len(messages))
+	for _, m := range messages {
+		msg := s.sender.PrepareMessage(m.Content, m.To)
+		msg.CorrelationID = correlation.FromContext(ctx)
+		msgs = append(msgs, msg)
+	}
+
+	if err := s.repository.CreateMessages(ctx, msgs); err != nil {
+		return nil, fmt.Errorf("failed to create messages: %v", err)
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	return ids, nil
+}
+
+func (s *SenderService) GetMessage(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
+	return s.repository.GetByID(ctx, id)
+}
+
 func (s *SenderService) ListMessages(ctx context.Context) ([]models.Message, error) {
 	return s.repository.ListMessages(ctx)
 }
 
+// WithChangeStream makes the scheduler publish new messages from a MongoDB
+// change stream in addition to polling.
+func (s *SenderService) WithChangeStream(enabled bool) *SenderService {
+	s.scheduler.changeStream = enabled
+	return s
+}
+
+// WithAdaptiveBatching makes the scheduler size each batch from the backlog
+// of the messages and retry queues instead of using a fixed batch size.
+func (s *SenderService) WithAdaptiveBatching(sizer *localDomain.BatchSizer) *SenderService {
+	s.scheduler.sizer = sizer
+	return s
+}
+
+// WithPublisherPool publishes each batch on up to size workers, each with
+// its own channel.
+func (s *SenderService) WithPublisherPool(size int) *SenderService {
+	if size > 0 {
+		s.scheduler.publishers = size
+	}
+	return s
+}
+
+// WithTopology sets the queue names the scheduler inspects for adaptive
+// batching. It must match the topology the message queue was created with.
+func (s *SenderService) WithTopology(topology contracts.Topology) *SenderService {
+	s.topology = topology
+	return s
+}
+
+// WithSchedulerState persists the scheduler's running state and settings so
+// they survive restarts.
+func (s *SenderService) WithSchedulerState(store mongoPort.SchedulerStateStore) *SenderService {
+	s.stateStore = store
+	return s
+}
+
+// RestoreScheduler applies the persisted scheduler state and starts the
+// scheduler if it was running before the restart.
+func (s *SenderService) RestoreScheduler(ctx context.Context) error {
+	if s.stateStore == nil {
+		return nil
+	}
+
+	state, err := s.stateStore.LoadSchedulerState(ctx, schedulerStateName)
+	if err != nil {
+		return fmt.Errorf("failed to load scheduler state: %v", err)
+	}
+	if state == nil {
+		return nil
+	}
+
+	s.scheduler.Configure(ports.SchedulerSettings{
+		BatchSize: state.BatchSize,
+		Interval:  time.Duration(state.IntervalSeconds) * time.Second,
+	})
+	if state.Running {
+		s.scheduler.Start()
+	}
+	return nil
+}
+
 func (s *SenderService) StartScheduler(ctx context.Context) {
 	s.scheduler.Start()
+	if err := s.saveSchedulerState(ctx); err != nil {
+		log.Printf("Scheduler started but its state was not persisted: %v", err)
+	}
 }
 
 func (s *SenderService) StopScheduler(ctx context.Context) {
 	s.scheduler.Stop()
+	if err := s.saveSchedulerState(ctx); err != nil {
+		log.Printf("Scheduler stopped but its state was not persisted: %v", err)
+	}
+}
+
+// Close stops the scheduler without persisting the stop, so it starts again
+// after a restart if it was running.
+func (s *SenderService) Close() {
+	s.scheduler.Stop()
+}
+
+func (s *SenderService) SchedulerStatus(ctx context.Context) ports.SchedulerStatus {
+	return s.scheduler.Status()
+}
+
+func (s *SenderService) UpdateScheduler(ctx context.Context, settings ports.SchedulerSettings) (ports.SchedulerStatus, error) {
+	if settings.BatchSize < 0 || settings.Interval < 0 {
+		return ports.SchedulerStatus{}, fmt.Errorf("batch size and interval must not be negative")
+	}
+
+	s.scheduler.Configure(settings)
+	if err := s.saveSchedulerState(ctx); err != nil {
+		return s.scheduler.Status(), err
+	}
+	return s.scheduler.Status(), nil
+}
+
+func (s *SenderService) saveSchedulerState(ctx context.Context) error {
+	if s.stateStore == nil {
+		return nil
+	}
+
+	status := s.scheduler.Status()
+	err := s.stateStore.SaveSchedulerState(ctx, &models.SchedulerState{
+		Name:            schedulerStateName,
+		Running:         status.Running,
+		BatchSize:       status.BatchSize,
+		IntervalSeconds: int(status.Interval / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save scheduler state: %v", err)
+	}
+	return nil
+}
+
+const (
+	schedulerStateName     = "sender-scheduler"
+	changeStreamName       = "sender-outbox-events"
+	changeStreamRetryDelay = 10 * time.Second
+	changeStreamLeaderPoll = time.Second
+	maxReportedErrors      = 10
+)
+
+// eventHandler publishes one type of outbox event. commit applies the
+// effects of a batch of published events, such as status changes, in one
+// write.
+type eventHandler struct {
+	publish func(ctx context.Context, publisher rabbitPort.Publisher, event *models.OutboxEvent) error
+	commit  func(ctx context.Context, events []*models.OutboxEvent) error
 }
 
+// MessageScheduler relays outbox events. Each event type has a handler that
+// publishes it; types without a handler are left in the outbox untouched.
 type MessageScheduler struct {
-	service    *SenderService
-	done       chan bool
+	service      *SenderService
+	handlers     map[string]eventHandler
+	eventTypes   []string
+	changeStream bool
+	sizer        *localDomain.BatchSizer
+	publishers   int
+	reconfigured chan struct{}
+
+	// lifecycle serializes Start and Stop; mu guards the fields below it.
+	lifecycle  sync.Mutex
+	mu         sync.RWMutex
 	isRunning  bool
+	cancel     context.CancelFunc
+	done       chan struct{}
+	batchSize  int
+	interval   time.Duration
+	lastRunAt  time.Time
+	nextRunAt  time.Time
+	lastResult *ports.BatchResult
+	lastError  string
+	paused     bool
+	decision   string
+
+	// adaptiveBatchSize is the size adaptive batching last chose, starting
+	// from batchSize. It is never saved, so restarts begin at batchSize.
+	adaptiveBatchSize int
 }
 
 func NewMessageScheduler(service *SenderService) *MessageScheduler {
-	return &MessageScheduler{
-		service:   service,
-		done:      make(chan bool),
-		isRunning: false,
+	s := &MessageScheduler{
+		service:      service,
+		publishers:   1,
+		reconfigured: make(chan struct{}, 1),
+		isRunning:    false,
+		batchSize:    service.sender.GetBatchSize(),
+		interval:     service.sender.GetCheckInterval(),
 	}
+	s.handlers = map[string]eventHandler{
+		models.EventMessageCreated: {publish: s.publishMessageCreated, commit: s.commitMessagesCreated},
+	}
+	for eventType := range s.handlers {
+		s.eventTypes = append(s.eventTypes, eventType)
+	}
+	sort.Strings(s.eventTypes)
+	return s
 }
 
 func (s *MessageScheduler) Start() {
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.isRunning {
 		return
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
 	s.isRunning = true
-	go s.run()
+	s.nextRunAt = time.Now().Add(s.interval)
+	go s.run(ctx, s.done)
+	if s.changeStream {
+		go s.watch(ctx)
+	}
 }
 
+// Stop cancels the loops and waits for a batch in progress to finish.
 func (s *MessageScheduler) Stop() {
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+
+	s.mu.Lock()
 	if !s.isRunning {
+		s.mu.Unlock()
 		return
 	}
-
 	s.isRunning = false
-	s.done <- true
+	s.nextRunAt = time.Time{}
+	s.cancel()
+	done := s.done
+	s.mu.Unlock()
+
+	<-done
 }
 
-func (s *MessageScheduler) run() {
-	ticker := time.NewTicker(s.service.sender.GetCheckInterval())
-	defer ticker.Stop()
+func (s *MessageScheduler) Configure(settings ports.SchedulerSettings) {
+	s.mu.Lock()
+	if settings.BatchSize > 0 {
+		s.batchSize = settings.BatchSize
+		s.adaptiveBatchSize = 0
+	}
+	if settings.Interval > 0 {
+		s.interval = settings.Interval
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.reconfigured <- struct{}{}:
+	default:
+	}
+}
+
+func (s *MessageScheduler) Status() ports.SchedulerStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := ports.SchedulerStatus{
+		Running:    s.isRunning,
+		Leader:     s.service.leadership.IsLeader(),
+		BatchSize:  s.batchSize,
+		Interval:   s.interval,
+		LastResult: s.lastResult,
+		LastError:  s.lastError,
+		Paused:     s.paused,
+		Decision:   s.decision,
+	}
+	if s.sizer != nil {
+		status.AdaptiveBatchSize = s.currentBatchSize()
+	}
+	if !s.lastRunAt.IsZero() {
+		lastRunAt := s.lastRunAt
+		status.LastRunAt = &lastRunAt
+	}
+	if !s.nextRunAt.IsZero() {
+		nextRunAt := s.nextRunAt
+		status.NextRunAt = &nextRunAt
+	}
+	return status
+}
+
+func (s *MessageScheduler) settings() (int, time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.batchSize, s.interval
+}
+
+func (s *MessageScheduler) isPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paused
+}
+
+// currentBatchSize is the adaptive batch size, or the configured one before
+// adaptive batching has chosen a size. The caller holds mu.
+func (s *MessageScheduler) currentBatchSize() int {
+	if s.adaptiveBatchSize <= 0 {
+		return s.batchSize
+	}
+	return s.adaptiveBatchSize
+}
+
+// adaptBatchSize picks the next batch size from the backlog of the messages
+// and retry queues, starting from the configured batch size and bounded only
+// by the sizer. If the queues cannot be inspected the current size is used
+// and publishing is not paused.
+func (s *MessageScheduler) adaptBatchSize() (int, bool) {
+	s.mu.RLock()
+	configured := s.batchSize
+	batchSize := s.currentBatchSize()
+	s.mu.RUnlock()
+	if s.sizer == nil {
+		return configured, false
+	}
 
+	main, err := s.service.queue.GetQueueStats(s.service.topology.MainQueue())
+	if err == nil {
+		var retry int
+		retry, err = s.retryBacklog()
+		main.Messages += retry
+	}
+	if err != nil {
+		log.Printf("Failed to inspect queues, keeping batch size %d: %v", batchSize, err)
+		s.mu.Lock()
+		s.paused = false
+		s.decision = "queue inspection failed"
+		s.mu.Unlock()
+		return batchSize, false
+	}
+
+	decision := s.sizer.Next(batchSize, main.Messages, main.Consumers)
+	log.Printf("Adaptive batch size %d -> %d, paused=%t: %s (backlog=%d, consumers=%d)",
+		batchSize, decision.Size, decision.Paused, decision.Reason, main.Messages, main.Consumers)
+
+	s.mu.Lock()
+	s.adaptiveBatchSize = decision.Size
+	s.paused = decision.Paused
+	s.decision = decision.Reason
+	s.mu.Unlock()
+	return decision.Size, decision.Paused
+}
+
+// retryBacklog counts the messages waiting in the retry queue and in the
+// queue of every backoff tier.
+func (s *MessageScheduler) retryBacklog() (int, error) {
+	topology := s.service.topology
+	queues := []string{topology.RetryQueue()}
+	for tier := range topology.RetryBackoff {
+		queues = append(queues, topology.RetryTierQueue(tier))
+	}
+
+	backlog := 0
+	for _, queue := range queues {
+		stats, err := s.service.queue.GetQueueStats(queue)
+		if err != nil {
+			return 0, err
+		}
+		backlog += stats.Messages
+	}
+	return backlog, nil
+}
+
+func (s *MessageScheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	_, interval := s.settings()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			s.runOnce()
+		case <-s.reconfigured:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		_, interval = s.settings()
+		timer.Reset(interval)
+		s.mu.Lock()
+		s.nextRunAt = time.Now().Add(interval)
+		s.mu.Unlock()
+	}
+}
+
+func (s *MessageScheduler) runOnce() {
+	startedAt := time.Now()
+	result, err := s.processOutbox()
+	if err != nil {
+		log.Printf("Error processing outbox: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRunAt = startedAt
+	s.lastResult = &result
+	s.lastError = ""
+	if err != nil {
+		s.lastError = err.Error()
+	}
+}
+
+// watch relays outbox events as soon as they are inserted. The polling loop in
+// run stays active as a safety net for events missed while the stream is down.
+// The stream is only open while this instance is the leader, so followers
+// never move the shared resume token past events they did not publish.
+func (s *MessageScheduler) watch(ctx context.Context) {
+	for {
+		if !s.service.leadership.IsLeader() {
+			select {
+			case <-time.After(changeStreamLeaderPoll):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		go s.cancelOnLostLeadership(watchCtx, cancel)
+		err := s.service.outbox.WatchInserts(watchCtx, changeStreamName, s.handleInsert)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, leader.ErrNotLeader) || !s.service.leadership.IsLeader() {
+			log.Println("Change stream closed, this instance is no longer the leader")
+			continue
+		}
+
+		log.Printf("Change stream stopped: %v; reconnecting in %s", err, changeStreamRetryDelay)
+		select {
+		case <-time.After(changeStreamRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// cancelOnLostLeadership closes the change stream once leadership is lost.
+func (s *MessageScheduler) cancelOnLostLeadership(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(changeStreamLeaderPoll)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
-			if err := s.processUnsentMessages(); err != nil {
-				log.Printf("Error processing unsent messages: %v", err)
+			if !s.service.leadership.IsLeader() {
+				cancel()
+				return
 			}
-		case <-s.done:
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (s *MessageScheduler) processUnsentMessages() error {
+func (s *MessageScheduler) handleInsert(ctx context.Context, id primitive.ObjectID) error {
+	if !s.service.leadership.IsLeader() {
+		// Returning an error stops the stream before the resume token moves
+		// past this event, so the next leader still relays it.
+		return leader.ErrNotLeader
+	}
+	if s.isPaused() {
+		// Backpressure: the event stays in the outbox for a later poll.
+		return nil
+	}
+
+	sender := s.service.sender
+	event, err := s.service.outbox.ClaimEvent(ctx, id, s.eventTypes, sender.GetInstanceID(), sender.GetClaimLease())
+	if err != nil {
+		log.Printf("Failed to claim inserted outbox event %s, leaving it for polling: %v", id.Hex(), err)
+		return nil
+	}
+	if event == nil {
+		return nil
+	}
+
+	if err := s.handleEvent(ctx, event); err != nil {
+		log.Printf("Failed to handle inserted outbox event %s: %v", id.Hex(), err)
+	}
+	return nil
+}
+
+func (s *MessageScheduler) processOutbox() (ports.BatchResult, error) {
 	ctx := context.Background()
+	var result ports.BatchResult
+
+	if err := s.service.leadership.Fence(ctx); err != nil {
+		if errors.Is(err, leader.ErrNotLeader) {
+			log.Println("Skipping outbox check, this instance is not the leader")
+			return result, nil
+		}
+		return result, err
+	}
 	
-	log.Println("Checking for unsent messages...")
-	messages, err := s.service.repository.FindUnsentMessages(ctx, s.service.sender.GetBatchSize())
+	batchSize, paused := s.adaptBatchSize()
+	if paused {
+		log.Println("Skipping outbox check, publishing is paused until the queues drain")
+		return result, nil
+	}
+
+	log.Println("Checking for pending outbox events...")
+	sender := s.service.sender
+	events, err := s.service.outbox.ClaimEvents(ctx, s.eventTypes, sender.GetInstanceID(), batchSize, sender.GetClaimLease())
 	if err != nil {
-		return fmt.Errorf("failed to find unsent messages: %v", err)
+		return result, fmt.Errorf("failed to claim outbox events: %v", err)
 	}
 
-	log.Printf("Found %d unsent messages", len(messages))
+	log.Printf("Claimed %d outbox events as %s", len(events), sender.GetInstanceID())
+	result.Claimed = len(events)
 
-	for _, msg := range messages {
-		if err := s.handleMessage(ctx, &msg); err != nil {
-			log.Printf("Failed to handle message %s: %v", msg.ID.Hex(), err)
-			continue
+	published := s.publishBatch(ctx, events, &result)
+	s.commitBatch(ctx, published, &result)
+
+	return result, nil
+}
+
+// handleEvent publishes a claimed event and deletes it from the outbox. If
+// publishing fails the claim is released so the event is retried later.
+func (s *MessageScheduler) handleEvent(ctx context.Context, event *models.OutboxEvent) error {
+	owner := s.service.sender.GetInstanceID()
+	handler := s.handlers[event.Type]
+
+	err := handler.publish(ctx, s.service.queue, event)
+	if err == nil {
+		err = handler.commit(ctx, []*models.OutboxEvent{event})
+	}
+	if err != nil {
+		s.releaseEvent(ctx, event, err)
+		return err
+	}
+
+	if err := s.service.outbox.CompleteEvent(ctx, event.ID, owner); err != nil {
+		if errors.Is(err, mongoPort.ErrClaimNotHeld) {
+			log.Printf("Claim on outbox event %s expired before it was completed; another instance may publish it again", event.ID.Hex())
 		}
+		return fmt.Errorf("failed to complete outbox event: %v", err)
 	}
 
 	return nil
 }
 
-func (s *MessageScheduler) handleMessage(ctx context.Context, msg *models.Message) error {
+func (s *MessageScheduler) releaseEvent(ctx context.Context, event *models.OutboxEvent, cause error) {
+	owner := s.service.sender.GetInstanceID()
+	if err := s.service.outbox.ReleaseEvent(ctx, event.ID, owner, cause.Error()); err != nil {
+		log.Printf("Failed to release claim on outbox event %s: %v", event.ID.Hex(), err)
+	}
+}
+
+func (s *MessageScheduler) publishMessageCreated(ctx context.Context, publisher rabbitPort.Publisher, event *models.OutboxEvent) error {
+	var payload models.MessageCreatedPayload
+	if err := event.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("failed to decode outbox payload: %v", err)
+	}
+
 	queueMsg := contracts.QueueMessage{
-		ID:      msg.ID.Hex(),
-		Content: msg.Content,
-		To:      msg.To,
-		Retry:   msg.RetryCount,
+		ID:            event.AggregateID.Hex(),
+		Content:       payload.Content,
+		To:            payload.To,
+		Retry:         payload.RetryCount,
+		CorrelationID: payload.CorrelationID,
 	}
 
-	log.Printf("Attempting to publish message %s to queue", msg.ID.Hex())
-	if err := s.service.queue.PublishMessage(ctx, queueMsg); err != nil {
+	log.Printf("Attempting to publish message %s to queue", queueMsg.ID)
+	if err := publisher.PublishMessage(ctx, queueMsg); err != nil {
+		var publishErr *rabbitPort.PublishError
+		if errors.As(err, &publishErr) {
+			log.Printf("Broker did not accept message %s, leaving it unsent: %v", queueMsg.ID, publishErr)
+		}
 		return fmt.Errorf("failed to publish message: %v", err)
 	}
-	log.Printf("Successfully published message %s to queue", msg.ID.Hex())
+	log.Printf("Successfully published message %s to queue", queueMsg.ID)
+
+	return nil
+}
+
+func (s *MessageScheduler) commitMessagesCreated(ctx context.Context, events []*models.OutboxEvent) error {
+	ids := make([]primitive.ObjectID, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.AggregateID)
+	}
 
-	log.Printf("Updating status to processing for message %s", msg.ID.Hex())
-	if err := s.service.repository.UpdateStatus(ctx, msg.ID, models.StatusProcessing); err != nil {
+	// Only move unsent messages forward; the processor may already have
+	// recorded a later status.
+	updated, err := s.service.repository.TransitionStatuses(ctx, ids, models.StatusUnsent, models.StatusProcessing)
+	if err != nil {
 		return fmt.Errorf("failed to update message status: %v", err)
 	}
-	log.Printf("Successfully updated status to processing for message %s", msg.ID.Hex())
+	if updated < len(ids) {
+		log.Printf("%d of %d published messages had already moved past %s", len(ids)-updated, len(ids), models.StatusUnsent)
+	}
 
 	return nil
-} 
\ No newline at end of file
+}
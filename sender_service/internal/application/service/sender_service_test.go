@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -9,13 +11,31 @@ import (
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/domain"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/leader"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
 	rabbitInterfaces "github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
 )
 
+type stubLeadership struct {
+	err error
+}
+
+func (s stubLeadership) IsLeader() bool {
+	return s.err == nil
+}
+
+func (s stubLeadership) Fence(ctx context.Context) error {
+	return s.err
+}
+
+func (s stubLeadership) Status() leader.Status {
+	return leader.Status{Name: "sender-scheduler", Holder: "sender-1", IsLeader: s.err == nil}
+}
+
 type MockMessageRepository struct {
 	mock.Mock
 	interfaces.MessageRepository
@@ -26,6 +46,11 @@ func (m *MockMessageRepository) CreateMessage(ctx context.Context, message *mode
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) CreateMessages(ctx context.Context, messages []*models.Message) error {
+	args := m.Called(ctx, messages)
+	return args.Error(0)
+}
+
 func (m *MockMessageRepository) ListMessages(ctx context.Context) ([]models.Message, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]models.Message), args.Error(1)
@@ -41,6 +66,16 @@ func (m *MockMessageRepository) UpdateStatus(ctx context.Context, id primitive.O
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (bool, error) {
+	args := m.Called(ctx, id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) TransitionStatuses(ctx context.Context, ids []primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (int, error) {
+	args := m.Called(ctx, ids, from, to)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockMessageRepository) FindStaleProcessingMessages(ctx context.Context, duration time.Duration) ([]models.Message, error) {
 	args := m.Called(ctx, duration)
 	return args.Get(0).([]models.Message), args.Error(1)
@@ -59,6 +94,64 @@ func (m *MockMessageRepository) IncrementRetryCount(ctx context.Context, id prim
 	return args.Error(0)
 }
 
+type MockOutboxRepository struct {
+	mock.Mock
+	interfaces.OutboxRepository
+}
+
+func (m *MockOutboxRepository) ClaimEvents(ctx context.Context, types []string, owner string, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	args := m.Called(ctx, types, owner, limit, lease)
+	if events, ok := args.Get(0).([]models.OutboxEvent); ok {
+		return events, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOutboxRepository) ClaimEvent(ctx context.Context, id primitive.ObjectID, types []string, owner string, lease time.Duration) (*models.OutboxEvent, error) {
+	args := m.Called(ctx, id, types, owner, lease)
+	if event, ok := args.Get(0).(*models.OutboxEvent); ok {
+		return event, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOutboxRepository) CompleteEvent(ctx context.Context, id primitive.ObjectID, owner string) error {
+	args := m.Called(ctx, id, owner)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) CompleteEvents(ctx context.Context, ids []primitive.ObjectID, owner string) (int, error) {
+	args := m.Called(ctx, ids, owner)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxRepository) ReleaseEvent(ctx context.Context, id primitive.ObjectID, owner string, lastErr string) error {
+	args := m.Called(ctx, id, owner, lastErr)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) WatchInserts(ctx context.Context, stream string, handle func(ctx context.Context, id primitive.ObjectID) error) error {
+	args := m.Called(ctx, stream, handle)
+	return args.Error(0)
+}
+
+type MockSchedulerStateStore struct {
+	mock.Mock
+}
+
+func (m *MockSchedulerStateStore) LoadSchedulerState(ctx context.Context, name string) (*models.SchedulerState, error) {
+	args := m.Called(ctx, name)
+	if state, ok := args.Get(0).(*models.SchedulerState); ok {
+		return state, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockSchedulerStateStore) SaveSchedulerState(ctx context.Context, state *models.SchedulerState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
 type MockMessageQueue struct {
 	mock.Mock
 	rabbitInterfaces.MessageQueue
@@ -74,6 +167,19 @@ func (m *MockMessageQueue) GetDLQMessageCount() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockMessageQueue) GetQueueStats(queueName string) (rabbitInterfaces.QueueStats, error) {
+	args := m.Called(queueName)
+	return args.Get(0).(rabbitInterfaces.QueueStats), args.Error(1)
+}
+
+func (m *MockMessageQueue) NewPublisher() (rabbitInterfaces.Publisher, error) {
+	args := m.Called()
+	if publisher, ok := args.Get(0).(rabbitInterfaces.Publisher); ok {
+		return publisher, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockMessageQueue) Close() {
 	m.Called()
 }
@@ -82,7 +188,7 @@ func TestSenderService_CreateMessage(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
 	mockQueue := new(MockMessageQueue)
 	sender := domain.NewMessageSender(5, 10*time.Second)
-	service := NewSenderService(sender, mockRepo, mockQueue)
+	service := NewSenderService(sender, mockRepo, new(MockOutboxRepository), mockQueue)
 
 	ctx := context.Background()
 	content := "test content"
@@ -107,11 +213,74 @@ func TestSenderService_CreateMessage(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestSenderService_BatchCreateMessages(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second)
+	service := NewSenderService(sender, mockRepo, new(MockOutboxRepository), mockQueue)
+
+	ctx := context.Background()
+	input := []ports.NewMessage{
+		{Content: "first", To: "+905321234567"},
+		{Content: "second", To: "+905321234568"},
+	}
+
+	mockRepo.On("CreateMessages", ctx, mock.MatchedBy(func(msgs []*models.Message) bool {
+		return len(msgs) == 2 &&
+			msgs[0].Content == "first" && msgs[0].Status == models.StatusUnsent &&
+			msgs[1].Content == "second" && msgs[1].To == "+905321234568"
+	})).Run(func(args mock.Arguments) {
+		for _, msg := range args.Get(1).([]*models.Message) {
+			msg.ID = primitive.NewObjectID()
+		}
+	}).Return(nil)
+
+	ids, err := service.BatchCreateMessages(ctx, input)
+
+	assert.NoError(t, err)
+	assert.Len(t, ids, 2)
+	assert.NotEqual(t, ids[0], ids[1])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSenderService_BatchCreateMessages_Error(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second)
+	service := NewSenderService(sender, mockRepo, new(MockOutboxRepository), mockQueue)
+
+	ctx := context.Background()
+	mockRepo.On("CreateMessages", ctx, mock.Anything).Return(assert.AnError)
+
+	ids, err := service.BatchCreateMessages(ctx, []ports.NewMessage{{Content: "first", To: "+905321234567"}})
+
+	assert.Error(t, err)
+	assert.Nil(t, ids)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSenderService_GetMessage(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second)
+	service := NewSenderService(sender, mockRepo, new(MockOutboxRepository), mockQueue)
+
+	ctx := context.Background()
+	expected := &models.Message{ID: primitive.NewObjectID(), Content: "test"}
+	mockRepo.On("GetByID", ctx, expected.ID).Return(expected, nil)
+
+	msg, err := service.GetMessage(ctx, expected.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, msg)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestSenderService_ListMessages(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
 	mockQueue := new(MockMessageQueue)
 	sender := domain.NewMessageSender(5, 10*time.Second)
-	service := NewSenderService(sender, mockRepo, mockQueue)
+	service := NewSenderService(sender, mockRepo, new(MockOutboxRepository), mockQueue)
 
 	ctx := context.Background()
 	expectedMessages := []models.Message{
@@ -148,48 +317,585 @@ func TestSenderService_Scheduler(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
 	mockQueue := new(MockMessageQueue)
 	sender := domain.NewMessageSender(5, 10*time.Second)
-	service := NewSenderService(sender, mockRepo, mockQueue)
+	service := NewSenderService(sender, mockRepo, new(MockOutboxRepository), mockQueue)
 
 	ctx := context.Background()
 
 	service.StartScheduler(ctx)
-	assert.True(t, service.scheduler.isRunning)
+	status := service.SchedulerStatus(ctx)
+	assert.True(t, status.Running)
+	assert.NotNil(t, status.NextRunAt)
 
 	service.StopScheduler(ctx)
-	assert.False(t, service.scheduler.isRunning)
+	status = service.SchedulerStatus(ctx)
+	assert.False(t, status.Running)
+	assert.Nil(t, status.NextRunAt)
+}
+
+func TestSenderService_UpdateScheduler(t *testing.T) {
+	sender := domain.NewMessageSender(5, 10*time.Second)
+	store := new(MockSchedulerStateStore)
+	service := NewSenderService(sender, new(MockMessageRepository), new(MockOutboxRepository), new(MockMessageQueue)).WithSchedulerState(store)
+
+	ctx := context.Background()
+	store.On("SaveSchedulerState", ctx, mock.MatchedBy(func(state *models.SchedulerState) bool {
+		return state.Name == "sender-scheduler" && !state.Running && state.BatchSize == 20 && state.IntervalSeconds == 10
+	})).Return(nil)
+
+	status, err := service.UpdateScheduler(ctx, ports.SchedulerSettings{BatchSize: 20})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 20, status.BatchSize)
+	assert.Equal(t, 10*time.Second, status.Interval)
+	store.AssertExpectations(t)
+
+	_, err = service.UpdateScheduler(ctx, ports.SchedulerSettings{Interval: -time.Second})
+	assert.Error(t, err)
+}
+
+func TestSenderService_RestoreScheduler(t *testing.T) {
+	sender := domain.NewMessageSender(5, 10*time.Second)
+	store := new(MockSchedulerStateStore)
+	service := NewSenderService(sender, new(MockMessageRepository), new(MockOutboxRepository), new(MockMessageQueue)).WithSchedulerState(store)
+
+	ctx := context.Background()
+	store.On("LoadSchedulerState", ctx, "sender-scheduler").Return(&models.SchedulerState{
+		Name:            "sender-scheduler",
+		Running:         true,
+		BatchSize:       50,
+		IntervalSeconds: 30,
+	}, nil)
+
+	assert.NoError(t, service.RestoreScheduler(ctx))
+
+	status := service.SchedulerStatus(ctx)
+	assert.True(t, status.Running)
+	assert.Equal(t, 50, status.BatchSize)
+	assert.Equal(t, 30*time.Second, status.Interval)
+
+	service.Close()
+	assert.False(t, service.SchedulerStatus(ctx).Running)
+	store.AssertExpectations(t)
+}
+
+func TestMessageScheduler_RunOnceRecordsResult(t *testing.T) {
+	mockOutbox := new(MockOutboxRepository)
+	sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, new(MockMessageRepository), mockOutbox, new(MockMessageQueue))
+
+	mockOutbox.On("ClaimEvents", mock.Anything, []string{models.EventMessageCreated}, "sender-1", 5, time.Minute).Return(nil, assert.AnError)
+
+	service.scheduler.runOnce()
+
+	status := service.scheduler.Status()
+	assert.NotNil(t, status.LastRunAt)
+	assert.NotNil(t, status.LastResult)
+	assert.Contains(t, status.LastError, assert.AnError.Error())
+	mockOutbox.AssertExpectations(t)
+}
+
+
+func newCreatedEvent(t *testing.T) *models.OutboxEvent {
+	event, err := models.NewMessageCreatedEvent(&models.Message{
+		ID:      primitive.NewObjectID(),
+		Content: "test1",
+		To:      "+905321234567",
+		Status:  models.StatusUnsent,
+	})
+	assert.NoError(t, err)
+	return event
+}
+
+func TestSenderService_SchedulerWithChangeStream(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second)
+	service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue).WithChangeStream(true)
+
+	watching := make(chan struct{})
+	mockOutbox.On("WatchInserts", mock.Anything, "sender-outbox-events", mock.Anything).Run(func(args mock.Arguments) {
+		close(watching)
+		<-args.Get(0).(context.Context).Done()
+	}).Return(context.Canceled).Once()
+
+	service.StartScheduler(context.Background())
+	<-watching
+	service.StopScheduler(context.Background())
+
+	mockOutbox.AssertExpectations(t)
+}
+
+// switchLeadership is a Leadership whose state a test can change while the
+// scheduler runs.
+type switchLeadership struct {
+	leader atomic.Bool
+}
+
+func (s *switchLeadership) IsLeader() bool {
+	return s.leader.Load()
+}
+
+func (s *switchLeadership) Fence(ctx context.Context) error {
+	if !s.leader.Load() {
+		return leader.ErrNotLeader
+	}
+	return nil
+}
+
+func (s *switchLeadership) Status() leader.Status {
+	return leader.Status{Name: "sender-scheduler", Holder: "sender-1", IsLeader: s.leader.Load()}
 }
 
-func TestMessageScheduler_ProcessUnsentMessages(t *testing.T) {
+func TestSenderService_ChangeStreamOnlyWhileLeader(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
+	mockOutbox := new(MockOutboxRepository)
 	mockQueue := new(MockMessageQueue)
+	leadership := &switchLeadership{}
 	sender := domain.NewMessageSender(5, 10*time.Second)
-	service := NewSenderService(sender, mockRepo, mockQueue)
+	service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue).WithChangeStream(true).WithLeadership(leadership)
+
+	watching := make(chan struct{})
+	closed := make(chan struct{})
+	mockOutbox.On("WatchInserts", mock.Anything, "sender-outbox-events", mock.Anything).Run(func(args mock.Arguments) {
+		close(watching)
+		<-args.Get(0).(context.Context).Done()
+		close(closed)
+	}).Return(context.Canceled).Once()
+
+	service.StartScheduler(context.Background())
+	defer service.StopScheduler(context.Background())
+
+	select {
+	case <-watching:
+		t.Fatal("a follower opened the change stream")
+	case <-time.After(2 * changeStreamLeaderPoll):
+	}
 
+	leadership.leader.Store(true)
+	select {
+	case <-watching:
+	case <-time.After(3 * changeStreamLeaderPoll):
+		t.Fatal("the leader did not open the change stream")
+	}
+
+	leadership.leader.Store(false)
+	select {
+	case <-closed:
+	case <-time.After(3 * changeStreamLeaderPoll):
+		t.Fatal("the change stream stayed open after leadership was lost")
+	}
+}
+
+func TestMessageScheduler_HandleInsert(t *testing.T) {
 	ctx := context.Background()
-	unsentMessages := []models.Message{
+	event := newCreatedEvent(t)
+	types := []string{models.EventMessageCreated}
+
+	tests := []struct {
+		name       string
+		leadership stubLeadership
+		setupMocks func(*MockMessageRepository, *MockOutboxRepository, *MockMessageQueue)
+		wantErr    error
+	}{
 		{
-			ID:         primitive.NewObjectID(),
-			Content:    "test1",
-			To:         "+905321234569",
-			Status:     models.StatusUnsent,
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
-			RetryCount: 0,
+			name: "claims and publishes the inserted event",
+			setupMocks: func(repo *MockMessageRepository, outbox *MockOutboxRepository, queue *MockMessageQueue) {
+				outbox.On("ClaimEvent", ctx, event.ID, types, "sender-1", time.Minute).Return(event, nil)
+				queue.On("PublishMessage", ctx, mock.MatchedBy(func(m contracts.QueueMessage) bool {
+					return m.ID == event.AggregateID.Hex()
+				})).Return(nil)
+				repo.On("TransitionStatuses", ctx, []primitive.ObjectID{event.AggregateID}, models.StatusUnsent, models.StatusProcessing).Return(1, nil)
+				outbox.On("CompleteEvent", ctx, event.ID, "sender-1").Return(nil)
+			},
+		},
+		{
+			name: "event already claimed by another instance",
+			setupMocks: func(repo *MockMessageRepository, outbox *MockOutboxRepository, queue *MockMessageQueue) {
+				outbox.On("ClaimEvent", ctx, event.ID, types, "sender-1", time.Minute).Return(nil, nil)
+			},
+		},
+		{
+			name: "claim error is left for polling",
+			setupMocks: func(repo *MockMessageRepository, outbox *MockOutboxRepository, queue *MockMessageQueue) {
+				outbox.On("ClaimEvent", ctx, event.ID, types, "sender-1", time.Minute).Return(nil, assert.AnError)
+			},
+		},
+		{
+			name:       "not the leader",
+			leadership: stubLeadership{err: leader.ErrNotLeader},
+			wantErr:    leader.ErrNotLeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			mockOutbox := new(MockOutboxRepository)
+			mockQueue := new(MockMessageQueue)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockRepo, mockOutbox, mockQueue)
+			}
+			sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+			service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue).WithLeadership(tt.leadership)
+
+			err := service.scheduler.handleInsert(ctx, event.ID)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertExpectations(t)
+			mockOutbox.AssertExpectations(t)
+			mockQueue.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageScheduler_ProcessOutbox(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue)
+
+	ctx := context.Background()
+	event := newCreatedEvent(t)
+
+	mockOutbox.On("ClaimEvents", ctx, []string{models.EventMessageCreated}, "sender-1", 5, time.Minute).Return([]models.OutboxEvent{*event}, nil)
+	mockQueue.On("PublishMessage", ctx, contracts.QueueMessage{
+		ID:      event.AggregateID.Hex(),
+		Content: "test1",
+		To:      "+905321234567",
+		Retry:   0,
+	}).Return(nil)
+	mockRepo.On("TransitionStatuses", ctx, []primitive.ObjectID{event.AggregateID}, models.StatusUnsent, models.StatusProcessing).Return(1, nil)
+	mockOutbox.On("CompleteEvents", ctx, []primitive.ObjectID{event.ID}, "sender-1").Return(1, nil)
+
+	result, err := service.scheduler.processOutbox()
+
+	assert.NoError(t, err)
+	assert.Equal(t, ports.BatchResult{Claimed: 1, Published: 1}, result)
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestMessageScheduler_ProcessOutbox_NotLeader(t *testing.T) {
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, new(MockMessageRepository), mockOutbox, mockQueue).WithLeadership(stubLeadership{err: leader.ErrNotLeader})
+
+	_, err := service.scheduler.processOutbox()
+
+	assert.NoError(t, err)
+	mockOutbox.AssertNotCalled(t, "ClaimEvents", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockQueue.AssertNotCalled(t, "PublishMessage", mock.Anything, mock.Anything)
+}
+
+// expectRetryTierStats reports stats for the queue of every default backoff
+// tier.
+func expectRetryTierStats(mockQueue *MockMessageQueue, stats rabbitInterfaces.QueueStats) {
+	topology := contracts.DefaultTopology()
+	for tier := range topology.RetryBackoff {
+		mockQueue.On("GetQueueStats", topology.RetryTierQueue(tier)).Return(stats, nil)
+	}
+}
+
+func TestMessageScheduler_ProcessOutbox_AdaptiveBatching(t *testing.T) {
+	types := []string{models.EventMessageCreated}
+
+	tests := []struct {
+		name         string
+		adaptive     int
+		mainStats    rabbitInterfaces.QueueStats
+		retryStats   rabbitInterfaces.QueueStats
+		tierStats    rabbitInterfaces.QueueStats
+		statsErr     error
+		wantClaim    int
+		wantAdaptive int
+		wantPaused   bool
+	}{
+		{
+			name:         "grows the batch when the queues are empty",
+			adaptive:     2,
+			mainStats:    rabbitInterfaces.QueueStats{Consumers: 2},
+			wantClaim:    4,
+			wantAdaptive: 4,
+		},
+		{
+			name:         "grows the batch past the configured size",
+			mainStats:    rabbitInterfaces.QueueStats{Consumers: 2},
+			wantClaim:    10,
+			wantAdaptive: 10,
 		},
+		{
+			name:         "does not grow the batch beyond the sizer's maximum",
+			adaptive:     80,
+			mainStats:    rabbitInterfaces.QueueStats{Consumers: 2},
+			wantClaim:    100,
+			wantAdaptive: 100,
+		},
+		{
+			name:         "shrinks the batch when the retry queue backs up",
+			mainStats:    rabbitInterfaces.QueueStats{Messages: 10, Consumers: 1},
+			retryStats:   rabbitInterfaces.QueueStats{Messages: 30},
+			wantClaim:    2,
+			wantAdaptive: 2,
+		},
+		{
+			name:         "shrinks the batch when the retry tiers back up",
+			mainStats:    rabbitInterfaces.QueueStats{Messages: 10, Consumers: 1},
+			tierStats:    rabbitInterfaces.QueueStats{Messages: 4},
+			wantClaim:    2,
+			wantAdaptive: 2,
+		},
+		{
+			name:         "pauses when the backlog is far above the limit",
+			mainStats:    rabbitInterfaces.QueueStats{Messages: 100, Consumers: 1},
+			wantAdaptive: 2,
+			wantPaused:   true,
+		},
+		{
+			name:         "keeps the batch size when the queues cannot be inspected",
+			adaptive:     3,
+			statsErr:     assert.AnError,
+			wantClaim:    3,
+			wantAdaptive: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockOutbox := new(MockOutboxRepository)
+			mockQueue := new(MockMessageQueue)
+			sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+			service := NewSenderService(sender, new(MockMessageRepository), mockOutbox, mockQueue).
+				WithAdaptiveBatching(domain.NewBatchSizer(1, 100, 20))
+			service.scheduler.adaptiveBatchSize = tt.adaptive
+
+			mockQueue.On("GetQueueStats", contracts.MainQueueName).Return(tt.mainStats, tt.statsErr)
+			if tt.statsErr == nil {
+				mockQueue.On("GetQueueStats", contracts.RetryQueueName).Return(tt.retryStats, nil)
+				expectRetryTierStats(mockQueue, tt.tierStats)
+			}
+			if !tt.wantPaused {
+				mockOutbox.On("ClaimEvents", mock.Anything, types, "sender-1", tt.wantClaim, time.Minute).Return([]models.OutboxEvent{}, nil)
+			}
+
+			_, err := service.scheduler.processOutbox()
+
+			assert.NoError(t, err)
+			status := service.scheduler.Status()
+			assert.Equal(t, tt.wantPaused, status.Paused)
+			assert.NotEmpty(t, status.Decision)
+			assert.Equal(t, 5, status.BatchSize)
+			assert.Equal(t, tt.wantAdaptive, status.AdaptiveBatchSize)
+			mockOutbox.AssertExpectations(t)
+			mockQueue.AssertExpectations(t)
+		})
 	}
+}
 
-	mockRepo.On("FindUnsentMessages", ctx, 5).Return(unsentMessages, nil)
-	mockQueue.On("PublishMessage", ctx, mock.MatchedBy(func(msg contracts.QueueMessage) bool {
-		return msg.ID == unsentMessages[0].ID.Hex() &&
-			msg.Content == unsentMessages[0].Content &&
-			msg.To == unsentMessages[0].To &&
-			msg.Retry == unsentMessages[0].RetryCount
+func TestMessageScheduler_AdaptiveBatchingKeepsConfiguredBatchSize(t *testing.T) {
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	mockStore := new(MockSchedulerStateStore)
+	sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, new(MockMessageRepository), mockOutbox, mockQueue).
+		WithAdaptiveBatching(domain.NewBatchSizer(1, 100, 20)).
+		WithSchedulerState(mockStore)
+
+	mockQueue.On("GetQueueStats", contracts.MainQueueName).Return(rabbitInterfaces.QueueStats{Messages: 30, Consumers: 1}, nil)
+	mockQueue.On("GetQueueStats", contracts.RetryQueueName).Return(rabbitInterfaces.QueueStats{}, nil)
+	expectRetryTierStats(mockQueue, rabbitInterfaces.QueueStats{})
+	mockOutbox.On("ClaimEvents", mock.Anything, mock.Anything, "sender-1", 2, time.Minute).Return([]models.OutboxEvent{}, nil)
+	mockStore.On("SaveSchedulerState", mock.Anything, mock.MatchedBy(func(state *models.SchedulerState) bool {
+		return state.BatchSize == 5
 	})).Return(nil)
-	mockRepo.On("UpdateStatus", ctx, unsentMessages[0].ID, models.StatusProcessing).Return(nil)
 
-	err := service.scheduler.processUnsentMessages()
+	_, err := service.scheduler.processOutbox()
+	assert.NoError(t, err)
+	service.StopScheduler(context.Background())
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestMessageScheduler_HandleInsert_Paused(t *testing.T) {
+	mockOutbox := new(MockOutboxRepository)
+	sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, new(MockMessageRepository), mockOutbox, new(MockMessageQueue))
+	service.scheduler.paused = true
+
+	err := service.scheduler.handleInsert(context.Background(), primitive.NewObjectID())
+
+	assert.NoError(t, err)
+	mockOutbox.AssertNotCalled(t, "ClaimEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMessageScheduler_HandleEvent_PublishFailureReleasesClaim(t *testing.T) {
+	publishErrors := []error{
+		assert.AnError,
+		&rabbitInterfaces.PublishError{Reason: rabbitInterfaces.ErrPublishNacked},
+		&rabbitInterfaces.PublishError{Reason: rabbitInterfaces.ErrPublishUnroutable, ReplyCode: 312, ReplyText: "NO_ROUTE"},
+	}
+
+	for _, publishErr := range publishErrors {
+		t.Run(publishErr.Error(), func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			mockOutbox := new(MockOutboxRepository)
+			mockQueue := new(MockMessageQueue)
+			sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+			service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue)
+
+			ctx := context.Background()
+			event := newCreatedEvent(t)
+
+			mockQueue.On("PublishMessage", ctx, mock.Anything).Return(publishErr)
+			mockOutbox.On("ReleaseEvent", ctx, event.ID, "sender-1", mock.MatchedBy(func(lastErr string) bool {
+				return strings.Contains(lastErr, publishErr.Error())
+			})).Return(nil)
+
+			err := service.scheduler.handleEvent(ctx, event)
+
+			assert.ErrorContains(t, err, publishErr.Error())
+			mockOutbox.AssertExpectations(t)
+			mockOutbox.AssertNotCalled(t, "CompleteEvent", mock.Anything, mock.Anything, mock.Anything)
+			mockRepo.AssertNotCalled(t, "TransitionStatuses", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMessageScheduler_HandleEvent_StatusAlreadyAdvanced(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue)
+
+	ctx := context.Background()
+	event := newCreatedEvent(t)
+
+	mockQueue.On("PublishMessage", ctx, mock.Anything).Return(nil)
+	mockRepo.On("TransitionStatuses", ctx, []primitive.ObjectID{event.AggregateID}, models.StatusUnsent, models.StatusProcessing).Return(0, nil)
+	mockOutbox.On("CompleteEvent", ctx, event.ID, "sender-1").Return(nil)
+
+	err := service.scheduler.handleEvent(ctx, event)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestMessageScheduler_HandleEvent_ClaimLost(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(5, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue)
+
+	ctx := context.Background()
+	event := newCreatedEvent(t)
+
+	mockQueue.On("PublishMessage", ctx, mock.Anything).Return(nil)
+	mockRepo.On("TransitionStatuses", ctx, []primitive.ObjectID{event.AggregateID}, models.StatusUnsent, models.StatusProcessing).Return(1, nil)
+	mockOutbox.On("CompleteEvent", ctx, event.ID, "sender-1").Return(interfaces.ErrClaimNotHeld)
+
+	err := service.scheduler.handleEvent(ctx, event)
+
+	assert.ErrorContains(t, err, interfaces.ErrClaimNotHeld.Error())
+	mockOutbox.AssertExpectations(t)
+}
+
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) PublishMessage(ctx context.Context, message contracts.QueueMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockPublisher) Close() {
+	m.Called()
+}
+
+func TestMessageScheduler_ProcessOutbox_PublisherPool(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(10, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue).WithPublisherPool(2)
+
+	ctx := context.Background()
+	events := make([]models.OutboxEvent, 0, 6)
+	for i := 0; i < 6; i++ {
+		events = append(events, *newCreatedEvent(t))
+	}
+	failed := events[2]
+
+	publishers := []*MockPublisher{new(MockPublisher), new(MockPublisher)}
+	for _, publisher := range publishers {
+		publisher.On("PublishMessage", ctx, mock.MatchedBy(func(m contracts.QueueMessage) bool {
+			return m.ID == failed.AggregateID.Hex()
+		})).Return(assert.AnError).Maybe()
+		publisher.On("PublishMessage", ctx, mock.Anything).Return(nil).Maybe()
+		publisher.On("Close").Return().Once()
+		mockQueue.On("NewPublisher").Return(publisher, nil).Once()
+	}
+
+	var publishedIDs, eventIDs []primitive.ObjectID
+	for _, event := range events {
+		if event.ID == failed.ID {
+			continue
+		}
+		publishedIDs = append(publishedIDs, event.AggregateID)
+		eventIDs = append(eventIDs, event.ID)
+	}
+
+	mockOutbox.On("ClaimEvents", ctx, []string{models.EventMessageCreated}, "sender-1", 10, time.Minute).Return(events, nil)
+	mockOutbox.On("ReleaseEvent", ctx, failed.ID, "sender-1", mock.Anything).Return(nil)
+	mockRepo.On("TransitionStatuses", ctx, publishedIDs, models.StatusUnsent, models.StatusProcessing).Return(5, nil).Once()
+	mockOutbox.On("CompleteEvents", ctx, eventIDs, "sender-1").Return(4, nil).Once()
+
+	result, err := service.scheduler.processOutbox()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, result.Claimed)
+	assert.Equal(t, 4, result.Published)
+	assert.Equal(t, 2, result.Failed)
+	assert.Len(t, result.Errors, 2)
+	assert.Contains(t, result.Errors[0], failed.ID.Hex())
+	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+	for _, publisher := range publishers {
+		publisher.AssertExpectations(t)
+	}
+}
+
+func TestMessageScheduler_ProcessOutbox_BulkStatusFailureReleasesEvents(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockOutbox := new(MockOutboxRepository)
+	mockQueue := new(MockMessageQueue)
+	sender := domain.NewMessageSender(10, 10*time.Second).WithClaim("sender-1", time.Minute)
+	service := NewSenderService(sender, mockRepo, mockOutbox, mockQueue)
+
+	ctx := context.Background()
+	events := []models.OutboxEvent{*newCreatedEvent(t), *newCreatedEvent(t)}
+
+	mockOutbox.On("ClaimEvents", ctx, []string{models.EventMessageCreated}, "sender-1", 10, time.Minute).Return(events, nil)
+	mockQueue.On("PublishMessage", ctx, mock.Anything).Return(nil).Twice()
+	mockRepo.On("TransitionStatuses", ctx, mock.Anything, models.StatusUnsent, models.StatusProcessing).Return(0, assert.AnError)
+	for _, event := range events {
+		mockOutbox.On("ReleaseEvent", ctx, event.ID, "sender-1", mock.Anything).Return(nil).Once()
+	}
+
+	result, err := service.scheduler.processOutbox()
 
 	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Published)
+	assert.Equal(t, 2, result.Failed)
+	mockOutbox.AssertNotCalled(t, "CompleteEvents", mock.Anything, mock.Anything, mock.Anything)
 	mockRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 	mockQueue.AssertExpectations(t)
-} 
\ No newline at end of file
+}
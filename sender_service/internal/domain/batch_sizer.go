@@ -0,0 +1,68 @@
+package domain
+
+import "fmt"
+
+// BatchDecision is the batch size chosen for the next outbox run. Paused
+// means the relay should publish nothing until the queues drain.
+type BatchDecision struct {
+	Size   int
+	Paused bool
+	Reason string
+}
+
+// BatchSizer adapts the outbox batch size to queue backpressure. The backlog
+// is compared against backlogPerConsumer messages for each processor
+// consumer: an empty backlog doubles the batch, a backlog above the limit
+// halves it, and a backlog above twice the limit pauses publishing.
+type BatchSizer struct {
+	min                int
+	max                int
+	backlogPerConsumer int
+}
+
+func NewBatchSizer(min, max, backlogPerConsumer int) *BatchSizer {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	if backlogPerConsumer < 1 {
+		backlogPerConsumer = 1
+	}
+	return &BatchSizer{
+		min:                min,
+		max:                max,
+		backlogPerConsumer: backlogPerConsumer,
+	}
+}
+
+func (b *BatchSizer) Next(current, backlog, consumers int) BatchDecision {
+	current = b.clamp(current)
+
+	if consumers == 0 {
+		return BatchDecision{Size: current, Paused: true, Reason: "no consumers on the messages queue"}
+	}
+
+	limit := consumers * b.backlogPerConsumer
+	switch {
+	case backlog == 0:
+		return BatchDecision{Size: b.clamp(current * 2), Reason: "queues are empty"}
+	case backlog > 2*limit:
+		return BatchDecision{Size: b.clamp(current / 2), Paused: true, Reason: fmt.Sprintf("backlog %d exceeds twice the limit of %d", backlog, limit)}
+	case backlog > limit:
+		return BatchDecision{Size: b.clamp(current / 2), Reason: fmt.Sprintf("backlog %d exceeds the limit of %d", backlog, limit)}
+	default:
+		return BatchDecision{Size: current, Reason: fmt.Sprintf("backlog %d is within the limit of %d", backlog, limit)}
+	}
+}
+
+func (b *BatchSizer) clamp(size int) int {
+	if size < b.min {
+		return b.min
+	}
+	if size > b.max {
+		return b.max
+	}
+	return size
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchSizer_Next(t *testing.T) {
+	sizer := NewBatchSizer(2, 100, 50)
+
+	tests := []struct {
+		name       string
+		current    int
+		backlog    int
+		consumers  int
+		wantSize   int
+		wantPaused bool
+	}{
+		{name: "grows when queues are empty", current: 10, backlog: 0, consumers: 1, wantSize: 20},
+		{name: "growth is capped at max", current: 80, backlog: 0, consumers: 1, wantSize: 100},
+		{name: "holds within the limit", current: 10, backlog: 50, consumers: 1, wantSize: 10},
+		{name: "limit scales with consumers", current: 10, backlog: 150, consumers: 4, wantSize: 10},
+		{name: "shrinks above the limit", current: 10, backlog: 60, consumers: 1, wantSize: 5},
+		{name: "shrink is floored at min", current: 3, backlog: 60, consumers: 1, wantSize: 2},
+		{name: "pauses above twice the limit", current: 10, backlog: 101, consumers: 1, wantSize: 5, wantPaused: true},
+		{name: "pauses without consumers", current: 10, backlog: 0, consumers: 0, wantSize: 10, wantPaused: true},
+		{name: "clamps an out of range current size", current: 500, backlog: 10, consumers: 1, wantSize: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := sizer.Next(tt.current, tt.backlog, tt.consumers)
+
+			assert.Equal(t, tt.wantSize, decision.Size)
+			assert.Equal(t, tt.wantPaused, decision.Paused)
+			assert.NotEmpty(t, decision.Reason)
+		})
+	}
+}
+
+func TestNewBatchSizer_NormalizesLimits(t *testing.T) {
+	sizer := NewBatchSizer(0, -1, 0)
+
+	assert.Equal(t, 1, sizer.min)
+	assert.Equal(t, 1, sizer.max)
+	assert.Equal(t, 1, sizer.backlogPerConsumer)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// RecurringSchedule is a standard five-field cron expression, or a
+// descriptor such as @daily, evaluated in a fixed timezone.
+type RecurringSchedule struct {
+	schedule cron.Schedule
+	location *time.Location
+}
+
+func ParseRecurringSchedule(expr string, timezone string) (*RecurringSchedule, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+
+	return &RecurringSchedule{schedule: schedule, location: location}, nil
+}
+
+// Next returns the first occurrence strictly after t.
+func (s *RecurringSchedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t.In(s.location))
+}
+
+func (s *RecurringSchedule) Location() *time.Location {
+	return s.location
+}
+
+// NextN returns the first n occurrences strictly after t.
+func (s *RecurringSchedule) NextN(t time.Time, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		t = s.Next(t)
+		if t.IsZero() {
+			break
+		}
+		occurrences = append(occurrences, t)
+	}
+	return occurrences
+}
+
+// Occurrence is the data a recurring message's content template is rendered
+// with. Time is in the schedule's timezone.
+type Occurrence struct {
+	Time time.Time
+	To   string
+}
+
+func ParseContentTemplate(content string) (*template.Template, error) {
+	tmpl, err := template.New("content").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content template: %v", err)
+	}
+	return tmpl, nil
+}
+
+func RenderContent(content string, occurrence Occurrence) (string, error) {
+	tmpl, err := ParseContentTemplate(content)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, occurrence); err != nil {
+		return "", fmt.Errorf("failed to render content: %v", err)
+	}
+	return buf.String(), nil
+}
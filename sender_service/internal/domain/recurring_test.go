@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecurringSchedule(t *testing.T) {
+	_, err := ParseRecurringSchedule("0 9 * * 1-5", "Europe/Istanbul")
+	assert.NoError(t, err)
+
+	_, err = ParseRecurringSchedule("@daily", "")
+	assert.NoError(t, err)
+
+	_, err = ParseRecurringSchedule("not a cron", "UTC")
+	assert.ErrorContains(t, err, "invalid cron expression")
+
+	_, err = ParseRecurringSchedule("0 9 * * *", "Mars/Olympus")
+	assert.ErrorContains(t, err, "invalid timezone")
+}
+
+func TestRecurringSchedule_NextN(t *testing.T) {
+	schedule, err := ParseRecurringSchedule("0 9 * * 1-5", "Europe/Istanbul")
+	assert.NoError(t, err)
+
+	// Friday 2024-05-03 10:00 in Istanbul (UTC+3).
+	from := time.Date(2024, 5, 3, 7, 0, 0, 0, time.UTC)
+	occurrences := schedule.NextN(from, 3)
+
+	assert.Len(t, occurrences, 3)
+	assert.Equal(t, time.Date(2024, 5, 6, 6, 0, 0, 0, time.UTC), occurrences[0].UTC())
+	assert.Equal(t, time.Date(2024, 5, 7, 6, 0, 0, 0, time.UTC), occurrences[1].UTC())
+	assert.Equal(t, time.Date(2024, 5, 8, 6, 0, 0, 0, time.UTC), occurrences[2].UTC())
+	assert.Equal(t, "Europe/Istanbul", occurrences[0].Location().String())
+}
+
+func TestRenderContent(t *testing.T) {
+	occurrence := Occurrence{
+		Time: time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+		To:   "+905321234567",
+	}
+
+	content, err := RenderContent(`Standup on {{.Time.Format "Monday"}}`, occurrence)
+	assert.NoError(t, err)
+	assert.Equal(t, "Standup on Monday", content)
+
+	content, err = RenderContent("Plain reminder", occurrence)
+	assert.NoError(t, err)
+	assert.Equal(t, "Plain reminder", content)
+
+	_, err = RenderContent("{{.Time", occurrence)
+	assert.ErrorContains(t, err, "invalid content template")
+
+	_, err = RenderContent("{{.Missing}}", occurrence)
+	assert.ErrorContains(t, err, "failed to render content")
+}
@@ -1,23 +1,40 @@
 package domain
 
 import (
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
 )
 
+const defaultClaimLease = time.Minute
+
 type MessageSender struct {
 	batchSize     int
 	checkInterval time.Duration
+	instanceID    string
+	claimLease    time.Duration
 }
 
 func NewMessageSender(batchSize int, checkInterval time.Duration) *MessageSender {
+	hostname, _ := os.Hostname()
 	return &MessageSender{
 		batchSize:     batchSize,
 		checkInterval: checkInterval,
+		instanceID:    fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		claimLease:    defaultClaimLease,
 	}
 }
 
+// WithClaim sets the owner ID recorded on claimed outbox events and how long
+// the claim is held before other instances may take the events over.
+func (s *MessageSender) WithClaim(instanceID string, lease time.Duration) *MessageSender {
+	s.instanceID = instanceID
+	s.claimLease = lease
+	return s
+}
+
 func (s *MessageSender) PrepareMessage(content string, to string) *models.Message {
 	return &models.Message{
 		Content:    content,
@@ -35,4 +52,12 @@ func (s *MessageSender) GetBatchSize() int {
 
 func (s *MessageSender) GetCheckInterval() time.Duration {
 	return s.checkInterval
-} 
\ No newline at end of file
+}
+
+func (s *MessageSender) GetInstanceID() string {
+	return s.instanceID
+}
+
+func (s *MessageSender) GetClaimLease() time.Duration {
+	return s.claimLease
+}
@@ -34,4 +34,14 @@ func TestMessageSender_PrepareMessage(t *testing.T) {
 	assert.Equal(t, 0, msg.RetryCount)
 	assert.False(t, msg.CreatedAt.IsZero())
 	assert.False(t, msg.UpdatedAt.IsZero())
-} 
\ No newline at end of file
+}
+
+func TestMessageSender_WithClaim(t *testing.T) {
+	sender := NewMessageSender(5, 10*time.Second)
+	assert.NotEmpty(t, sender.GetInstanceID())
+	assert.Equal(t, time.Minute, sender.GetClaimLease())
+
+	sender = sender.WithClaim("sender-1", 30*time.Second)
+	assert.Equal(t, "sender-1", sender.GetInstanceID())
+	assert.Equal(t, 30*time.Second, sender.GetClaimLease())
+}
@@ -0,0 +1,62 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/auth"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func UnaryAPIKeyAuth(keys *auth.APIKeys) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, keys); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAPIKeyAuth(keys *auth.APIKeys) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), keys); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func UnaryRateLimit(limiter ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !limiter.Allow() {
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamRateLimit(limiter ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !limiter.Allow() {
+			return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, keys *auth.APIKeys) error {
+	if !keys.Enabled() {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(auth.APIKeyMetadata)
+	if len(values) == 0 || !keys.Valid(values[0]) {
+		return status.Error(codes.Unauthenticated, "invalid or missing API key")
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/api/senderv1"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/handlers"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/problems"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/auth"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
+
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxBatchSize = 500
+
+type Server struct {
+	senderv1.UnimplementedSenderServiceServer
+	service ports.MessageService
+}
+
+func NewServer(service ports.MessageService) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// NewGRPCServer builds a gRPC server exposing service with the same API key
+// authentication and rate limiting that guard the REST routes.
+func NewGRPCServer(service ports.MessageService, keys *auth.APIKeys, limiter ratelimit.Limiter) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryAPIKeyAuth(keys), UnaryRateLimit(limiter)),
+		grpc.ChainStreamInterceptor(StreamAPIKeyAuth(keys), StreamRateLimit(limiter)),
+	)
+	senderv1.RegisterSenderServiceServer(srv, NewServer(service))
+	return srv
+}
+
+func (s *Server) CreateMessage(ctx context.Context, req *senderv1.CreateMessageRequest) (*senderv1.CreateMessageResponse, error) {
+	if violations := validateCreateRequest(req, ""); len(violations) > 0 {
+		return nil, invalidArgument(violations)
+	}
+
+	id, err := s.service.CreateMessage(ctx, req.GetContent(), req.GetTo())
+	if err != nil {
+		log.Printf("grpc: failed to create message: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create message")
+	}
+
+	return &senderv1.CreateMessageResponse{Id: id.Hex()}, nil
+}
+
+func (s *Server) BatchCreateMessages(ctx context.Context, req *senderv1.BatchCreateMessagesRequest) (*senderv1.BatchCreateMessagesResponse, error) {
+	if len(req.GetMessages()) == 0 {
+		return nil, invalidArgument([]*errdetails.BadRequest_FieldViolation{
+			{Field: "messages", Description: "at least one message is required"},
+		})
+	}
+	if len(req.GetMessages()) > maxBatchSize {
+		return nil, invalidArgument([]*errdetails.BadRequest_FieldViolation{
+			{Field: "messages", Description: fmt.Sprintf("at most %d messages are allowed per batch", maxBatchSize)},
+		})
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	messages := make([]ports.NewMessage, 0, len(req.GetMessages()))
+	for i, m := range req.GetMessages() {
+		violations = append(violations, validateCreateRequest(m, fmt.Sprintf("messages[%d].", i))...)
+		messages = append(messages, ports.NewMessage{Content: m.GetContent(), To: m.GetTo()})
+	}
+	if len(violations) > 0 {
+		return nil, invalidArgument(violations)
+	}
+
+	ids, err := s.service.BatchCreateMessages(ctx, messages)
+	if err != nil {
+		log.Printf("grpc: failed to create message batch: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create messages")
+	}
+
+	resp := &senderv1.BatchCreateMessagesResponse{Ids: make([]string, 0, len(ids))}
+	for _, id := range ids {
+		resp.Ids = append(resp.Ids, id.Hex())
+	}
+	return resp, nil
+}
+
+func (s *Server) GetMessage(ctx context.Context, req *senderv1.GetMessageRequest) (*senderv1.Message, error) {
+	id, err := primitive.ObjectIDFromHex(req.GetId())
+	if err != nil {
+		return nil, invalidArgument([]*errdetails.BadRequest_FieldViolation{
+			{Field: "id", Description: "id must be a valid message ID"},
+		})
+	}
+
+	msg, err := s.service.GetMessage(ctx, id)
+	if err != nil {
+		log.Printf("grpc: failed to get message %s: %v", req.GetId(), err)
+		return nil, status.Error(codes.Internal, "failed to get message")
+	}
+	if msg == nil {
+		return nil, status.Errorf(codes.NotFound, "message %s not found", req.GetId())
+	}
+
+	return toProto(msg), nil
+}
+
+func (s *Server) ListMessages(req *senderv1.ListMessagesRequest, stream senderv1.SenderService_ListMessagesServer) error {
+	messages, err := s.service.ListMessages(stream.Context())
+	if err != nil {
+		log.Printf("grpc: failed to list messages: %v", err)
+		return status.Error(codes.Internal, "failed to list messages")
+	}
+
+	for i := range messages {
+		if err := stream.Send(toProto(&messages[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) StartScheduler(ctx context.Context, req *senderv1.StartSchedulerRequest) (*senderv1.SchedulerResponse, error) {
+	s.service.StartScheduler(ctx)
+	return &senderv1.SchedulerResponse{Status: "scheduler started"}, nil
+}
+
+func (s *Server) StopScheduler(ctx context.Context, req *senderv1.StopSchedulerRequest) (*senderv1.SchedulerResponse, error) {
+	s.service.StopScheduler(ctx)
+	return &senderv1.SchedulerResponse{Status: "scheduler stopped"}, nil
+}
+
+// validateCreateRequest applies the REST request struct's validation rules so
+// both transports accept and reject exactly the same input.
+func validateCreateRequest(req *senderv1.CreateMessageRequest, prefix string) []*errdetails.BadRequest_FieldViolation {
+	body := handlers.SendMessageRequest{
+		To:      req.GetTo(),
+		Content: req.GetContent(),
+	}
+
+	err := binding.Validator.ValidateStruct(&body)
+	if err == nil {
+		return nil
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, fe := range problems.FromBindError(err, body).Errors {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       prefix + fe.Field,
+			Description: fe.Message,
+		})
+	}
+	return violations
+}
+
+func invalidArgument(violations []*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, "request validation failed")
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func toProto(msg *models.Message) *senderv1.Message {
+	return &senderv1.Message{
+		Id:         msg.ID.Hex(),
+		To:         msg.To,
+		Content:    msg.Content,
+		Status:     string(msg.Status),
+		RetryCount: int32(msg.RetryCount),
+		CreatedAt:  timestamppb.New(msg.CreatedAt),
+		UpdatedAt:  timestamppb.New(msg.UpdatedAt),
+	}
+}
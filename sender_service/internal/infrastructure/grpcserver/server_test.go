@@ -0,0 +1,328 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/api/senderv1"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/ports"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/auth"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
+)
+
+type MockMessageService struct {
+	mock.Mock
+}
+
+func (m *MockMessageService) CreateMessage(ctx context.Context, content string, to string) (primitive.ObjectID, error) {
+	args := m.Called(ctx, content, to)
+	return args.Get(0).(primitive.ObjectID), args.Error(1)
+}
+
+func (m *MockMessageService) BatchCreateMessages(ctx context.Context, messages []ports.NewMessage) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx, messages)
+	if ids, ok := args.Get(0).([]primitive.ObjectID); ok {
+		return ids, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMessageService) GetMessage(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
+	args := m.Called(ctx, id)
+	if msg, ok := args.Get(0).(*models.Message); ok {
+		return msg, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMessageService) ListMessages(ctx context.Context) ([]models.Message, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Message), args.Error(1)
+}
+
+func (m *MockMessageService) StartScheduler(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockMessageService) StopScheduler(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockMessageService) SchedulerStatus(ctx context.Context) ports.SchedulerStatus {
+	args := m.Called(ctx)
+	return args.Get(0).(ports.SchedulerStatus)
+}
+
+func (m *MockMessageService) UpdateScheduler(ctx context.Context, settings ports.SchedulerSettings) (ports.SchedulerStatus, error) {
+	args := m.Called(ctx, settings)
+	return args.Get(0).(ports.SchedulerStatus), args.Error(1)
+}
+
+func newTestClient(t *testing.T, service ports.MessageService, keys []string) senderv1.SenderServiceClient {
+	return newTestClientWithLimiter(t, service, keys, ratelimit.NewRateLimiter(1000, 1000))
+}
+
+func newTestClientWithLimiter(t *testing.T, service ports.MessageService, keys []string, limiter ratelimit.Limiter) senderv1.SenderServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	srv := NewGRPCServer(service, auth.NewAPIKeys(keys), limiter)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return senderv1.NewSenderServiceClient(conn)
+}
+
+func fieldViolations(t *testing.T, err error) map[string]string {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+
+	violations := make(map[string]string)
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				violations[v.GetField()] = v.GetDescription()
+			}
+		}
+	}
+	return violations
+}
+
+func TestServer_CreateMessage(t *testing.T) {
+	t.Run("successful message creation", func(t *testing.T) {
+		mockService := new(MockMessageService)
+		id := primitive.NewObjectID()
+		mockService.On("CreateMessage", mock.Anything, "test content", "+905321234567").Return(id, nil)
+
+		client := newTestClient(t, mockService, nil)
+		resp, err := client.CreateMessage(context.Background(), &senderv1.CreateMessageRequest{
+			To:      "+905321234567",
+			Content: "test content",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, id.Hex(), resp.GetId())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("validation uses REST rules", func(t *testing.T) {
+		mockService := new(MockMessageService)
+
+		client := newTestClient(t, mockService, nil)
+		_, err := client.CreateMessage(context.Background(), &senderv1.CreateMessageRequest{
+			To:      "05321234111",
+			Content: "",
+		})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		violations := fieldViolations(t, err)
+		assert.Equal(t, "Phone number must be in E.164 format (e.g., +90111111111)", violations["to"])
+		assert.Equal(t, "content is required", violations["content"])
+		mockService.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("service error is not leaked", func(t *testing.T) {
+		mockService := new(MockMessageService)
+		mockService.On("CreateMessage", mock.Anything, "test content", "+905321234567").Return(primitive.NilObjectID, assert.AnError)
+
+		client := newTestClient(t, mockService, nil)
+		_, err := client.CreateMessage(context.Background(), &senderv1.CreateMessageRequest{
+			To:      "+905321234567",
+			Content: "test content",
+		})
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.NotContains(t, err.Error(), assert.AnError.Error())
+	})
+}
+
+func TestServer_BatchCreateMessages(t *testing.T) {
+	t.Run("successful batch creation", func(t *testing.T) {
+		mockService := new(MockMessageService)
+		ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+		mockService.On("BatchCreateMessages", mock.Anything, []ports.NewMessage{
+			{Content: "first", To: "+905321234567"},
+			{Content: "second", To: "+905321234568"},
+		}).Return(ids, nil)
+
+		client := newTestClient(t, mockService, nil)
+		resp, err := client.BatchCreateMessages(context.Background(), &senderv1.BatchCreateMessagesRequest{
+			Messages: []*senderv1.CreateMessageRequest{
+				{To: "+905321234567", Content: "first"},
+				{To: "+905321234568", Content: "second"},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[0].Hex(), ids[1].Hex()}, resp.GetIds())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("violations are indexed per message", func(t *testing.T) {
+		mockService := new(MockMessageService)
+
+		client := newTestClient(t, mockService, nil)
+		_, err := client.BatchCreateMessages(context.Background(), &senderv1.BatchCreateMessagesRequest{
+			Messages: []*senderv1.CreateMessageRequest{
+				{To: "+905321234567", Content: "first"},
+				{To: "invalid", Content: "second"},
+			},
+		})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, fieldViolations(t, err), "messages[1].to")
+	})
+
+	t.Run("empty batch is rejected", func(t *testing.T) {
+		client := newTestClient(t, new(MockMessageService), nil)
+		_, err := client.BatchCreateMessages(context.Background(), &senderv1.BatchCreateMessagesRequest{})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestServer_GetMessage(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		mockService := new(MockMessageService)
+		msg := &models.Message{
+			ID:        primitive.NewObjectID(),
+			To:        "+905321234567",
+			Content:   "test content",
+			Status:    models.StatusSent,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		mockService.On("GetMessage", mock.Anything, msg.ID).Return(msg, nil)
+
+		client := newTestClient(t, mockService, nil)
+		resp, err := client.GetMessage(context.Background(), &senderv1.GetMessageRequest{Id: msg.ID.Hex()})
+
+		require.NoError(t, err)
+		assert.Equal(t, msg.ID.Hex(), resp.GetId())
+		assert.Equal(t, string(models.StatusSent), resp.GetStatus())
+		assert.Equal(t, msg.CreatedAt.Unix(), resp.GetCreatedAt().AsTime().Unix())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockMessageService)
+		id := primitive.NewObjectID()
+		mockService.On("GetMessage", mock.Anything, id).Return(nil, nil)
+
+		client := newTestClient(t, mockService, nil)
+		_, err := client.GetMessage(context.Background(), &senderv1.GetMessageRequest{Id: id.Hex()})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		client := newTestClient(t, new(MockMessageService), nil)
+		_, err := client.GetMessage(context.Background(), &senderv1.GetMessageRequest{Id: "not-an-id"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, fieldViolations(t, err), "id")
+	})
+}
+
+func TestServer_ListMessages(t *testing.T) {
+	mockService := new(MockMessageService)
+	messages := []models.Message{
+		{ID: primitive.NewObjectID(), Content: "first", Status: models.StatusUnsent},
+		{ID: primitive.NewObjectID(), Content: "second", Status: models.StatusSent},
+	}
+	mockService.On("ListMessages", mock.Anything).Return(messages, nil)
+
+	client := newTestClient(t, mockService, nil)
+	stream, err := client.ListMessages(context.Background(), &senderv1.ListMessagesRequest{})
+	require.NoError(t, err)
+
+	var received []string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		received = append(received, msg.GetContent())
+	}
+
+	assert.Equal(t, []string{"first", "second"}, received)
+}
+
+func TestServer_Scheduler(t *testing.T) {
+	mockService := new(MockMessageService)
+	mockService.On("StartScheduler", mock.Anything).Return()
+	mockService.On("StopScheduler", mock.Anything).Return()
+
+	client := newTestClient(t, mockService, nil)
+
+	resp, err := client.StartScheduler(context.Background(), &senderv1.StartSchedulerRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "scheduler started", resp.GetStatus())
+
+	resp, err = client.StopScheduler(context.Background(), &senderv1.StopSchedulerRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "scheduler stopped", resp.GetStatus())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestServer_APIKeyAuth(t *testing.T) {
+	mockService := new(MockMessageService)
+	mockService.On("ListMessages", mock.Anything).Return([]models.Message{}, nil)
+	mockService.On("StartScheduler", mock.Anything).Return()
+
+	client := newTestClient(t, mockService, []string{"secret"})
+
+	_, err := client.StartScheduler(context.Background(), &senderv1.StartSchedulerRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.ListMessages(context.Background(), &senderv1.ListMessagesRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), auth.APIKeyMetadata, "secret")
+	_, err = client.StartScheduler(ctx, &senderv1.StartSchedulerRequest{})
+	assert.NoError(t, err)
+
+	stream, err = client.ListMessages(ctx, &senderv1.ListMessagesRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	mockService := new(MockMessageService)
+	mockService.On("StartScheduler", mock.Anything).Return()
+
+	client := newTestClientWithLimiter(t, mockService, nil, ratelimit.NewRateLimiter(1, 1))
+
+	_, err := client.StartScheduler(context.Background(), &senderv1.StartSchedulerRequest{})
+	assert.NoError(t, err)
+
+	_, err = client.StartScheduler(context.Background(), &senderv1.StartSchedulerRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/correlation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Correlation adds the request's correlation ID to its context, generating
+// one when the client did not send it, and echoes it in the response.
+func Correlation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(correlation.Header)
+		if id == "" {
+			id = correlation.NewID()
+		}
+		c.Header(correlation.Header, id)
+		c.Request = c.Request.WithContext(correlation.WithID(c.Request.Context(), id))
+		c.Next()
+	}
+}
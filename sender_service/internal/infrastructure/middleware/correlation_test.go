@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/correlation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "client sends an ID", header: "request-1"},
+		{name: "client sends no ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			router := gin.New()
+			router.Use(Correlation())
+			router.GET("/test", func(c *gin.Context) {
+				seen = correlation.FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(correlation.Header, tt.header)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.NotEmpty(t, seen)
+			assert.Equal(t, seen, w.Header().Get(correlation.Header))
+			if tt.header != "" {
+				assert.Equal(t, tt.header, seen)
+			}
+		})
+	}
+}
@@ -3,14 +3,17 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/problems"
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RateLimit(rps float64, burst int) gin.HandlerFunc {
-	limiter := ratelimit.NewRateLimiter(rps, burst)
-	
+	return RateLimitWithLimiter(ratelimit.NewRateLimiter(rps, burst))
+}
+
+func RateLimitWithLimiter(limiter ratelimit.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !limiter.Allow() {
 			c.JSON(http.StatusTooManyRequests, gin.H{
@@ -21,4 +24,14 @@ func RateLimit(rps float64, burst int) gin.HandlerFunc {
 		}
 		c.Next()
 	}
-} 
\ No newline at end of file
+}
+
+func ProblemRateLimit(limiter ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limiter.Allow() {
+			problems.Write(c, problems.RateLimited())
+			return
+		}
+		c.Next()
+	}
+}
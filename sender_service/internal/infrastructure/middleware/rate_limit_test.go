@@ -6,6 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Furkan-Gulsen/reliable_messaging_system/sender_service/internal/application/problems"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ratelimit"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -87,4 +90,26 @@ func TestRateLimit_Recovery(t *testing.T) {
 	req = httptest.NewRequest(http.MethodGet, "/test", nil)
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
-} 
\ No newline at end of file
+}
+
+func TestProblemRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ProblemRateLimit(ratelimit.NewRateLimiter(1, 1)))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, problems.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), string(problems.CodeRateLimited))
+}
@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"github.com/sony/gobreaker"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoAlertStateStore struct {
+	collection *mongo.Collection
+	cb         *gobreaker.CircuitBreaker
+}
+
+func NewAlertStateStore(db *mongo.Database) interfaces.AlertStateStore {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "mongodb-alert-state",
+		MaxRequests: 3,
+		Interval:    10 * time.Second,
+		Timeout:     30 * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= 3 && failureRatio >= 0.6
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			fmt.Printf("Circuit breaker %s state changed from %s to %s\n", name, from, to)
+		},
+	})
+
+	return &mongoAlertStateStore{
+		collection: db.Collection("alert_state"),
+		cb:         cb,
+	}
+}
+
+func (s *mongoAlertStateStore) LoadAlertState(ctx context.Context, rule string) (*models.AlertState, error) {
+	result, err := s.cb.Execute(func() (interface{}, error) {
+		var state models.AlertState
+		err := s.collection.FindOne(ctx, bson.M{"_id": rule}).Decode(&state)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &state, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result.(*models.AlertState), nil
+}
+
+// CreateAlertState inserts the state keyed by rule, so only one instance can
+// record that a rule started firing.
+func (s *mongoAlertStateStore) CreateAlertState(ctx context.Context, state *models.AlertState) (bool, error) {
+	result, err := s.cb.Execute(func() (interface{}, error) {
+		_, err := s.collection.InsertOne(ctx, state)
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(bool), nil
+}
+
+func (s *mongoAlertStateStore) DeleteAlertState(ctx context.Context, rule string) (bool, error) {
+	result, err := s.cb.Execute(func() (interface{}, error) {
+		return s.collection.DeleteOne(ctx, bson.M{"_id": rule})
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(*mongo.DeleteResult).DeletedCount == 1, nil
+}
@@ -95,4 +95,52 @@ func (s *redisIdempotencyService) StoreWebhookMessageID(ctx context.Context, mes
 		return nil, nil
 	})
 	return err
+}
+
+// releaseScript deletes a claim only if it is still the caller's.
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// claimValue is the inbox entry of a claimed message. Its status is not
+// "processed", so IsProcessed reports false while it is held.
+func claimValue(owner string) (string, error) {
+	data, err := json.Marshal(map[string]string{"status": "processing", "owner": owner})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal data: %v", err)
+	}
+	return string(data), nil
+}
+
+func (s *redisIdempotencyService) Claim(ctx context.Context, messageID string, owner string, ttl time.Duration) (bool, error) {
+	result, err := s.cb.Execute(func() (interface{}, error) {
+		value, err := claimValue(owner)
+		if err != nil {
+			return false, err
+		}
+		return s.client.SetNX(ctx, fmt.Sprintf("inbox:%s", messageID), value, ttl).Result()
+	})
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(bool), nil
+}
+
+func (s *redisIdempotencyService) Release(ctx context.Context, messageID string, owner string) error {
+	_, err := s.cb.Execute(func() (interface{}, error) {
+		value, err := claimValue(owner)
+		if err != nil {
+			return nil, err
+		}
+		return nil, releaseScript.Run(ctx, s.client, []string{fmt.Sprintf("inbox:%s", messageID)}, value).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return nil
 } 
\ No newline at end of file
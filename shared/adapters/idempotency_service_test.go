@@ -0,0 +1,59 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/redis/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyService_Claim(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		testIdempotencyClaim(t, NewMemoryIdempotencyService())
+	})
+	t.Run("redis", func(t *testing.T) {
+		testIdempotencyClaim(t, NewIdempotencyService(newTestRedisClient(t)))
+	})
+}
+
+// testIdempotencyClaim checks that one owner at a time holds the claim on a
+// message and that a processed message cannot be claimed.
+func testIdempotencyClaim(t *testing.T, service interfaces.IdempotencyServicePort) {
+	ctx := context.Background()
+
+	claimed, err := service.Claim(ctx, "m1", "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = service.Claim(ctx, "m1", "b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed, "the message is claimed by a")
+
+	processed, err := service.IsProcessed(ctx, "m1")
+	require.NoError(t, err)
+	assert.False(t, processed, "a claimed message is not processed")
+
+	require.NoError(t, service.Release(ctx, "m1", "b"))
+	claimed, err = service.Claim(ctx, "m1", "b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed, "b cannot release the claim of a")
+
+	require.NoError(t, service.Release(ctx, "m1", "a"))
+	claimed, err = service.Claim(ctx, "m1", "b", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed, "the claim is free once a releases it")
+
+	require.NoError(t, service.MarkAsProcessed(ctx, "m1"))
+	require.NoError(t, service.Release(ctx, "m1", "b"))
+	processed, err = service.IsProcessed(ctx, "m1")
+	require.NoError(t, err)
+	assert.True(t, processed, "releasing does not remove a processed entry")
+
+	claimed, err = service.Claim(ctx, "m1", "c", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed, "a processed message cannot be claimed")
+}
@@ -0,0 +1,131 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"github.com/sony/gobreaker"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLeaseStore struct {
+	collection *mongo.Collection
+	cb         *gobreaker.CircuitBreaker
+}
+
+func NewLeaseStore(db *mongo.Database) interfaces.LeaseStore {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "mongodb-lease-store",
+		MaxRequests: 3,
+		Interval:    10 * time.Second,
+		Timeout:     30 * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= 3 && failureRatio >= 0.6
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			fmt.Printf("Circuit breaker %s state changed from %s to %s\n", name, from, to)
+		},
+	})
+
+	return &mongoLeaseStore{
+		collection: db.Collection("leases"),
+		cb:         cb,
+	}
+}
+
+func (s *mongoLeaseStore) Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (*models.Lease, error) {
+	result, err := s.cb.Execute(func() (interface{}, error) {
+		now := time.Now()
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+		var lease models.Lease
+		renewFilter := bson.M{
+			"_id":        name,
+			"holder":     holder,
+			"expires_at": bson.M{"$gt": now},
+		}
+		renew := bson.M{"$set": bson.M{"expires_at": now.Add(ttl)}}
+		err := s.collection.FindOneAndUpdate(ctx, renewFilter, renew, opts).Decode(&lease)
+		if err == nil {
+			return &lease, nil
+		}
+		if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+
+		// Taking over a free or expired lease bumps the fencing token so work
+		// started under the previous holder can be told apart.
+		takeoverFilter := bson.M{
+			"_id":        name,
+			"expires_at": bson.M{"$lte": now},
+		}
+		takeover := bson.M{
+			"$set": bson.M{
+				"holder":      holder,
+				"expires_at":  now.Add(ttl),
+				"acquired_at": now,
+			},
+			"$inc": bson.M{"token": int64(1)},
+		}
+		err = s.collection.FindOneAndUpdate(ctx, takeoverFilter, takeover, opts.SetUpsert(true)).Decode(&lease)
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &lease, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result.(*models.Lease), nil
+}
+
+func (s *mongoLeaseStore) Release(ctx context.Context, name string, holder string) error {
+	_, err := s.cb.Execute(func() (interface{}, error) {
+		update := bson.M{"$set": bson.M{"expires_at": time.Now()}}
+		_, err := s.collection.UpdateOne(ctx, bson.M{"_id": name, "holder": holder}, update)
+		return nil, err
+	})
+
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return nil
+}
+
+func (s *mongoLeaseStore) IsCurrent(ctx context.Context, name string, token int64) (bool, error) {
+	result, err := s.cb.Execute(func() (interface{}, error) {
+		filter := bson.M{
+			"_id":        name,
+			"token":      token,
+			"expires_at": bson.M{"$gt": time.Now()},
+		}
+		count, err := s.collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return false, err
+		}
+		return count > 0, nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(bool), nil
+}
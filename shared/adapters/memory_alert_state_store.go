@@ -0,0 +1,51 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+)
+
+type memoryAlertStateStore struct {
+	db *MemoryDatabase
+}
+
+func NewMemoryAlertStateStore(db *MemoryDatabase) interfaces.AlertStateStore {
+	return &memoryAlertStateStore{db: db}
+}
+
+func (s *memoryAlertStateStore) LoadAlertState(ctx context.Context, rule string) (*models.AlertState, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	state, ok := s.db.alertStates[rule]
+	if !ok {
+		return nil, nil
+	}
+	loaded := *state
+	return &loaded, nil
+}
+
+func (s *memoryAlertStateStore) CreateAlertState(ctx context.Context, state *models.AlertState) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.alertStates[state.Rule]; ok {
+		return false, nil
+	}
+	saved := *state
+	s.db.alertStates[state.Rule] = &saved
+	return true, nil
+}
+
+func (s *memoryAlertStateStore) DeleteAlertState(ctx context.Context, rule string) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.alertStates[rule]; !ok {
+		return false, nil
+	}
+	delete(s.db.alertStates, rule)
+	return true, nil
+}
@@ -0,0 +1,157 @@
+package adapters
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryDatabase holds the collections of the in-memory repositories, the
+// way a MongoDB database holds the collections of the Mongo repositories.
+// Repositories created from the same MemoryDatabase share its data, and
+// writes that the Mongo repositories make in one transaction are made under
+// one lock. Documents are copied on the way in and out, so callers never
+// share memory with the store.
+type MemoryDatabase struct {
+	mu sync.Mutex
+
+	messages     map[primitive.ObjectID]*models.Message
+	messageOrder []primitive.ObjectID
+
+	outbox map[primitive.ObjectID]*models.OutboxEvent
+	// outboxSeq orders outbox inserts for events created at the same time and
+	// positions change stream watchers. outboxLog keeps the inserts that a
+	// running watcher or a saved stream position has not seen yet.
+	outboxSeq     int64
+	outboxOrder   map[primitive.ObjectID]int64
+	outboxLog     []outboxInsert
+	streamTokens  map[string]int64
+	watchers      map[*int64]struct{}
+	outboxChanged chan struct{}
+
+	recurring       map[primitive.ObjectID]*models.RecurringMessage
+	leases          map[string]*models.Lease
+	schedulerStates map[string]*models.SchedulerState
+	alertStates     map[string]*models.AlertState
+}
+
+type outboxInsert struct {
+	seq int64
+	id  primitive.ObjectID
+}
+
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{
+		messages:        make(map[primitive.ObjectID]*models.Message),
+		outbox:          make(map[primitive.ObjectID]*models.OutboxEvent),
+		outboxOrder:     make(map[primitive.ObjectID]int64),
+		streamTokens:    make(map[string]int64),
+		watchers:        make(map[*int64]struct{}),
+		outboxChanged:   make(chan struct{}),
+		recurring:       make(map[primitive.ObjectID]*models.RecurringMessage),
+		leases:          make(map[string]*models.Lease),
+		schedulerStates: make(map[string]*models.SchedulerState),
+		alertStates:     make(map[string]*models.AlertState),
+	}
+}
+
+// insertMessage stores a copy of msg. The caller holds mu and has checked
+// that the ID is free.
+func (db *MemoryDatabase) insertMessage(msg *models.Message) {
+	db.messages[msg.ID] = copyMessage(msg)
+	db.messageOrder = append(db.messageOrder, msg.ID)
+}
+
+// insertOutboxEvent stores a copy of event and wakes change stream watchers.
+// The caller holds mu and has checked that the ID is free.
+func (db *MemoryDatabase) insertOutboxEvent(event *models.OutboxEvent) {
+	db.outboxSeq++
+	db.outbox[event.ID] = copyOutboxEvent(event)
+	db.outboxOrder[event.ID] = db.outboxSeq
+	if len(db.streamTokens) > 0 || len(db.watchers) > 0 {
+		db.outboxLog = append(db.outboxLog, outboxInsert{seq: db.outboxSeq, id: event.ID})
+	}
+
+	close(db.outboxChanged)
+	db.outboxChanged = make(chan struct{})
+}
+
+// sortedOutboxEvents returns the outbox events oldest first. The caller
+// holds mu.
+func (db *MemoryDatabase) sortedOutboxEvents() []*models.OutboxEvent {
+	events := make([]*models.OutboxEvent, 0, len(db.outbox))
+	for _, event := range db.outbox {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		}
+		return db.outboxOrder[events[i].ID] < db.outboxOrder[events[j].ID]
+	})
+	return events
+}
+
+// trimOutboxLog drops the inserts every watcher and saved stream position
+// has seen. The caller holds mu.
+func (db *MemoryDatabase) trimOutboxLog() {
+	oldest := db.outboxSeq
+	for _, seq := range db.streamTokens {
+		if seq < oldest {
+			oldest = seq
+		}
+	}
+	for position := range db.watchers {
+		if *position < oldest {
+			oldest = *position
+		}
+	}
+
+	keep := 0
+	for keep < len(db.outboxLog) && db.outboxLog[keep].seq <= oldest {
+		keep++
+	}
+	db.outboxLog = append(db.outboxLog[:0], db.outboxLog[keep:]...)
+}
+
+func copyMessage(msg *models.Message) *models.Message {
+	c := *msg
+	if msg.RetryTier != nil {
+		tier := *msg.RetryTier
+		c.RetryTier = &tier
+	}
+	if msg.NextRetryAt != nil {
+		next := *msg.NextRetryAt
+		c.NextRetryAt = &next
+	}
+	return &c
+}
+
+func copyOutboxEvent(event *models.OutboxEvent) *models.OutboxEvent {
+	c := *event
+	if event.Payload != nil {
+		c.Payload = append(event.Payload[:0:0], event.Payload...)
+	}
+	if event.ClaimExpiresAt != nil {
+		expiresAt := *event.ClaimExpiresAt
+		c.ClaimExpiresAt = &expiresAt
+	}
+	return &c
+}
+
+func copyRecurringMessage(recurring *models.RecurringMessage) *models.RecurringMessage {
+	c := *recurring
+	if recurring.LastRunAt != nil {
+		lastRunAt := *recurring.LastRunAt
+		c.LastRunAt = &lastRunAt
+	}
+	return &c
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
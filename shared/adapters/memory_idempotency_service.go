@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/redis/interfaces"
+)
+
+type memoryIdempotencyEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// memoryIdempotencyService keeps the keys the Redis adapter writes in a map
+// with the same expirations. Expired keys are dropped when they are read and
+// on every write, so the map does not grow without bound.
+type memoryIdempotencyService struct {
+	mu      sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+	now     func() time.Time
+}
+
+func NewMemoryIdempotencyService() interfaces.IdempotencyServicePort {
+	return &memoryIdempotencyService{
+		entries: make(map[string]memoryIdempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryIdempotencyService) IsProcessed(ctx context.Context, messageID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := "inbox:" + messageID
+	entry, ok := s.entries[key]
+	if !ok {
+		return false, nil
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return false, nil
+	}
+	return entry.value == "processed", nil
+}
+
+func (s *memoryIdempotencyService) MarkAsProcessed(ctx context.Context, messageID string) error {
+	s.set("inbox:"+messageID, "processed", 24*time.Hour)
+	return nil
+}
+
+func (s *memoryIdempotencyService) StoreWebhookMessageID(ctx context.Context, messageID string, webhookMessageID string, expiration time.Duration) error {
+	s.set("webhook:msg:"+messageID, webhookMessageID, expiration)
+	return nil
+}
+
+func (s *memoryIdempotencyService) Claim(ctx context.Context, messageID string, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := "inbox:" + messageID
+	if entry, ok := s.entries[key]; ok && s.now().Before(entry.expiresAt) {
+		return false, nil
+	}
+	s.entries[key] = memoryIdempotencyEntry{value: "processing:" + owner, expiresAt: s.now().Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryIdempotencyService) Release(ctx context.Context, messageID string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := "inbox:" + messageID
+	if entry, ok := s.entries[key]; ok && entry.value == "processing:"+owner {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyService) set(key string, value string, expiration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryIdempotencyEntry{value: value, expiresAt: now.Add(expiration)}
+}
@@ -0,0 +1,62 @@
+package adapters
+
+import (
+	"context"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+)
+
+type memoryLeaseStore struct {
+	db *MemoryDatabase
+}
+
+func NewMemoryLeaseStore(db *MemoryDatabase) interfaces.LeaseStore {
+	return &memoryLeaseStore{db: db}
+}
+
+func (s *memoryLeaseStore) Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (*models.Lease, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	now := time.Now()
+	lease, ok := s.db.leases[name]
+	switch {
+	case ok && lease.Holder == holder && lease.ExpiresAt.After(now):
+		lease.ExpiresAt = now.Add(ttl)
+	case !ok:
+		lease = &models.Lease{Name: name, Holder: holder, Token: 1, ExpiresAt: now.Add(ttl), AcquiredAt: now}
+		s.db.leases[name] = lease
+	case !lease.ExpiresAt.After(now):
+		// Taking over a free or expired lease bumps the fencing token so work
+		// started under the previous holder can be told apart.
+		lease.Holder = holder
+		lease.Token++
+		lease.ExpiresAt = now.Add(ttl)
+		lease.AcquiredAt = now
+	default:
+		return nil, nil
+	}
+
+	acquired := *lease
+	return &acquired, nil
+}
+
+func (s *memoryLeaseStore) Release(ctx context.Context, name string, holder string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if lease, ok := s.db.leases[name]; ok && lease.Holder == holder {
+		lease.ExpiresAt = time.Now()
+	}
+	return nil
+}
+
+func (s *memoryLeaseStore) IsCurrent(ctx context.Context, name string, token int64) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	lease, ok := s.db.leases[name]
+	return ok && lease.Token == token && lease.ExpiresAt.After(time.Now()), nil
+}
@@ -0,0 +1,608 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"github.com/streadway/amqp"
+)
+
+// memoryMessageQueue keeps the queues of a topology in memory and behaves
+// like the broker for everything the RabbitMQ adapter relies on: the main
+// queue dead-letters rejected messages and, with drop-head, overflowing ones
+// into the retry queue; the retry queue and the backoff tier queues expire
+// messages back into the main queue; dead-lettering records x-death entries;
+// and consumers get at most prefetch unacknowledged deliveries.
+//
+// Like the broker, queues only expire messages at their head.
+type memoryMessageQueue struct {
+	topology contracts.Topology
+	since    time.Time
+
+	mu        sync.Mutex
+	queues    map[string]*memoryQueue
+	unacked   map[uint64]*memoryUnacked
+	nextTag   uint64
+	changed   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type memoryQueue struct {
+	name     string
+	exchange string
+	messages []*memoryMessage
+	// ttl is the queue's message TTL and deadLetterTo the queue expired and
+	// rejected messages move to; messages are dropped when it is empty.
+	ttl          time.Duration
+	deadLetterTo string
+	maxLength    int
+	overflow     string
+	consumers    int
+}
+
+type memoryMessage struct {
+	publishing  amqp.Publishing
+	expiresAt   time.Time
+	redelivered bool
+}
+
+type memoryConsumer struct {
+	queue      *memoryQueue
+	tag        string
+	prefetch   int
+	unacked    int
+	deliveries chan amqp.Delivery
+}
+
+type memoryUnacked struct {
+	consumer *memoryConsumer
+	message  *memoryMessage
+}
+
+func NewMemoryMessageQueue(topology contracts.Topology) (interfaces.MessageQueue, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid topology: %v", err)
+	}
+
+	mq := &memoryMessageQueue{
+		topology: topology,
+		since:    time.Now(),
+		queues:   make(map[string]*memoryQueue),
+		unacked:  make(map[uint64]*memoryUnacked),
+		changed:  make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+
+	mq.declare(&memoryQueue{
+		name:         topology.MainQueue(),
+		exchange:     topology.MainExchange(),
+		deadLetterTo: topology.RetryQueue(),
+		maxLength:    topology.MaxLength,
+		overflow:     topology.Overflow,
+	})
+	mq.declare(&memoryQueue{
+		name:         topology.RetryQueue(),
+		exchange:     topology.RetryExchange(),
+		ttl:          topology.RetryTTL,
+		deadLetterTo: topology.MainQueue(),
+	})
+	for tier := range topology.RetryBackoff {
+		mq.declare(&memoryQueue{
+			name:         topology.RetryTierQueue(tier),
+			exchange:     topology.RetryExchange(),
+			deadLetterTo: topology.MainQueue(),
+		})
+	}
+	mq.declare(&memoryQueue{
+		name:      topology.DLQQueue(),
+		ttl:       topology.DLQMessageTTL,
+		maxLength: topology.DLQMaxLength,
+		overflow:  contracts.OverflowDropHead,
+	})
+
+	go mq.expireLoop()
+	return mq, nil
+}
+
+func (mq *memoryMessageQueue) declare(q *memoryQueue) {
+	mq.queues[q.name] = q
+}
+
+// notify wakes the dispatchers and the expiry loop. The caller holds mu.
+func (mq *memoryMessageQueue) notify() {
+	close(mq.changed)
+	mq.changed = make(chan struct{})
+}
+
+func (mq *memoryMessageQueue) isClosed() bool {
+	select {
+	case <-mq.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueue appends msg to q, applying q's length limit. It returns
+// ErrPublishNacked when the limit rejects the message. The caller holds mu.
+func (mq *memoryMessageQueue) enqueue(q *memoryQueue, msg *memoryMessage, now time.Time) error {
+	msg.expiresAt = time.Time{}
+	if q.ttl > 0 {
+		msg.expiresAt = now.Add(q.ttl)
+	}
+	if ms, err := strconv.ParseInt(msg.publishing.Expiration, 10, 64); err == nil {
+		if expiresAt := now.Add(time.Duration(ms) * time.Millisecond); msg.expiresAt.IsZero() || expiresAt.Before(msg.expiresAt) {
+			msg.expiresAt = expiresAt
+		}
+	}
+
+	if q.maxLength > 0 && len(q.messages) >= q.maxLength {
+		switch q.overflow {
+		case contracts.OverflowRejectPublish:
+			return &interfaces.PublishError{Reason: interfaces.ErrPublishNacked}
+		case contracts.OverflowRejectPublishDLX:
+			mq.deadLetter(q, msg, "maxlen", now)
+			return &interfaces.PublishError{Reason: interfaces.ErrPublishNacked}
+		default:
+			head := q.messages[0]
+			q.messages = q.messages[1:]
+			mq.deadLetter(q, head, "maxlen", now)
+		}
+	}
+
+	q.messages = append(q.messages, msg)
+	mq.notify()
+	return nil
+}
+
+// deadLetter moves msg from q to q's dead-letter queue the way the broker
+// does: it records the death and drops the per-message expiration. The
+// caller holds mu.
+func (mq *memoryMessageQueue) deadLetter(q *memoryQueue, msg *memoryMessage, reason string, now time.Time) {
+	target, ok := mq.queues[q.deadLetterTo]
+	if !ok {
+		return
+	}
+
+	publishing := msg.publishing
+	publishing.Headers = recordDeath(msg.publishing.Headers, q.name, q.exchange, reason, msg.publishing.Expiration, now)
+	publishing.Expiration = ""
+	// A dead-lettered message the target queue cannot take is dropped.
+	_ = mq.enqueue(target, &memoryMessage{publishing: publishing}, now)
+}
+
+// recordDeath returns a copy of headers with the x-death entry and
+// x-first-death-* headers RabbitMQ adds when it dead-letters a message from
+// queue, for queues that emulate dead-lettering. Repeated deaths in the same
+// queue for the same reason increment the entry's count and move it to the
+// front.
+func recordDeath(headers amqp.Table, queue, exchange, reason, expiration string, now time.Time) amqp.Table {
+	recorded := amqp.Table{}
+	for k, v := range headers {
+		recorded[k] = v
+	}
+
+	death := amqp.Table{
+		"queue":        queue,
+		"reason":       reason,
+		"exchange":     exchange,
+		"count":        int64(1),
+		"time":         now,
+		"routing-keys": []interface{}{queue},
+	}
+	if expiration != "" {
+		death["original-expiration"] = expiration
+	}
+	deaths := []interface{}{death}
+	if existing, ok := recorded["x-death"].([]interface{}); ok {
+		for _, entry := range existing {
+			table, ok := entry.(amqp.Table)
+			if ok && table["queue"] == queue && table["reason"] == reason {
+				death["count"] = interfaces.ParseDeaths(amqp.Table{"x-death": []interface{}{table}})[0].Count + 1
+				continue
+			}
+			deaths = append(deaths, entry)
+		}
+	}
+	recorded["x-death"] = deaths
+	if _, ok := recorded["x-first-death-queue"]; !ok {
+		recorded["x-first-death-queue"] = queue
+		recorded["x-first-death-reason"] = reason
+		recorded["x-first-death-exchange"] = exchange
+	}
+	return recorded
+}
+
+// expire dead-letters or drops the expired messages at the head of every
+// queue and returns when the next head expires. A message dead-lettered into
+// a queue that was already visited can be its new head, so it repeats until
+// no message moves. The caller holds mu.
+func (mq *memoryMessageQueue) expire(now time.Time) time.Time {
+	for {
+		var next time.Time
+		moved := false
+		for _, q := range mq.queues {
+			for len(q.messages) > 0 {
+				head := q.messages[0]
+				if head.expiresAt.IsZero() {
+					break
+				}
+				if head.expiresAt.After(now) {
+					if next.IsZero() || head.expiresAt.Before(next) {
+						next = head.expiresAt
+					}
+					break
+				}
+				q.messages = q.messages[1:]
+				mq.deadLetter(q, head, "expired", now)
+				moved = true
+			}
+		}
+		if !moved {
+			return next
+		}
+	}
+}
+
+func (mq *memoryMessageQueue) expireLoop() {
+	for {
+		mq.mu.Lock()
+		next := mq.expire(time.Now())
+		changed := mq.changed
+		mq.mu.Unlock()
+
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			timeout = timer.C
+		}
+
+		select {
+		case <-mq.closed:
+		case <-changed:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if mq.isClosed() {
+			return
+		}
+	}
+}
+
+func (mq *memoryMessageQueue) publish(queueName string, publishing amqp.Publishing) error {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.isClosed() {
+		return interfaces.ErrNotConnected
+	}
+	q, ok := mq.queues[queueName]
+	if !ok {
+		return &interfaces.PublishError{Reason: interfaces.ErrPublishUnroutable, ReplyCode: amqp.NoRoute, ReplyText: "NO_ROUTE"}
+	}
+	return mq.enqueue(q, &memoryMessage{publishing: publishing}, time.Now())
+}
+
+func (mq *memoryMessageQueue) PublishMessage(ctx context.Context, msg contracts.QueueMessage) error {
+	publishing, err := newPublishing(msg, mq.topology)
+	if err != nil {
+		return err
+	}
+	return mq.publish(mq.topology.MainQueue(), publishing)
+}
+
+type memoryPublisher struct {
+	mq *memoryMessageQueue
+}
+
+func (mq *memoryMessageQueue) NewPublisher() (interfaces.Publisher, error) {
+	if mq.isClosed() {
+		return nil, interfaces.ErrNotConnected
+	}
+	return &memoryPublisher{mq: mq}, nil
+}
+
+func (p *memoryPublisher) PublishMessage(ctx context.Context, msg contracts.QueueMessage) error {
+	return p.mq.PublishMessage(ctx, msg)
+}
+
+func (p *memoryPublisher) Close() {}
+
+func (mq *memoryMessageQueue) ConsumeMessages(queueName string, prefetch int) (<-chan amqp.Delivery, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.isClosed() {
+		return nil, interfaces.ErrNotConnected
+	}
+	if prefetch < 0 {
+		return nil, fmt.Errorf("prefetch must not be negative, got %d", prefetch)
+	}
+	q, ok := mq.queues[queueName]
+	if !ok {
+		return nil, fmt.Errorf("failed to register a consumer: no queue '%s'", queueName)
+	}
+
+	q.consumers++
+	c := &memoryConsumer{
+		queue:      q,
+		tag:        fmt.Sprintf("memory-consumer-%d", q.consumers),
+		prefetch:   prefetch,
+		deliveries: make(chan amqp.Delivery),
+	}
+	go mq.dispatch(c)
+	return c.deliveries, nil
+}
+
+// dispatch hands c the messages at the head of its queue while it has
+// prefetch to spare. It is the only sender on c.deliveries and closes it when
+// the queue is closed.
+func (mq *memoryMessageQueue) dispatch(c *memoryConsumer) {
+	defer close(c.deliveries)
+
+	for {
+		mq.mu.Lock()
+		var delivery *amqp.Delivery
+		if len(c.queue.messages) > 0 && (c.prefetch == 0 || c.unacked < c.prefetch) {
+			msg := c.queue.messages[0]
+			c.queue.messages = c.queue.messages[1:]
+			mq.nextTag++
+			mq.unacked[mq.nextTag] = &memoryUnacked{consumer: c, message: msg}
+			c.unacked++
+			d := mq.delivery(c, mq.nextTag, msg)
+			delivery = &d
+		}
+		changed := mq.changed
+		mq.mu.Unlock()
+
+		if delivery == nil {
+			select {
+			case <-mq.closed:
+				return
+			case <-changed:
+			}
+			continue
+		}
+
+		select {
+		case <-mq.closed:
+			return
+		case c.deliveries <- *delivery:
+		}
+	}
+}
+
+func (mq *memoryMessageQueue) delivery(c *memoryConsumer, tag uint64, msg *memoryMessage) amqp.Delivery {
+	headers := amqp.Table{}
+	for k, v := range msg.publishing.Headers {
+		headers[k] = v
+	}
+
+	return amqp.Delivery{
+		Acknowledger:  mq,
+		Headers:       headers,
+		ContentType:   msg.publishing.ContentType,
+		DeliveryMode:  msg.publishing.DeliveryMode,
+		Expiration:    msg.publishing.Expiration,
+		MessageId:     msg.publishing.MessageId,
+		CorrelationId: msg.publishing.CorrelationId,
+		AppId:         msg.publishing.AppId,
+		Type:          msg.publishing.Type,
+		Timestamp:     msg.publishing.Timestamp,
+		ConsumerTag:   c.tag,
+		DeliveryTag:   tag,
+		Redelivered:   msg.redelivered,
+		Exchange:      c.queue.exchange,
+		RoutingKey:    c.queue.name,
+		Body:          msg.publishing.Body,
+	}
+}
+
+// settle removes the deliveries that tag and multiple select from the
+// unacked set and returns them oldest first. The caller holds mu.
+func (mq *memoryMessageQueue) settle(tag uint64, multiple bool) ([]*memoryUnacked, error) {
+	settled, ok := mq.unacked[tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown delivery tag %d", tag)
+	}
+
+	tags := []uint64{tag}
+	if multiple {
+		tags = tags[:0]
+		for t := uint64(1); t <= tag; t++ {
+			if u, ok := mq.unacked[t]; ok && u.consumer == settled.consumer {
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	deliveries := make([]*memoryUnacked, 0, len(tags))
+	for _, t := range tags {
+		u := mq.unacked[t]
+		delete(mq.unacked, t)
+		u.consumer.unacked--
+		deliveries = append(deliveries, u)
+	}
+	mq.notify()
+	return deliveries, nil
+}
+
+func (mq *memoryMessageQueue) Ack(tag uint64, multiple bool) error {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	_, err := mq.settle(tag, multiple)
+	return err
+}
+
+// Nack returns requeued messages to the head of their queue in their
+// original order, and dead-letters the others.
+func (mq *memoryMessageQueue) Nack(tag uint64, multiple bool, requeue bool) error {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	settled, err := mq.settle(tag, multiple)
+	if err != nil {
+		return err
+	}
+
+	if !requeue {
+		now := time.Now()
+		for _, u := range settled {
+			mq.deadLetter(u.consumer.queue, u.message, "rejected", now)
+		}
+		return nil
+	}
+
+	for i := len(settled) - 1; i >= 0; i-- {
+		q := settled[i].consumer.queue
+		settled[i].message.redelivered = true
+		q.messages = append([]*memoryMessage{settled[i].message}, q.messages...)
+	}
+	return nil
+}
+
+func (mq *memoryMessageQueue) Reject(tag uint64, requeue bool) error {
+	return mq.Nack(tag, false, requeue)
+}
+
+func (mq *memoryMessageQueue) MoveToDeadLetter(msg *amqp.Delivery, failure interfaces.Failure) error {
+	return mq.publish(mq.topology.DLQQueue(), deadLetterPublishing(msg, failure, mq.topology, time.Now()))
+}
+
+func (mq *memoryMessageQueue) MoveToRetryQueue(msg *amqp.Delivery, tier int, delay time.Duration) error {
+	tier, publishing := retryPublishing(msg, tier, delay, mq.topology, time.Now())
+	return mq.publish(mq.topology.RetryTierQueue(tier), publishing)
+}
+
+// dlq expires the DLQ's head and returns the DLQ. The caller holds mu.
+func (mq *memoryMessageQueue) dlq() (*memoryQueue, error) {
+	if mq.isClosed() {
+		return nil, interfaces.ErrNotConnected
+	}
+	mq.expire(time.Now())
+	return mq.queues[mq.topology.DLQQueue()], nil
+}
+
+func (mq *memoryMessageQueue) GetDLQMessageCount() (int, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	q, err := mq.dlq()
+	if err != nil {
+		return 0, err
+	}
+	return len(q.messages), nil
+}
+
+func (mq *memoryMessageQueue) BrowseDLQ(offset, limit int) ([]interfaces.DeadLetter, int, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	q, err := mq.dlq()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var messages []interfaces.DeadLetter
+	for i := offset; i < offset+limit && i < len(q.messages); i++ {
+		messages = append(messages, deadLetter(mq.delivery(&memoryConsumer{queue: q}, 0, q.messages[i])))
+	}
+	return messages, len(q.messages), nil
+}
+
+// ReplayDLQ passes each message that was in the DLQ when the replay started
+// to replay. A replayed message is removed from the DLQ only after its
+// replacement has been published.
+func (mq *memoryMessageQueue) ReplayDLQ(ctx context.Context, replay interfaces.ReplayFunc) (int, error) {
+	mq.mu.Lock()
+	q, err := mq.dlq()
+	if err != nil {
+		mq.mu.Unlock()
+		return 0, err
+	}
+	snapshot := append([]*memoryMessage(nil), q.messages...)
+	mq.mu.Unlock()
+
+	replayed := 0
+	for _, dead := range snapshot {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		mq.mu.Lock()
+		letter := deadLetter(mq.delivery(&memoryConsumer{queue: q}, 0, dead))
+		mq.mu.Unlock()
+
+		msg, err := replay(letter)
+		if err != nil {
+			return replayed, err
+		}
+		if msg == nil {
+			continue
+		}
+
+		if err := mq.PublishMessage(ctx, *msg); err != nil {
+			return replayed, fmt.Errorf("failed to replay message %s: %v", msg.ID, err)
+		}
+
+		mq.mu.Lock()
+		for i, m := range q.messages {
+			if m == dead {
+				q.messages = append(q.messages[:i], q.messages[i+1:]...)
+				break
+			}
+		}
+		mq.mu.Unlock()
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (mq *memoryMessageQueue) PurgeDLQ() (int, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	q, err := mq.dlq()
+	if err != nil {
+		return 0, err
+	}
+	purged := len(q.messages)
+	q.messages = nil
+	return purged, nil
+}
+
+func (mq *memoryMessageQueue) GetQueueStats(queueName string) (interfaces.QueueStats, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.isClosed() {
+		return interfaces.QueueStats{}, interfaces.ErrNotConnected
+	}
+	q, ok := mq.queues[queueName]
+	if !ok {
+		return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: no queue '%s'", queueName, queueName)
+	}
+	mq.expire(time.Now())
+	return interfaces.QueueStats{Messages: len(q.messages), Consumers: q.consumers}, nil
+}
+
+func (mq *memoryMessageQueue) ConnectionState() interfaces.ConnectionState {
+	return interfaces.ConnectionState{Connected: !mq.isClosed(), Since: mq.since}
+}
+
+// Close stops the consumers and closes their delivery channels. Unacked
+// deliveries can no longer be settled.
+func (mq *memoryMessageQueue) Close() {
+	mq.closeOnce.Do(func() { close(mq.closed) })
+}
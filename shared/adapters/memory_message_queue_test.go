@@ -0,0 +1,68 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryQueue(t *testing.T, topology contracts.Topology) interfaces.MessageQueue {
+	mq, err := NewMemoryMessageQueue(topology)
+	require.NoError(t, err)
+	t.Cleanup(mq.Close)
+	return mq
+}
+
+func TestMemoryMessageQueue(t *testing.T) {
+	testMessageQueueBehaviour(t, newTestMemoryQueue)
+}
+
+func TestMemoryMessageQueue_AckTwiceFails(t *testing.T) {
+	topology := testTopology()
+	mq := newTestMemoryQueue(t, topology)
+	require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+
+	deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 1)
+	require.NoError(t, err)
+
+	d := receive(t, deliveries)
+	require.NoError(t, d.Ack(false))
+	assert.Error(t, d.Ack(false), "a delivery can only be acked once")
+}
+
+func TestMemoryMessageQueue_DropHeadDeadLettersTheOldestMessage(t *testing.T) {
+	topology := testTopology()
+	topology.MaxLength = 1
+	mq := newTestMemoryQueue(t, topology)
+
+	require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+	require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m2"}))
+
+	stats, err := mq.GetQueueStats(topology.RetryQueue())
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Messages)
+}
+
+func TestMemoryMessageQueue_CloseEndsConsumers(t *testing.T) {
+	topology := testTopology()
+	mq, err := NewMemoryMessageQueue(topology)
+	require.NoError(t, err)
+
+	deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+	require.NoError(t, err)
+
+	mq.Close()
+
+	select {
+	case _, ok := <-deliveries:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("delivery channel was not closed")
+	}
+}
@@ -0,0 +1,191 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryMessageRepository struct {
+	db *MemoryDatabase
+}
+
+func NewMemoryMessageRepository(db *MemoryDatabase) interfaces.MessageRepository {
+	return &memoryMessageRepository{db: db}
+}
+
+func (r *memoryMessageRepository) FindUnsentMessages(ctx context.Context, limit int) ([]models.Message, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var messages []models.Message
+	for _, id := range r.db.messageOrder {
+		if limit > 0 && len(messages) == limit {
+			break
+		}
+		if msg := r.db.messages[id]; msg.Status == models.StatusUnsent {
+			messages = append(messages, *copyMessage(msg))
+		}
+	}
+	return messages, nil
+}
+
+func (r *memoryMessageRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status models.MessageStatus) error {
+	r.update(id, func(msg *models.Message) bool {
+		msg.Status = status
+		return true
+	})
+	return nil
+}
+
+func (r *memoryMessageRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (bool, error) {
+	updated := r.update(id, func(msg *models.Message) bool {
+		if msg.Status != from {
+			return false
+		}
+		msg.Status = to
+		return true
+	})
+	return updated, nil
+}
+
+func (r *memoryMessageRepository) TransitionStatuses(ctx context.Context, ids []primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (int, error) {
+	updated := 0
+	for _, id := range ids {
+		if ok, _ := r.TransitionStatus(ctx, id, from, to); ok {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r *memoryMessageRepository) IncrementRetryCount(ctx context.Context, id primitive.ObjectID) error {
+	r.update(id, func(msg *models.Message) bool {
+		msg.RetryCount++
+		return true
+	})
+	return nil
+}
+
+func (r *memoryMessageRepository) ScheduleRetry(ctx context.Context, id primitive.ObjectID, tier int, nextRetryAt time.Time) error {
+	r.update(id, func(msg *models.Message) bool {
+		msg.RetryTier = &tier
+		msg.NextRetryAt = &nextRetryAt
+		return true
+	})
+	return nil
+}
+
+func (r *memoryMessageRepository) ResetRetries(ctx context.Context, id primitive.ObjectID) error {
+	r.update(id, func(msg *models.Message) bool {
+		msg.Status = models.StatusProcessing
+		msg.RetryCount = 0
+		msg.RetryTier = nil
+		msg.NextRetryAt = nil
+		return true
+	})
+	return nil
+}
+
+// update applies change to the stored message and sets updated_at if change
+// reports that it modified the message. It reports whether it did.
+func (r *memoryMessageRepository) update(id primitive.ObjectID, change func(msg *models.Message) bool) bool {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	msg, ok := r.db.messages[id]
+	if !ok || !change(msg) {
+		return false
+	}
+	msg.UpdatedAt = time.Now()
+	return true
+}
+
+func (r *memoryMessageRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	msg, ok := r.db.messages[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyMessage(msg), nil
+}
+
+func (r *memoryMessageRepository) CreateMessage(ctx context.Context, msg *models.Message) error {
+	return r.CreateMessages(ctx, []*models.Message{msg})
+}
+
+func (r *memoryMessageRepository) CreateMessages(ctx context.Context, msgs []*models.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	events := make([]*models.OutboxEvent, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.ID.IsZero() {
+			msg.ID = primitive.NewObjectID()
+		}
+		event, err := models.NewMessageCreatedEvent(msg)
+		if err != nil {
+			return fmt.Errorf("failed to build outbox event: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	seen := make(map[primitive.ObjectID]bool, len(msgs))
+	for _, msg := range msgs {
+		if _, exists := r.db.messages[msg.ID]; exists || seen[msg.ID] {
+			return fmt.Errorf("duplicate message ID %s", msg.ID.Hex())
+		}
+		if _, exists := r.db.outbox[msg.ID]; exists {
+			return fmt.Errorf("duplicate outbox event ID %s", msg.ID.Hex())
+		}
+		seen[msg.ID] = true
+	}
+
+	for i, msg := range msgs {
+		r.db.insertMessage(msg)
+		r.db.insertOutboxEvent(events[i])
+	}
+	return nil
+}
+
+func (r *memoryMessageRepository) ListMessages(ctx context.Context) ([]models.Message, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	messages := make([]models.Message, 0, len(r.db.messageOrder))
+	for _, id := range r.db.messageOrder {
+		messages = append(messages, *copyMessage(r.db.messages[id]))
+	}
+	return messages, nil
+}
+
+func (r *memoryMessageRepository) FindStaleProcessingMessages(ctx context.Context, staleDuration time.Duration) ([]models.Message, error) {
+	staleTime := time.Now().Add(-staleDuration)
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var messages []models.Message
+	for _, id := range r.db.messageOrder {
+		msg := r.db.messages[id]
+		if msg.Status != models.StatusProcessing || !msg.UpdatedAt.Before(staleTime) {
+			continue
+		}
+		if msg.NextRetryAt != nil && !msg.NextRetryAt.Before(staleTime) {
+			continue
+		}
+		messages = append(messages, *copyMessage(msg))
+	}
+	return messages, nil
+}
@@ -0,0 +1,189 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryOutboxRepository struct {
+	db *MemoryDatabase
+}
+
+func NewMemoryOutboxRepository(db *MemoryDatabase) interfaces.OutboxRepository {
+	return &memoryOutboxRepository{db: db}
+}
+
+func claimable(event *models.OutboxEvent, types []string, now time.Time) bool {
+	if event.ClaimExpiresAt != nil && event.ClaimExpiresAt.After(now) {
+		return false
+	}
+	for _, t := range types {
+		if event.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryOutboxRepository) ClaimEvents(ctx context.Context, types []string, owner string, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	events := make([]models.OutboxEvent, 0, limit)
+	for _, event := range r.db.sortedOutboxEvents() {
+		if len(events) == limit {
+			break
+		}
+		if !claimable(event, types, now) {
+			continue
+		}
+		event.ClaimOwner = owner
+		event.ClaimExpiresAt = timePtr(now.Add(lease))
+		events = append(events, *copyOutboxEvent(event))
+	}
+	return events, nil
+}
+
+func (r *memoryOutboxRepository) ClaimEvent(ctx context.Context, id primitive.ObjectID, types []string, owner string, lease time.Duration) (*models.OutboxEvent, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	event, ok := r.db.outbox[id]
+	if !ok || !claimable(event, types, now) {
+		return nil, nil
+	}
+	event.ClaimOwner = owner
+	event.ClaimExpiresAt = timePtr(now.Add(lease))
+	return copyOutboxEvent(event), nil
+}
+
+func (r *memoryOutboxRepository) CompleteEvent(ctx context.Context, id primitive.ObjectID, owner string) error {
+	if deleted, _ := r.CompleteEvents(ctx, []primitive.ObjectID{id}, owner); deleted == 0 {
+		return interfaces.ErrClaimNotHeld
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) CompleteEvents(ctx context.Context, ids []primitive.ObjectID, owner string) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	deleted := 0
+	for _, id := range ids {
+		if event, ok := r.db.outbox[id]; ok && event.ClaimOwner == owner {
+			delete(r.db.outbox, id)
+			delete(r.db.outboxOrder, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *memoryOutboxRepository) ReleaseEvent(ctx context.Context, id primitive.ObjectID, owner string, lastErr string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if event, ok := r.db.outbox[id]; ok && event.ClaimOwner == owner {
+		event.ClaimOwner = ""
+		event.ClaimExpiresAt = nil
+		event.LastError = lastErr
+		event.Attempts++
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) EnqueueUnsentMessages(ctx context.Context) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	enqueued := 0
+	for _, id := range r.db.messageOrder {
+		msg := r.db.messages[id]
+		if msg.Status != models.StatusUnsent {
+			continue
+		}
+		// The event ID is the message ID, so a message that already has a
+		// pending event is skipped.
+		if _, exists := r.db.outbox[msg.ID]; exists {
+			continue
+		}
+
+		event, err := models.NewMessageCreatedEvent(msg)
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to build outbox event: %v", err)
+		}
+		r.db.insertOutboxEvent(event)
+		enqueued++
+	}
+	return enqueued, nil
+}
+
+func (r *memoryOutboxRepository) OldestEvent(ctx context.Context) (*models.OutboxEvent, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	events := r.db.sortedOutboxEvents()
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return copyOutboxEvent(events[0]), nil
+}
+
+func (r *memoryOutboxRepository) WatchInserts(ctx context.Context, stream string, handle func(ctx context.Context, id primitive.ObjectID) error) error {
+	r.db.mu.Lock()
+	position, ok := r.db.streamTokens[stream]
+	if !ok {
+		// Like a change stream without a resume token, start from now.
+		position = r.db.outboxSeq
+	}
+	r.db.watchers[&position] = struct{}{}
+	r.db.mu.Unlock()
+
+	defer func() {
+		r.db.mu.Lock()
+		delete(r.db.watchers, &position)
+		r.db.trimOutboxLog()
+		r.db.mu.Unlock()
+	}()
+
+	for {
+		r.db.mu.Lock()
+		var next *outboxInsert
+		for i := range r.db.outboxLog {
+			if r.db.outboxLog[i].seq > position {
+				insert := r.db.outboxLog[i]
+				next = &insert
+				break
+			}
+		}
+		changed := r.db.outboxChanged
+		r.db.mu.Unlock()
+
+		if next == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-changed:
+			}
+			continue
+		}
+
+		if err := handle(ctx, next.id); err != nil {
+			return err
+		}
+
+		r.db.mu.Lock()
+		position = next.seq
+		r.db.streamTokens[stream] = position
+		r.db.trimOutboxLog()
+		r.db.mu.Unlock()
+	}
+}
@@ -0,0 +1,148 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRecurringMessageRepository struct {
+	db *MemoryDatabase
+}
+
+func NewMemoryRecurringMessageRepository(db *MemoryDatabase) interfaces.RecurringMessageRepository {
+	return &memoryRecurringMessageRepository{db: db}
+}
+
+func (r *memoryRecurringMessageRepository) Create(ctx context.Context, recurring *models.RecurringMessage) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if recurring.ID.IsZero() {
+		recurring.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.db.recurring[recurring.ID]; exists {
+		return fmt.Errorf("duplicate recurring message ID %s", recurring.ID.Hex())
+	}
+	r.db.recurring[recurring.ID] = copyRecurringMessage(recurring)
+	return nil
+}
+
+// sorted returns copies of the definitions accepted by keep, ordered by less.
+// The caller holds mu.
+func (r *memoryRecurringMessageRepository) sorted(keep func(*models.RecurringMessage) bool, less func(a, b *models.RecurringMessage) bool) []models.RecurringMessage {
+	matched := []*models.RecurringMessage{}
+	for _, recurring := range r.db.recurring {
+		if keep(recurring) {
+			matched = append(matched, recurring)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(matched[i], matched[j])
+	})
+
+	result := make([]models.RecurringMessage, 0, len(matched))
+	for _, recurring := range matched {
+		result = append(result, *copyRecurringMessage(recurring))
+	}
+	return result
+}
+
+func (r *memoryRecurringMessageRepository) List(ctx context.Context) ([]models.RecurringMessage, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.sorted(
+		func(*models.RecurringMessage) bool { return true },
+		func(a, b *models.RecurringMessage) bool { return a.CreatedAt.Before(b.CreatedAt) },
+	), nil
+}
+
+func (r *memoryRecurringMessageRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.RecurringMessage, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	recurring, ok := r.db.recurring[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyRecurringMessage(recurring), nil
+}
+
+func (r *memoryRecurringMessageRepository) SetPaused(ctx context.Context, id primitive.ObjectID, paused bool, nextRunAt time.Time) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	recurring, ok := r.db.recurring[id]
+	if !ok {
+		return false, nil
+	}
+	recurring.Paused = paused
+	recurring.NextRunAt = nextRunAt
+	recurring.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (r *memoryRecurringMessageRepository) Delete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.recurring[id]; !ok {
+		return false, nil
+	}
+	delete(r.db.recurring, id)
+	return true, nil
+}
+
+func (r *memoryRecurringMessageRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]models.RecurringMessage, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	due := r.sorted(
+		func(recurring *models.RecurringMessage) bool {
+			return !recurring.Paused && !recurring.NextRunAt.After(now)
+		},
+		func(a, b *models.RecurringMessage) bool { return a.NextRunAt.Before(b.NextRunAt) },
+	)
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *memoryRecurringMessageRepository) RecordOccurrence(ctx context.Context, id primitive.ObjectID, occurrence time.Time, next time.Time, msg *models.Message) (bool, error) {
+	if msg.ID.IsZero() {
+		msg.ID = primitive.NewObjectID()
+	}
+	event, err := models.NewMessageCreatedEvent(msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to build outbox event: %v", err)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	recurring, ok := r.db.recurring[id]
+	if !ok || recurring.Paused || !recurring.NextRunAt.Equal(occurrence) {
+		return false, nil
+	}
+	if _, exists := r.db.messages[msg.ID]; exists {
+		return false, fmt.Errorf("duplicate message ID %s", msg.ID.Hex())
+	}
+	if _, exists := r.db.outbox[msg.ID]; exists {
+		return false, fmt.Errorf("duplicate outbox event ID %s", msg.ID.Hex())
+	}
+
+	recurring.NextRunAt = next
+	recurring.LastRunAt = timePtr(occurrence)
+	recurring.UpdatedAt = time.Now()
+	r.db.insertMessage(msg)
+	r.db.insertOutboxEvent(event)
+	return true, nil
+}
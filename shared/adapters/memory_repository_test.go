@@ -0,0 +1,170 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newUnsentMessage(content string) *models.Message {
+	now := time.Now()
+	return &models.Message{To: "+905321234567", Content: content, Status: models.StatusUnsent, CreatedAt: now, UpdatedAt: now}
+}
+
+func TestMemoryMessageRepository_CreateMessagesWritesOutboxEvents(t *testing.T) {
+	db := NewMemoryDatabase()
+	messages := NewMemoryMessageRepository(db)
+	outbox := NewMemoryOutboxRepository(db)
+	ctx := context.Background()
+
+	first, second := newUnsentMessage("first"), newUnsentMessage("second")
+	require.NoError(t, messages.CreateMessages(ctx, []*models.Message{first, second}))
+	assert.False(t, first.ID.IsZero())
+
+	err := messages.CreateMessage(ctx, first)
+	assert.Error(t, err, "duplicate IDs are rejected")
+
+	unsent, err := messages.FindUnsentMessages(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, unsent, 1)
+	assert.Equal(t, first.ID, unsent[0].ID)
+
+	events, err := outbox.ClaimEvents(ctx, []string{models.EventMessageCreated}, "relay-1", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, first.ID, events[0].AggregateID)
+
+	again, err := outbox.ClaimEvents(ctx, []string{models.EventMessageCreated}, "relay-2", 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again, "claimed events are leased")
+
+	assert.ErrorIs(t, outbox.CompleteEvent(ctx, first.ID, "relay-2"), interfaces.ErrClaimNotHeld)
+	require.NoError(t, outbox.CompleteEvent(ctx, first.ID, "relay-1"))
+	require.NoError(t, outbox.ReleaseEvent(ctx, second.ID, "relay-1", "broker down"))
+
+	oldest, err := outbox.OldestEvent(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, oldest.ID)
+	assert.Equal(t, 1, oldest.Attempts)
+	assert.Equal(t, "broker down", oldest.LastError)
+}
+
+func TestMemoryMessageRepository_TransitionStatus(t *testing.T) {
+	db := NewMemoryDatabase()
+	messages := NewMemoryMessageRepository(db)
+	ctx := context.Background()
+
+	msg := newUnsentMessage("hello")
+	require.NoError(t, messages.CreateMessage(ctx, msg))
+
+	updated, err := messages.TransitionStatus(ctx, msg.ID, models.StatusUnsent, models.StatusProcessing)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	updated, err = messages.TransitionStatus(ctx, msg.ID, models.StatusUnsent, models.StatusProcessing)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	stored, err := messages.GetByID(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusProcessing, stored.Status)
+
+	missing, err := messages.GetByID(ctx, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMemoryOutboxRepository_WatchInsertsResumesAfterSavedPosition(t *testing.T) {
+	db := NewMemoryDatabase()
+	messages := NewMemoryMessageRepository(db)
+	outbox := NewMemoryOutboxRepository(db)
+
+	before := newUnsentMessage("before the watch")
+	require.NoError(t, messages.CreateMessage(context.Background(), before))
+
+	watch := func(ctx context.Context, seen chan<- primitive.ObjectID) chan error {
+		done := make(chan error, 1)
+		go func() {
+			done <- outbox.WatchInserts(ctx, "relay", func(ctx context.Context, id primitive.ObjectID) error {
+				seen <- id
+				return nil
+			})
+		}()
+		return done
+	}
+
+	seen := make(chan primitive.ObjectID, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := watch(ctx, seen)
+
+	first := newUnsentMessage("first")
+	require.Eventually(t, func() bool {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		return len(db.watchers) == 1
+	}, time.Second, time.Millisecond)
+	require.NoError(t, messages.CreateMessage(context.Background(), first))
+	assert.Equal(t, first.ID, <-seen, "a new stream starts from now")
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	second := newUnsentMessage("inserted while no one watched")
+	require.NoError(t, messages.CreateMessage(context.Background(), second))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	watch(ctx, seen)
+	assert.Equal(t, second.ID, <-seen, "a stream resumes after its saved position")
+}
+
+func TestMemoryLeaseStore_TakeoverBumpsToken(t *testing.T) {
+	store := NewMemoryLeaseStore(NewMemoryDatabase())
+	ctx := context.Background()
+
+	lease, err := store.Acquire(ctx, "scheduler", "a", 20*time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, lease)
+
+	other, err := store.Acquire(ctx, "scheduler", "b", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, other)
+
+	time.Sleep(30 * time.Millisecond)
+	other, err = store.Acquire(ctx, "scheduler", "b", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	assert.Equal(t, lease.Token+1, other.Token)
+
+	current, err := store.IsCurrent(ctx, "scheduler", lease.Token)
+	require.NoError(t, err)
+	assert.False(t, current)
+}
+
+func TestMemoryIdempotencyService_ExpiresKeys(t *testing.T) {
+	service := NewMemoryIdempotencyService().(*memoryIdempotencyService)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	processed, err := service.IsProcessed(ctx, "m1")
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	require.NoError(t, service.MarkAsProcessed(ctx, "m1"))
+	processed, err = service.IsProcessed(ctx, "m1")
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	now = now.Add(25 * time.Hour)
+	processed, err = service.IsProcessed(ctx, "m1")
+	require.NoError(t, err)
+	assert.False(t, processed)
+}
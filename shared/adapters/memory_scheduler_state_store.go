@@ -0,0 +1,39 @@
+package adapters
+
+import (
+	"context"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+)
+
+type memorySchedulerStateStore struct {
+	db *MemoryDatabase
+}
+
+func NewMemorySchedulerStateStore(db *MemoryDatabase) interfaces.SchedulerStateStore {
+	return &memorySchedulerStateStore{db: db}
+}
+
+func (s *memorySchedulerStateStore) LoadSchedulerState(ctx context.Context, name string) (*models.SchedulerState, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	state, ok := s.db.schedulerStates[name]
+	if !ok {
+		return nil, nil
+	}
+	loaded := *state
+	return &loaded, nil
+}
+
+func (s *memorySchedulerStateStore) SaveSchedulerState(ctx context.Context, state *models.SchedulerState) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	state.UpdatedAt = time.Now()
+	saved := *state
+	s.db.schedulerStates[state.Name] = &saved
+	return nil
+}
@@ -2,8 +2,10 @@ package adapters
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
@@ -12,102 +14,397 @@ import (
 	"github.com/streadway/amqp"
 )
 
+const (
+	publishConfirmTimeout  = 5 * time.Second
+	publishChannelPoolSize = 4
+	reconnectMinDelay      = time.Second
+	reconnectMaxDelay      = 30 * time.Second
+)
+
+// rabbitMQAdapter reconnects on its own when the connection or one of its
+// channels is closed. While it is disconnected, publishes and queue
+// operations fail with interfaces.ErrNotConnected, and consumers keep their
+// delivery channels, which resume once the consumers are re-registered.
 type rabbitMQAdapter struct {
-	conn    *amqp.Connection
+	url       string
+	topology  contracts.Topology
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// mu guards the session and the state below.
+	mu         sync.RWMutex
+	session    *session
+	consumers  []consumer
+	connected  bool
+	since      time.Time
+	reconnects int
+	lastError  string
+
+	// dlq serializes browsing and replaying the DLQ, which hold the messages
+	// they read unacked until they finish.
+	dlq sync.Mutex
+}
+
+// session is one connection and the channels opened on it. AMQP channels
+// are not safe for concurrent use, so each channel has a single role:
+// consume is only used while holding the adapter's mu, control is guarded
+// by its own mutex, and each publish channel is held by one publish at a
+// time through the publishers pool.
+type session struct {
+	conn       *amqp.Connection
+	consume    *amqp.Channel
+	control    *controlChannel
+	publishers chan *confirmPublisher
+}
+
+// controlChannel serializes topology declarations and queue inspections.
+type controlChannel struct {
+	mu      sync.Mutex
 	channel *amqp.Channel
 }
 
-func NewMessageQueue(url string) (interfaces.MessageQueue, error) {
-	conn, err := amqp.Dial(url)
+// consumer forwards deliveries from whichever channel is current to a
+// delivery channel that outlives reconnects.
+type consumer struct {
+	queue      string
+	prefetch   int
+	deliveries chan amqp.Delivery
+}
+
+// confirmPublisher owns a channel in confirm mode. publishMu keeps one
+// publish in flight so each confirm and return can be matched to the message
+// that caused it. draining is set while the confirm of an abandoned publish
+// is still outstanding.
+type confirmPublisher struct {
+	channel    *amqp.Channel
+	exchange   string
+	routingKey string
+	topology   contracts.Topology
+	confirms   chan amqp.Confirmation
+	returns    chan amqp.Return
+	publishMu  sync.Mutex
+	publishTag uint64
+	draining   <-chan struct{}
+}
+
+func NewMessageQueue(url string, topology contracts.Topology) (interfaces.MessageQueue, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RabbitMQ topology: %v", err)
+	}
+
+	mq := &rabbitMQAdapter{
+		url:      url,
+		topology: topology,
+		closed:   make(chan struct{}),
+	}
+
+	closed, err := mq.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	go mq.watch(closed)
+	return mq, nil
+}
+
+// connect dials RabbitMQ, declares the topology, opens the consume, control
+// and publish channels and re-registers existing consumers. It returns a
+// notification that fires when the connection or any of its channels closes.
+func (mq *rabbitMQAdapter) connect() (<-chan *amqp.Error, error) {
+	conn, err := amqp.Dial(mq.url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
 	}
 
-	ch, err := conn.Channel()
+	s, err := openSession(conn, mq.topology)
 	if err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("failed to open channel: %v", err)
+		return nil, err
 	}
 
-	mq := &rabbitMQAdapter{
-		conn:    conn,
-		channel: ch,
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	select {
+	case <-mq.closed:
+		conn.Close()
+		return nil, fmt.Errorf("message queue is closed")
+	default:
 	}
 
-	if err := mq.setupQueues(); err != nil {
-		mq.Close()
-		return nil, err
+	for _, c := range mq.consumers {
+		if err := mq.startConsumer(s.consume, c); err != nil {
+			conn.Close()
+			return nil, err
+		}
 	}
 
-	return mq, nil
+	mq.session = s
+	mq.connected = true
+	mq.since = time.Now()
+	mq.lastError = ""
+
+	return s.notifyClose(), nil
 }
 
-func (mq *rabbitMQAdapter) setupQueues() error {
-	err := mq.channel.ExchangeDeclare(
-		contracts.MainExchange, 
-		"direct",     
-		true,         
-		false,        
-		false,        
-		false,        
-		nil,          
-	)
+func openSession(conn *amqp.Connection, topology contracts.Topology) (*session, error) {
+	control, err := conn.Channel()
 	if err != nil {
-		return fmt.Errorf("failed to declare main exchange: %v", err)
+		return nil, fmt.Errorf("failed to open control channel: %v", err)
 	}
 
-	// Retry Exchange
-	err = mq.channel.ExchangeDeclare(
-		contracts.RetryExchange,
-		"direct",
-		true,
+	if err := setupQueues(control, topology); err != nil {
+		return nil, err
+	}
+
+	consume, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open consume channel: %v", err)
+	}
+
+	publishers := make(chan *confirmPublisher, publishChannelPoolSize)
+	for i := 0; i < publishChannelPoolSize; i++ {
+		publisher, err := newConfirmPublisher(conn, topology)
+		if err != nil {
+			return nil, err
+		}
+		publishers <- publisher
+	}
+
+	return &session{
+		conn:       conn,
+		consume:    consume,
+		control:    &controlChannel{channel: control},
+		publishers: publishers,
+	}, nil
+}
+
+// notifyClose merges the close notifications of the connection and every
+// channel of the session. Each notification fires exactly once, either with
+// the error or, on a clean close, by being closed, so the forwarders exit.
+func (s *session) notifyClose() <-chan *amqp.Error {
+	channels := []*amqp.Channel{s.consume, s.control.channel}
+	for i := 0; i < publishChannelPoolSize; i++ {
+		publisher := <-s.publishers
+		channels = append(channels, publisher.channel)
+		s.publishers <- publisher
+	}
+
+	closed := make(chan *amqp.Error, len(channels)+1)
+	forward := func(notify chan *amqp.Error) {
+		go func() { closed <- <-notify }()
+	}
+
+	forward(s.conn.NotifyClose(make(chan *amqp.Error, 1)))
+	for _, ch := range channels {
+		forward(ch.NotifyClose(make(chan *amqp.Error, 1)))
+	}
+	return closed
+}
+
+// acquire takes a publish channel from the pool; release must return it.
+func (s *session) acquire(ctx context.Context) (*confirmPublisher, error) {
+	select {
+	case publisher := <-s.publishers:
+		return publisher, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no publish channel available: %v", ctx.Err())
+	}
+}
+
+// release returns a publisher to the pool. A publisher that abandoned a
+// publish is returned once the late confirm has been drained.
+func (s *session) release(publisher *confirmPublisher) {
+	draining := publisher.draining
+	if draining == nil {
+		s.publishers <- publisher
+		return
+	}
+	go func() {
+		<-draining
+		s.publishers <- publisher
+	}()
+}
+
+func (c *controlChannel) inspect(queueName string) (amqp.Queue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.channel.QueueInspect(queueName)
+}
+
+func (c *controlChannel) purge(queueName string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.channel.QueuePurge(queueName, false)
+}
+
+// watch waits for the connection or one of its channels to close and
+// reconnects with exponential backoff until it succeeds or the adapter is
+// closed.
+func (mq *rabbitMQAdapter) watch(closed <-chan *amqp.Error) {
+	for {
+		var reason *amqp.Error
+		select {
+		case reason = <-closed:
+		case <-mq.closed:
+			return
+		}
+
+		select {
+		case <-mq.closed:
+			return
+		default:
+		}
+
+		mq.disconnected(reason)
+
+		delay := reconnectMinDelay
+		for {
+			select {
+			case <-time.After(delay):
+			case <-mq.closed:
+				return
+			}
+
+			var err error
+			closed, err = mq.connect()
+			if err == nil {
+				break
+			}
+
+			log.Printf("RabbitMQ reconnect failed, retrying in %s: %v", delay, err)
+			mq.mu.Lock()
+			mq.lastError = err.Error()
+			mq.mu.Unlock()
+
+			delay *= 2
+			if delay > reconnectMaxDelay {
+				delay = reconnectMaxDelay
+			}
+		}
+
+		mq.mu.Lock()
+		mq.reconnects++
+		mq.mu.Unlock()
+		log.Println("Reconnected to RabbitMQ")
+	}
+}
+
+func (mq *rabbitMQAdapter) disconnected(reason *amqp.Error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	mq.connected = false
+	mq.since = time.Now()
+	mq.lastError = "connection closed"
+	if reason != nil {
+		mq.lastError = reason.Error()
+	}
+	log.Printf("Lost RabbitMQ connection, reconnecting: %s", mq.lastError)
+
+	// A channel can close on its own, e.g. after a channel-level error;
+	// closing the connection makes the reconnect start from a clean state.
+	if mq.session != nil {
+		mq.session.conn.Close()
+	}
+}
+
+func (mq *rabbitMQAdapter) startConsumer(ch *amqp.Channel, c consumer) error {
+	// With global set to false the limit applies to each consumer started
+	// on the channel afterwards, so consumers can have their own prefetch.
+	if err := ch.Qos(c.prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch for %s: %v", c.queue, err)
+	}
+
+	deliveries, err := ch.Consume(
+		c.queue,
+		"",
+		false,
 		false,
 		false,
 		false,
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to declare retry exchange: %v", err)
+		return fmt.Errorf("failed to consume from %s: %v", c.queue, err)
 	}
 
-	// Main Queue
-	args := amqp.Table{
-		"x-dead-letter-exchange":    contracts.RetryExchange,
-		"x-dead-letter-routing-key": contracts.RetryQueueName,
-	}
-	_, err = mq.channel.QueueDeclare(
-		contracts.MainQueueName, 
-		true,          
-		false,         
-		false,         
-		false,         
-		args,          
-	)
+	go func() {
+		for delivery := range deliveries {
+			select {
+			case c.deliveries <- delivery:
+			case <-mq.closed:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// current returns the open session, or ErrNotConnected while reconnecting.
+func (mq *rabbitMQAdapter) current() (*session, error) {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	if !mq.connected {
+		return nil, interfaces.ErrNotConnected
+	}
+	return mq.session, nil
+}
+
+func (mq *rabbitMQAdapter) ConnectionState() interfaces.ConnectionState {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	return interfaces.ConnectionState{
+		Connected:  mq.connected,
+		Since:      mq.since,
+		Reconnects: mq.reconnects,
+		LastError:  mq.lastError,
+	}
+}
+
+func newConfirmPublisher(conn *amqp.Connection, topology contracts.Topology) (*confirmPublisher, error) {
+	ch, err := conn.Channel()
 	if err != nil {
-		return fmt.Errorf("failed to declare main queue: %v", err)
+		return nil, fmt.Errorf("failed to open confirm channel: %v", err)
 	}
 
-	// Retry Queue with TTL and dead-letter to main queue
-	retryArgs := amqp.Table{
-		"x-message-ttl":             10000, 
-		"x-dead-letter-exchange":    contracts.MainExchange,
-		"x-dead-letter-routing-key": contracts.MainQueueName,
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to put channel in confirm mode: %v", err)
 	}
-	_, err = mq.channel.QueueDeclare(
-		contracts.RetryQueueName,
+
+	return &confirmPublisher{
+		channel:    ch,
+		exchange:   topology.MainExchange(),
+		routingKey: topology.MainQueue(),
+		topology:   topology,
+		confirms:   ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+		returns:    ch.NotifyReturn(make(chan amqp.Return, 1)),
+	}, nil
+}
+
+func setupQueues(ch *amqp.Channel, topology contracts.Topology) error {
+	err := ch.ExchangeDeclare(
+		topology.MainExchange(),
+		"direct",
 		true,
 		false,
 		false,
 		false,
-		retryArgs,
+		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to declare retry queue: %v", err)
+		return declareError("main exchange", topology.MainExchange(), err)
 	}
 
-	// DLQ - Dead Letter Queue
-	_, err = mq.channel.QueueDeclare(
-		contracts.DLQQueueName,
+	// Retry Exchange
+	err = ch.ExchangeDeclare(
+		topology.RetryExchange(),
+		"direct",
 		true,
 		false,
 		false,
@@ -115,26 +412,76 @@ func (mq *rabbitMQAdapter) setupQueues() error {
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to declare DLQ: %v", err)
+		return declareError("retry exchange", topology.RetryExchange(), err)
+	}
+
+	// Main Queue
+	args := queueArgs(topology, amqp.Table{
+		"x-dead-letter-exchange":    topology.RetryExchange(),
+		"x-dead-letter-routing-key": topology.RetryQueue(),
+	})
+	if topology.MaxLength > 0 {
+		args["x-max-length"] = topology.MaxLength
+		args["x-overflow"] = topology.Overflow
+	}
+	if err := declareQueue(ch, "main queue", topology.MainQueue(), args); err != nil {
+		return err
+	}
+
+	// Retry Queue with TTL and dead-letter to main queue
+	retryArgs := queueArgs(topology, amqp.Table{
+		"x-message-ttl":             int(topology.RetryTTL.Milliseconds()),
+		"x-dead-letter-exchange":    topology.MainExchange(),
+		"x-dead-letter-routing-key": topology.MainQueue(),
+	})
+	if err := declareQueue(ch, "retry queue", topology.RetryQueue(), retryArgs); err != nil {
+		return err
+	}
+
+	// Backoff tier queues. Each message carries its own expiration, so a
+	// tier's delay can vary by the configured jitter.
+	for tier := range topology.RetryBackoff {
+		tierArgs := queueArgs(topology, amqp.Table{
+			"x-dead-letter-exchange":    topology.MainExchange(),
+			"x-dead-letter-routing-key": topology.MainQueue(),
+		})
+		if err := declareQueue(ch, "retry tier queue", topology.RetryTierQueue(tier), tierArgs); err != nil {
+			return err
+		}
+		err = ch.QueueBind(
+			topology.RetryTierQueue(tier),
+			topology.RetryTierQueue(tier),
+			topology.RetryExchange(),
+			false,
+			nil,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to bind retry tier queue: %v", err)
+		}
+	}
+
+	// DLQ - Dead Letter Queue
+	if err := declareQueue(ch, "DLQ", topology.DLQQueue(), dlqArgs(topology)); err != nil {
+		return err
 	}
 
 	// Bind queues to exchanges
-	err = mq.channel.QueueBind(
-		contracts.MainQueueName, 
-		contracts.MainQueueName, 
-		contracts.MainExchange, 
-		false, 
+	err = ch.QueueBind(
+		topology.MainQueue(),
+		topology.MainQueue(),
+		topology.MainExchange(),
+		false,
 		nil,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to bind main queue: %v", err)
 	}
 
-	err = mq.channel.QueueBind(
-		contracts.RetryQueueName, 
-		contracts.RetryQueueName, 
-		contracts.RetryExchange, 
-		false, 
+	err = ch.QueueBind(
+		topology.RetryQueue(),
+		topology.RetryQueue(),
+		topology.RetryExchange(),
+		false,
 		nil,
 	)
 	if err != nil {
@@ -144,91 +491,537 @@ func (mq *rabbitMQAdapter) setupQueues() error {
 	return nil
 }
 
-func (mq *rabbitMQAdapter) PublishMessage(ctx context.Context, msg contracts.QueueMessage) error {
-	body, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %v", err)
-	}
-
-	return mq.channel.Publish(
-		contracts.MainExchange,  
-		contracts.MainQueueName, 
-		false,         
-		false,         
-		amqp.Publishing{
-			DeliveryMode: amqp.Persistent,
-			ContentType:  "application/json",
-			Body:         body,
-			Timestamp:    time.Now(),
-		},
+// dlqArgs returns the arguments of the DLQ. It stays a classic queue with
+// quorum queues: browsing requeues its messages, and a quorum queue's
+// delivery limit would drop them after enough browses, as the DLQ has no
+// dead-letter exchange.
+func dlqArgs(topology contracts.Topology) amqp.Table {
+	args := amqp.Table{}
+	if topology.DLQMaxLength > 0 {
+		args["x-max-length"] = topology.DLQMaxLength
+	}
+	if topology.DLQMessageTTL > 0 {
+		args["x-message-ttl"] = int(topology.DLQMessageTTL.Milliseconds())
+	}
+	if topology.QueueType == contracts.QueueTypeQuorum {
+		topology.QueueType = contracts.QueueTypeClassic
+	}
+	return queueArgs(topology, args)
+}
+
+// queueArgs adds the arguments for the configured queue type. Classic
+// queues get none, so they match queues declared before the type was
+// configurable.
+func queueArgs(topology contracts.Topology, args amqp.Table) amqp.Table {
+	switch topology.QueueType {
+	case contracts.QueueTypeQuorum:
+		args["x-queue-type"] = string(contracts.QueueTypeQuorum)
+	case contracts.QueueTypeLazy:
+		args["x-queue-mode"] = "lazy"
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+func declareQueue(ch *amqp.Channel, kind, name string, args amqp.Table) error {
+	_, err := ch.QueueDeclare(
+		name,
+		true,
+		false,
+		false,
+		false,
+		args,
 	)
+	if err != nil {
+		return declareError(kind, name, err)
+	}
+	return nil
+}
+
+// declareError explains the precondition failure the broker returns when an
+// existing exchange or queue was declared with different arguments.
+func declareError(kind, name string, err error) error {
+	if amqpErr, ok := err.(*amqp.Error); ok && amqpErr.Code == amqp.PreconditionFailed {
+		return fmt.Errorf("%s %q already exists with incompatible arguments; delete it or change the topology configuration: %v", kind, name, err)
+	}
+	return fmt.Errorf("failed to declare %s: %v", kind, err)
+}
+
+// PublishMessage returns only after the broker has confirmed the message. A
+// nack or a mandatory return is reported as *interfaces.PublishError.
+// Concurrent calls are spread over the pool of publish channels.
+func (mq *rabbitMQAdapter) PublishMessage(ctx context.Context, msg contracts.QueueMessage) error {
+	s, err := mq.current()
+	if err != nil {
+		return err
+	}
+
+	publisher, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.release(publisher)
+
+	return publisher.PublishMessage(ctx, msg)
 }
 
-func (mq *rabbitMQAdapter) ConsumeMessages(queueName string) (<-chan amqp.Delivery, error) {
-	return mq.channel.Consume(
-		queueName, 
-		"",        
-		false,     
-		false,     
-		false,     
-		false,     
-		nil,       
+// NewPublisher opens a publisher with its own confirm channel, so several
+// goroutines can publish without waiting on each other's confirms.
+func (mq *rabbitMQAdapter) NewPublisher() (interfaces.Publisher, error) {
+	s, err := mq.current()
+	if err != nil {
+		return nil, err
+	}
+	return newConfirmPublisher(s.conn, mq.topology)
+}
+
+func (p *confirmPublisher) PublishMessage(ctx context.Context, msg contracts.QueueMessage) error {
+	publishing, err := newPublishing(msg, p.topology)
+	if err != nil {
+		return err
+	}
+	return p.publish(ctx, p.exchange, p.routingKey, publishing)
+}
+
+// publish publishes a mandatory message and waits for the broker to confirm
+// it. Returns are matched to the message by its message ID.
+func (p *confirmPublisher) publish(ctx context.Context, exchange, routingKey string, publishing amqp.Publishing) error {
+	p.publishMu.Lock()
+	defer p.publishMu.Unlock()
+
+	if p.draining != nil {
+		select {
+		case <-p.draining:
+			p.draining = nil
+		case <-ctx.Done():
+			return fmt.Errorf("publish channel is still waiting for a late confirm: %v", ctx.Err())
+		}
+	}
+
+	err := p.channel.Publish(
+		exchange,
+		routingKey,
+		true,
+		false,
+		publishing,
 	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %v", err)
+	}
+	p.publishTag++
+	tag := p.publishTag
+
+	timer := time.NewTimer(publishConfirmTimeout)
+	defer timer.Stop()
+
+	var returned *amqp.Return
+	for {
+		select {
+		case ret := <-p.returns:
+			if ret.MessageId == publishing.MessageId {
+				returned = &ret
+			}
+		case confirm, ok := <-p.confirms:
+			if !ok {
+				return fmt.Errorf("confirm channel closed before the broker confirmed the message")
+			}
+			if confirm.DeliveryTag < tag {
+				// Late confirm for a publish that already timed out.
+				continue
+			}
+			// The broker sends basic.return before the ack of an unroutable
+			// mandatory message, so the return is buffered by now.
+			select {
+			case ret := <-p.returns:
+				if ret.MessageId == publishing.MessageId {
+					returned = &ret
+				}
+			default:
+			}
+			if returned != nil {
+				return &interfaces.PublishError{
+					Reason:    interfaces.ErrPublishUnroutable,
+					ReplyCode: returned.ReplyCode,
+					ReplyText: returned.ReplyText,
+				}
+			}
+			if !confirm.Ack {
+				return &interfaces.PublishError{Reason: interfaces.ErrPublishNacked}
+			}
+			return nil
+		case <-timer.C:
+			p.draining = p.drain(tag)
+			return fmt.Errorf("timed out after %s waiting for publish confirm", publishConfirmTimeout)
+		case <-ctx.Done():
+			p.draining = p.drain(tag)
+			return fmt.Errorf("publish confirm not received: %v", ctx.Err())
+		}
+	}
+}
+
+// drain reads the confirm and returns of an abandoned publish in the
+// background. The client library delivers them from the connection's reader
+// goroutine, which blocks every channel until they are read. The returned
+// channel is closed once the confirm for tag has arrived or the channel has
+// closed.
+func (p *confirmPublisher) drain(tag uint64) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-p.returns:
+			case confirm, ok := <-p.confirms:
+				if !ok || confirm.DeliveryTag >= tag {
+					// A return precedes its confirm, so one may be buffered.
+					select {
+					case <-p.returns:
+					default:
+					}
+					return
+				}
+			}
+		}
+	}()
+	return done
+}
+
+// newPublishing encodes msg with the topology's content type. Messages without
+// a correlation ID are correlated by their own ID.
+func newPublishing(msg contracts.QueueMessage, topology contracts.Topology) (amqp.Publishing, error) {
+	now := time.Now()
+	body, err := contracts.EncodeQueueMessage(msg, topology.ContentType, now)
+	if err != nil {
+		return amqp.Publishing{}, fmt.Errorf("failed to marshal message: %v", err)
+	}
+
+	correlationID := msg.CorrelationID
+	if correlationID == "" {
+		correlationID = msg.ID
+	}
+
+	publishing := amqp.Publishing{
+		Headers:       amqp.Table{contracts.SchemaVersionHeader: contracts.CurrentSchemaVersion},
+		DeliveryMode:  amqp.Persistent,
+		ContentType:   topology.ContentType,
+		MessageId:     msg.ID,
+		CorrelationId: correlationID,
+		AppId:         contracts.AppID,
+		Type:          contracts.QueueMessageType,
+		Body:          body,
+		Timestamp:     now,
+	}
+	if topology.MessageTTL > 0 {
+		publishing.Expiration = strconv.FormatInt(topology.MessageTTL.Milliseconds(), 10)
+	}
+	return publishing, nil
+}
+
+// ConsumeMessages returns a delivery channel that stays open across
+// reconnects; the consumer is registered again on every new channel.
+// Deliveries from a channel that has since closed can no longer be acked and
+// are redelivered by the broker.
+func (mq *rabbitMQAdapter) ConsumeMessages(queueName string, prefetch int) (<-chan amqp.Delivery, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if !mq.connected {
+		return nil, interfaces.ErrNotConnected
+	}
+
+	if prefetch < 0 {
+		return nil, fmt.Errorf("prefetch must not be negative, got %d", prefetch)
+	}
+
+	c := consumer{queue: queueName, prefetch: prefetch, deliveries: make(chan amqp.Delivery)}
+	if err := mq.startConsumer(mq.session.consume, c); err != nil {
+		return nil, err
+	}
+	mq.consumers = append(mq.consumers, c)
+	return c.deliveries, nil
 }
 
-func (mq *rabbitMQAdapter) MoveToDeadLetter(msg *amqp.Delivery) error {
+// MoveToDeadLetter keeps the incoming headers, including the broker's
+// x-death entries, and adds the failure headers. Like moves to a retry
+// queue, it returns once the broker has confirmed the copy, so the caller
+// can ack the original.
+func (mq *rabbitMQAdapter) MoveToDeadLetter(msg *amqp.Delivery, failure interfaces.Failure) error {
+	return mq.publishConfirmed("", mq.topology.DLQQueue(), deadLetterPublishing(msg, failure, mq.topology, time.Now()))
+}
+
+// publishConfirmed publishes on a channel from the pool and waits for the
+// confirm. A nack or a mandatory return is reported as
+// *interfaces.PublishError.
+func (mq *rabbitMQAdapter) publishConfirmed(exchange, routingKey string, publishing amqp.Publishing) error {
+	s, err := mq.current()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishConfirmTimeout)
+	defer cancel()
+
+	publisher, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.release(publisher)
+
+	return publisher.publish(ctx, exchange, routingKey, publishing)
+}
+
+func deadLetterPublishing(msg *amqp.Delivery, failure interfaces.Failure, topology contracts.Topology, now time.Time) amqp.Publishing {
+	if failure.OriginalQueue == "" {
+		failure.OriginalQueue = topology.MainQueue()
+		if queue, ok := msg.Headers["x-first-death-queue"].(string); ok && queue != "" {
+			failure.OriginalQueue = queue
+		}
+	}
+	if failure.FirstSeenAt.IsZero() {
+		failure.FirstSeenAt = firstSeen(msg, now)
+	}
+	failure.DeadLetteredAt = now
+
 	headers := amqp.Table{}
-	if msg.Headers != nil {
-		headers = msg.Headers
-	}
-
-	return mq.channel.Publish(
-		"",          
-		contracts.DLQQueueName,
-		false,       
-		false,       
-		amqp.Publishing{
-			Headers:      headers,
-			ContentType: msg.ContentType,
-			Body:        msg.Body,
-			Timestamp:   time.Now(),
-		},
-	)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	for k, v := range failure.Headers() {
+		headers[k] = v
+	}
+
+	return amqp.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		MessageId:     msg.MessageId,
+		CorrelationId: msg.CorrelationId,
+		AppId:         msg.AppId,
+		Type:          msg.Type,
+		Body:          msg.Body,
+		DeliveryMode:  amqp.Persistent,
+		Timestamp:     now,
+	}
+}
+
+// firstSeen is when the message first reached the queues, as recorded in its
+// headers, or else its publish time.
+func firstSeen(msg *amqp.Delivery, now time.Time) time.Time {
+	if t := interfaces.FirstSeen(msg.Headers); !t.IsZero() {
+		return t
+	}
+	if !msg.Timestamp.IsZero() {
+		return msg.Timestamp
+	}
+	return now
 }
 
-func (mq *rabbitMQAdapter) MoveToRetryQueue(msg *amqp.Delivery) error {
+// MoveToRetryQueue publishes the message to the queue of the given backoff
+// tier. It returns to the main queue once delay has passed.
+func (mq *rabbitMQAdapter) MoveToRetryQueue(msg *amqp.Delivery, tier int, delay time.Duration) error {
+	tier, publishing := retryPublishing(msg, tier, delay, mq.topology, time.Now())
+	return mq.publishConfirmed(mq.topology.RetryExchange(), mq.topology.RetryTierQueue(tier), publishing)
+}
+
+// retryPublishing clamps tier to the configured tiers and returns it with
+// the message to publish to that tier's queue.
+func retryPublishing(msg *amqp.Delivery, tier int, delay time.Duration, topology contracts.Topology, now time.Time) (int, amqp.Publishing) {
+	if tier < 0 {
+		tier = 0
+	}
+	if tier >= len(topology.RetryBackoff) {
+		tier = len(topology.RetryBackoff) - 1
+	}
+
 	headers := amqp.Table{}
-	if msg.Headers != nil {
-		headers = msg.Headers
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[interfaces.RetryTierHeader] = tier
+	if _, ok := headers[interfaces.FirstSeenHeader]; !ok {
+		headers[interfaces.FirstSeenHeader] = firstSeen(msg, now).UTC().Format(time.RFC3339Nano)
 	}
 
-	return mq.channel.Publish(
-		contracts.RetryExchange,
-		contracts.RetryQueueName,
-		false,
-		false,
-		amqp.Publishing{
-			Headers:      headers,
-			ContentType:  msg.ContentType,
-			Body:        msg.Body,
-			Timestamp:   time.Now(),
-		},
-	)
+	return tier, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		MessageId:     msg.MessageId,
+		CorrelationId: msg.CorrelationId,
+		AppId:         msg.AppId,
+		Type:          msg.Type,
+		Body:          msg.Body,
+		Expiration:    strconv.FormatInt(delay.Milliseconds(), 10),
+		DeliveryMode:  amqp.Persistent,
+		Timestamp:     now,
+	}
 }
 
 func (mq *rabbitMQAdapter) GetDLQMessageCount() (int, error) {
-	queue, err := mq.channel.QueueInspect(contracts.DLQQueueName)
+	s, err := mq.current()
+	if err != nil {
+		return 0, err
+	}
+
+	queue, err := s.control.inspect(mq.topology.DLQQueue())
 	if err != nil {
 		return 0, fmt.Errorf("failed to inspect DLQ: %v", err)
 	}
 	return queue.Messages, nil
 }
 
-func (mq *rabbitMQAdapter) Close() {
-	if mq.channel != nil {
-		mq.channel.Close()
+// BrowseDLQ reads the DLQ on a channel of its own without acking. Closing
+// the channel returns the messages to the DLQ in their original order. It
+// waits for other browses and replays, which would miss the messages it
+// holds.
+func (mq *rabbitMQAdapter) BrowseDLQ(offset, limit int) ([]interfaces.DeadLetter, int, error) {
+	mq.dlq.Lock()
+	defer mq.dlq.Unlock()
+
+	s, err := mq.current()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open DLQ channel: %v", err)
+	}
+	defer ch.Close()
+
+	queue, err := ch.QueueInspect(mq.topology.DLQQueue())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to inspect DLQ: %v", err)
+	}
+
+	var messages []interfaces.DeadLetter
+	for i := 0; i < offset+limit && i < queue.Messages; i++ {
+		delivery, ok, err := ch.Get(mq.topology.DLQQueue(), false)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read DLQ: %v", err)
+		}
+		if !ok {
+			break
+		}
+		if i >= offset {
+			messages = append(messages, deadLetter(delivery))
+		}
+	}
+	return messages, queue.Messages, nil
+}
+
+// ReplayDLQ reads each message that was in the DLQ when the replay started.
+// A replayed message is acked only after the broker has confirmed its
+// replacement; the others are returned to the DLQ when the channel closes.
+// Like BrowseDLQ, it waits for other browses and replays.
+func (mq *rabbitMQAdapter) ReplayDLQ(ctx context.Context, replay interfaces.ReplayFunc) (int, error) {
+	mq.dlq.Lock()
+	defer mq.dlq.Unlock()
+
+	s, err := mq.current()
+	if err != nil {
+		return 0, err
+	}
+
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open DLQ channel: %v", err)
+	}
+	defer ch.Close()
+
+	publisher, err := newConfirmPublisher(s.conn, mq.topology)
+	if err != nil {
+		return 0, err
+	}
+	defer publisher.Close()
+
+	queue, err := ch.QueueInspect(mq.topology.DLQQueue())
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect DLQ: %v", err)
+	}
+
+	replayed := 0
+	for i := 0; i < queue.Messages; i++ {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		delivery, ok, err := ch.Get(mq.topology.DLQQueue(), false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read DLQ: %v", err)
+		}
+		if !ok {
+			break
+		}
+
+		msg, err := replay(deadLetter(delivery))
+		if err != nil {
+			return replayed, err
+		}
+		if msg == nil {
+			continue
+		}
+
+		if err := publisher.PublishMessage(ctx, *msg); err != nil {
+			return replayed, fmt.Errorf("failed to replay message %s: %v", msg.ID, err)
+		}
+		if err := delivery.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to remove replayed message %s from DLQ: %v", msg.ID, err)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (mq *rabbitMQAdapter) PurgeDLQ() (int, error) {
+	s, err := mq.current()
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := s.control.purge(mq.topology.DLQQueue())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge DLQ: %v", err)
 	}
-	if mq.conn != nil {
-		mq.conn.Close()
+	return purged, nil
+}
+
+func deadLetter(delivery amqp.Delivery) interfaces.DeadLetter {
+	return interfaces.DeadLetter{
+		MessageID:   delivery.MessageId,
+		ContentType: delivery.ContentType,
+		Body:        delivery.Body,
+		Headers:     delivery.Headers,
+		Timestamp:   delivery.Timestamp,
+	}
+}
+
+func (mq *rabbitMQAdapter) GetQueueStats(queueName string) (interfaces.QueueStats, error) {
+	s, err := mq.current()
+	if err != nil {
+		return interfaces.QueueStats{}, err
+	}
+
+	queue, err := s.control.inspect(queueName)
+	if err != nil {
+		return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: %v", queueName, err)
+	}
+	return interfaces.QueueStats{Messages: queue.Messages, Consumers: queue.Consumers}, nil
+}
+
+func (p *confirmPublisher) Close() {
+	p.channel.Close()
+}
+
+func (mq *rabbitMQAdapter) Close() {
+	mq.closeOnce.Do(func() { close(mq.closed) })
+
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	mq.connected = false
+	if mq.session != nil {
+		// Closing the connection closes every channel opened on it.
+		mq.session.conn.Close()
 	}
-} 
\ No newline at end of file
+}
\ No newline at end of file
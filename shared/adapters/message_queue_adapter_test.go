@@ -0,0 +1,64 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmPublisher_DrainsLateConfirms(t *testing.T) {
+	p := &confirmPublisher{
+		confirms: make(chan amqp.Confirmation, 1),
+		returns:  make(chan amqp.Return, 1),
+	}
+	s := &session{publishers: make(chan *confirmPublisher, 1)}
+
+	p.draining = p.drain(2)
+	s.release(p)
+
+	// The client library blocks its reader until a confirm is read, so every
+	// late confirm and return must be taken without a publish waiting.
+	for _, send := range []func(){
+		func() { p.confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: true} },
+		func() { p.returns <- amqp.Return{MessageId: "m2"} },
+	} {
+		sent := make(chan struct{})
+		go func() { send(); close(sent) }()
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("late confirm was not drained")
+		}
+	}
+
+	select {
+	case <-s.publishers:
+		t.Fatal("publisher returned to the pool before its confirm arrived")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	p.confirms <- amqp.Confirmation{DeliveryTag: 2, Ack: true}
+	select {
+	case released := <-s.publishers:
+		assert.Same(t, p, released)
+	case <-time.After(time.Second):
+		t.Fatal("publisher was not returned to the pool")
+	}
+}
+
+func TestDLQArgs_StaysClassicWithQuorumQueues(t *testing.T) {
+	topology := contracts.DefaultTopology()
+	assert.Nil(t, dlqArgs(topology))
+
+	topology.QueueType = contracts.QueueTypeQuorum
+	topology.DLQMaxLength = 100
+	assert.Equal(t, amqp.Table{"x-max-length": 100}, dlqArgs(topology))
+	assert.Equal(t, amqp.Table{"x-queue-type": "quorum"}, queueArgs(topology, amqp.Table{}), "the other queues are quorum queues")
+
+	topology.QueueType = contracts.QueueTypeLazy
+	assert.Equal(t, amqp.Table{"x-max-length": 100, "x-queue-mode": "lazy"}, dlqArgs(topology))
+}
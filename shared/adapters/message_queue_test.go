@@ -0,0 +1,342 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTopology() contracts.Topology {
+	topology := contracts.DefaultTopology()
+	topology.RetryTTL = 50 * time.Millisecond
+	topology.RetryBackoff = []time.Duration{20 * time.Millisecond, 40 * time.Millisecond}
+	return topology
+}
+
+func receive(t *testing.T, deliveries <-chan amqp.Delivery) amqp.Delivery {
+	select {
+	case d, ok := <-deliveries:
+		require.True(t, ok, "delivery channel closed")
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+		return amqp.Delivery{}
+	}
+}
+
+// testMessageQueueBehaviour checks the behaviour the processor relies on
+// from every MessageQueue backend.
+func testMessageQueueBehaviour(t *testing.T, newQueue func(t *testing.T, topology contracts.Topology) interfaces.MessageQueue) {
+	t.Run("publish and consume", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1", Content: "hello", To: "+905321234567", CorrelationID: "request-1"}))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 1)
+		require.NoError(t, err)
+
+		d := receive(t, deliveries)
+		assert.Equal(t, "m1", d.MessageId)
+		assert.Equal(t, "request-1", d.CorrelationId)
+		assert.Equal(t, contracts.AppID, d.AppId)
+		assert.Equal(t, contracts.QueueMessageType, d.Type)
+		assert.Equal(t, contracts.ContentTypeJSON, d.ContentType)
+		assert.Equal(t, contracts.CurrentSchemaVersion, interfaces.SchemaVersion(d.Headers))
+		assert.False(t, d.Redelivered)
+
+		msg, err := interfaces.DecodeMessage(d.ContentType, d.Headers, d.Body)
+		require.NoError(t, err)
+		assert.Equal(t, contracts.QueueMessage{ID: "m1", Content: "hello", To: "+905321234567"}, msg)
+		require.NoError(t, d.Ack(false))
+	})
+
+	t.Run("protobuf message survives a retry", func(t *testing.T) {
+		topology := testTopology()
+		topology.ContentType = contracts.ContentTypeProtobuf
+		mq := newQueue(t, topology)
+		sent := contracts.QueueMessage{ID: "m1", Content: "hello", To: "+905321234567", Retry: 1}
+		require.NoError(t, mq.PublishMessage(context.Background(), sent))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+
+		require.NoError(t, receive(t, deliveries).Nack(false, false))
+		d := receive(t, deliveries)
+		assert.Equal(t, contracts.ContentTypeProtobuf, d.ContentType)
+
+		msg, err := interfaces.DecodeMessage(d.ContentType, d.Headers, d.Body)
+		require.NoError(t, err)
+		assert.Equal(t, sent, msg)
+		require.NoError(t, d.Ack(false))
+	})
+
+	t.Run("prefetch limits unacked deliveries", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+		for _, id := range []string{"m1", "m2"} {
+			require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: id}))
+		}
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 1)
+		require.NoError(t, err)
+
+		first := receive(t, deliveries)
+		select {
+		case <-deliveries:
+			t.Fatal("received a second delivery beyond the prefetch")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		require.NoError(t, first.Ack(false))
+		second := receive(t, deliveries)
+		assert.Equal(t, "m2", second.MessageId)
+		require.NoError(t, second.Ack(false))
+	})
+
+	t.Run("nack with requeue redelivers", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+
+		require.NoError(t, receive(t, deliveries).Nack(false, true))
+		d := receive(t, deliveries)
+		assert.Equal(t, "m1", d.MessageId)
+		assert.True(t, d.Redelivered)
+		require.NoError(t, d.Ack(false))
+	})
+
+	t.Run("rejected message returns after retry TTL", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+
+		rejectedAt := time.Now()
+		require.NoError(t, receive(t, deliveries).Nack(false, false))
+
+		d := receive(t, deliveries)
+		assert.GreaterOrEqual(t, time.Since(rejectedAt), topology.RetryTTL)
+		assert.Equal(t, topology.MainQueue(), d.Headers["x-first-death-queue"])
+		assert.Equal(t, "rejected", d.Headers["x-first-death-reason"])
+
+		deaths := interfaces.ParseDeaths(d.Headers)
+		require.Len(t, deaths, 2)
+		assert.Equal(t, topology.RetryQueue(), deaths[0].Queue)
+		assert.Equal(t, "expired", deaths[0].Reason)
+		assert.Equal(t, topology.MainQueue(), deaths[1].Queue)
+		assert.Equal(t, "rejected", deaths[1].Reason)
+		require.NoError(t, d.Ack(false))
+	})
+
+	t.Run("retry tier delays message", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+
+		d := receive(t, deliveries)
+		retriedAt := time.Now()
+		require.NoError(t, mq.MoveToRetryQueue(&d, 7, 30*time.Millisecond))
+		require.NoError(t, d.Ack(false))
+
+		retried := receive(t, deliveries)
+		assert.GreaterOrEqual(t, time.Since(retriedAt), 30*time.Millisecond)
+		assert.EqualValues(t, 1, retried.Headers[interfaces.RetryTierHeader], "tier is clamped to the last tier")
+		assert.Equal(t, "m1", retried.MessageId)
+		assert.Equal(t, "m1", retried.CorrelationId, "messages without a correlation ID are correlated by their ID")
+		assert.Empty(t, retried.Expiration)
+		assert.False(t, interfaces.FirstSeen(retried.Headers).IsZero())
+
+		deaths := interfaces.ParseDeaths(retried.Headers)
+		require.Len(t, deaths, 1)
+		assert.Equal(t, topology.RetryTierQueue(1), deaths[0].Queue)
+		assert.Equal(t, "expired", deaths[0].Reason)
+		require.NoError(t, retried.Ack(false))
+	})
+
+	t.Run("retry backlog counts messages waiting in a tier", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+		d := receive(t, deliveries)
+		require.NoError(t, mq.MoveToRetryQueue(&d, 0, time.Minute))
+		require.NoError(t, d.Ack(false))
+
+		queues := []string{topology.RetryQueue()}
+		for tier := range topology.RetryBackoff {
+			queues = append(queues, topology.RetryTierQueue(tier))
+		}
+		backlog := 0
+		for _, queue := range queues {
+			stats, err := mq.GetQueueStats(queue)
+			require.NoError(t, err)
+			backlog += stats.Messages
+		}
+		assert.Equal(t, 1, backlog)
+	})
+
+	t.Run("expired message waits in the retry queue", func(t *testing.T) {
+		topology := testTopology()
+		topology.MessageTTL = 20 * time.Millisecond
+		mq := newQueue(t, topology)
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+		time.Sleep(2 * topology.MessageTTL)
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+
+		d := receive(t, deliveries)
+		assert.Equal(t, "m1", d.MessageId)
+		assert.Empty(t, d.Expiration)
+		assert.Equal(t, topology.MainQueue(), d.Headers["x-first-death-queue"])
+		assert.Equal(t, "expired", d.Headers["x-first-death-reason"])
+		require.NoError(t, d.Ack(false))
+	})
+
+	t.Run("reject-publish overflow nacks", func(t *testing.T) {
+		topology := testTopology()
+		topology.MaxLength = 1
+		topology.Overflow = contracts.OverflowRejectPublish
+		mq := newQueue(t, topology)
+
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+		err := mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m2"})
+		assert.True(t, errors.Is(err, interfaces.ErrPublishNacked))
+	})
+
+	t.Run("drop-head overflow keeps the newest messages", func(t *testing.T) {
+		topology := testTopology()
+		topology.MaxLength = 1
+		mq := newQueue(t, topology)
+
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m2"}))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+		d := receive(t, deliveries)
+		assert.Equal(t, "m2", d.MessageId)
+		require.NoError(t, d.Ack(false))
+	})
+
+	t.Run("drop-head overflow dead-letters the oldest message", func(t *testing.T) {
+		topology := testTopology()
+		topology.MaxLength = 1
+		mq := newQueue(t, topology)
+
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+		require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m2"}))
+
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+		d := receive(t, deliveries)
+		assert.Equal(t, "m2", d.MessageId)
+		require.NoError(t, d.Ack(false))
+
+		d = receive(t, deliveries)
+		assert.Equal(t, "m1", d.MessageId)
+		assert.Equal(t, topology.MainQueue(), d.Headers["x-first-death-queue"])
+		assert.Equal(t, "maxlen", d.Headers["x-first-death-reason"])
+		require.NoError(t, d.Ack(false))
+	})
+
+	t.Run("DLQ browse, replay and purge", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+		ctx := context.Background()
+
+		for _, id := range []string{"m1", "m2", "m3"} {
+			require.NoError(t, mq.PublishMessage(ctx, contracts.QueueMessage{ID: id}))
+		}
+		deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			d := receive(t, deliveries)
+			require.NoError(t, mq.MoveToDeadLetter(&d, interfaces.Failure{Reason: interfaces.FailureMaxRetries, Attempts: 5}))
+			require.NoError(t, d.Ack(false))
+		}
+
+		require.Eventually(t, func() bool {
+			count, err := mq.GetDLQMessageCount()
+			return err == nil && count == 3
+		}, 5*time.Second, 10*time.Millisecond)
+
+		page, total, err := mq.BrowseDLQ(1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, page, 1)
+		assert.Equal(t, "m2", page[0].MessageID)
+		failure := interfaces.ParseFailure(page[0].Headers)
+		require.NotNil(t, failure)
+		assert.Equal(t, interfaces.FailureMaxRetries, failure.Reason)
+		assert.Equal(t, topology.MainQueue(), failure.OriginalQueue)
+
+		replayed, err := mq.ReplayDLQ(ctx, func(letter interfaces.DeadLetter) (*contracts.QueueMessage, error) {
+			if letter.MessageID == "m2" {
+				return nil, nil
+			}
+			return &contracts.QueueMessage{ID: letter.MessageID}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, replayed)
+		for _, id := range []string{"m1", "m3"} {
+			d := receive(t, deliveries)
+			assert.Equal(t, id, d.MessageId)
+			require.NoError(t, d.Ack(false))
+		}
+
+		purged, err := mq.PurgeDLQ()
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+	})
+
+	t.Run("close disconnects", func(t *testing.T) {
+		topology := testTopology()
+		mq := newQueue(t, topology)
+		assert.True(t, mq.ConnectionState().Connected)
+
+		mq.Close()
+		mq.Close()
+
+		assert.False(t, mq.ConnectionState().Connected)
+		assert.ErrorIs(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}), interfaces.ErrNotConnected)
+	})
+}
+
+// TestRabbitMessageQueue runs against the broker at RABBITMQ_TEST_URI. Each
+// test declares its own prefixed topology, which is left on the broker.
+func TestRabbitMessageQueue(t *testing.T) {
+	uri := os.Getenv("RABBITMQ_TEST_URI")
+	if uri == "" {
+		t.Skip("RABBITMQ_TEST_URI is not set")
+	}
+
+	testMessageQueueBehaviour(t, func(t *testing.T, topology contracts.Topology) interfaces.MessageQueue {
+		topology.Prefix = fmt.Sprintf("test-%d", time.Now().UnixNano())
+		mq, err := NewMessageQueue(uri, topology)
+		require.NoError(t, err)
+		t.Cleanup(mq.Close)
+		return mq
+	})
+}
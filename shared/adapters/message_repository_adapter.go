@@ -17,6 +17,7 @@ import (
 
 type mongoMessageRepository struct {
 	collection *mongo.Collection
+	outbox     *mongo.Collection
 	cb         *gobreaker.CircuitBreaker
 }
 
@@ -37,6 +38,7 @@ func NewMessageRepository(db *mongo.Database) interfaces.MessageRepository {
 
 	return &mongoMessageRepository{
 		collection: db.Collection("messages"),
+		outbox:     db.Collection("outbox"),
 		cb:         cb,
 	}
 }
@@ -127,6 +129,54 @@ func (r *mongoMessageRepository) UpdateStatus(ctx context.Context, id primitive.
 	return nil
 }
 
+func (r *mongoMessageRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (bool, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		update := bson.M{
+			"$set": bson.M{
+				"status":     to,
+				"updated_at": time.Now(),
+			},
+		}
+		res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, update)
+		if err != nil {
+			return false, err
+		}
+		return res.ModifiedCount > 0, nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(bool), nil
+}
+
+func (r *mongoMessageRepository) TransitionStatuses(ctx context.Context, ids []primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		update := bson.M{
+			"$set": bson.M{
+				"status":     to,
+				"updated_at": time.Now(),
+			},
+		}
+		res, err := r.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "status": from}, update)
+		if err != nil {
+			return 0, err
+		}
+		return int(res.ModifiedCount), nil
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(int), nil
+}
+
 func (r *mongoMessageRepository) IncrementRetryCount(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.cb.Execute(func() (interface{}, error) {
 		update := bson.M{
@@ -144,6 +194,50 @@ func (r *mongoMessageRepository) IncrementRetryCount(ctx context.Context, id pri
 	return nil
 }
 
+func (r *mongoMessageRepository) ScheduleRetry(ctx context.Context, id primitive.ObjectID, tier int, nextRetryAt time.Time) error {
+	_, err := r.cb.Execute(func() (interface{}, error) {
+		update := bson.M{
+			"$set": bson.M{
+				"retry_tier":    tier,
+				"next_retry_at": nextRetryAt,
+				"updated_at":    time.Now(),
+			},
+		}
+		_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+		return nil, err
+	})
+
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *mongoMessageRepository) ResetRetries(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.cb.Execute(func() (interface{}, error) {
+		update := bson.M{
+			"$set": bson.M{
+				"status":      models.StatusProcessing,
+				"retry_count": 0,
+				"updated_at":  time.Now(),
+			},
+			"$unset": bson.M{
+				"retry_tier":    "",
+				"next_retry_at": "",
+			},
+		}
+		_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+		return nil, err
+	})
+
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return nil
+}
+
 func (r *mongoMessageRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
 	var message models.Message
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&message)
@@ -157,7 +251,41 @@ func (r *mongoMessageRepository) GetByID(ctx context.Context, id primitive.Objec
 }
 
 func (r *mongoMessageRepository) CreateMessage(ctx context.Context, msg *models.Message) error {
-	_, err := r.collection.InsertOne(ctx, msg)
+	return r.CreateMessages(ctx, []*models.Message{msg})
+}
+
+func (r *mongoMessageRepository) CreateMessages(ctx context.Context, msgs []*models.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(msgs))
+	events := make([]interface{}, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.ID.IsZero() {
+			msg.ID = primitive.NewObjectID()
+		}
+		event, err := models.NewMessageCreatedEvent(msg)
+		if err != nil {
+			return fmt.Errorf("failed to build outbox event: %v", err)
+		}
+		docs = append(docs, msg)
+		events = append(events, event)
+	}
+
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := r.collection.InsertMany(sc, docs); err != nil {
+			return nil, err
+		}
+		_, err := r.outbox.InsertMany(sc, events)
+		return nil, err
+	})
 	return err
 }
 
@@ -182,6 +310,10 @@ func (r *mongoMessageRepository) FindStaleProcessingMessages(ctx context.Context
 	filter := bson.M{
 		"status": models.StatusProcessing,
 		"updated_at": bson.M{"$lt": staleTime},
+		"$or": bson.A{
+			bson.M{"next_retry_at": bson.M{"$exists": false}},
+			bson.M{"next_retry_at": bson.M{"$lt": staleTime}},
+		},
 	}
 
 	cursor, err := r.collection.Find(ctx, filter)
@@ -196,4 +328,4 @@ func (r *mongoMessageRepository) FindStaleProcessingMessages(ctx context.Context
 	}
 
 	return messages, nil
-} 
\ No newline at end of file
+}
@@ -0,0 +1,328 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"github.com/sony/gobreaker"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const changeStreamHistoryLost = 286
+
+type mongoOutboxRepository struct {
+	collection *mongo.Collection
+	messages   *mongo.Collection
+	tokens     *mongo.Collection
+	cb         *gobreaker.CircuitBreaker
+}
+
+func NewOutboxRepository(db *mongo.Database) interfaces.OutboxRepository {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "mongodb-outbox",
+		MaxRequests: 3,
+		Interval:    10 * time.Second,
+		Timeout:     30 * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= 3 && failureRatio >= 0.6
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			fmt.Printf("Circuit breaker %s state changed from %s to %s\n", name, from, to)
+		},
+	})
+
+	return &mongoOutboxRepository{
+		collection: db.Collection("outbox"),
+		messages:   db.Collection("messages"),
+		tokens:     db.Collection("change_stream_tokens"),
+		cb:         cb,
+	}
+}
+
+func claimableFilter(types []string, now time.Time) bson.M {
+	return bson.M{
+		"type": bson.M{"$in": types},
+		"$or": bson.A{
+			bson.M{"claim_expires_at": nil},
+			bson.M{"claim_expires_at": bson.M{"$lte": now}},
+		},
+	}
+}
+
+// claimUpdate claims an event for owner. The token identifies the claim, so
+// a batch claim can read back the events it claimed.
+func claimUpdate(owner, token string, now time.Time, lease time.Duration) bson.M {
+	return bson.M{
+		"$set": bson.M{
+			"claim_owner":      owner,
+			"claim_token":      token,
+			"claim_expires_at": now.Add(lease),
+		},
+	}
+}
+
+// ClaimEvents claims up to limit events in three round trips: it finds the
+// oldest claimable events, claims those still claimable with one updateMany
+// under a token of its own, and reads back the events holding that token.
+// Events another instance claimed in between are left out, so it can return
+// fewer than limit events while more are pending.
+func (r *mongoOutboxRepository) ClaimEvents(ctx context.Context, types []string, owner string, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		now := time.Now()
+		opts := options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: 1}}).
+			SetLimit(int64(limit)).
+			SetProjection(bson.M{"_id": 1})
+
+		cursor, err := r.collection.Find(ctx, claimableFilter(types, now), opts)
+		if err != nil {
+			return nil, err
+		}
+		var found []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &found); err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return []models.OutboxEvent{}, nil
+		}
+
+		ids := make([]primitive.ObjectID, len(found))
+		for i, f := range found {
+			ids[i] = f.ID
+		}
+		token := primitive.NewObjectID().Hex()
+		filter := claimableFilter(types, now)
+		filter["_id"] = bson.M{"$in": ids}
+		if _, err := r.collection.UpdateMany(ctx, filter, claimUpdate(owner, token, now, lease)); err != nil {
+			return nil, err
+		}
+
+		cursor, err = r.collection.Find(ctx,
+			bson.M{"_id": bson.M{"$in": ids}, "claim_token": token},
+			options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+		if err != nil {
+			return nil, err
+		}
+		events := make([]models.OutboxEvent, 0, len(ids))
+		if err := cursor.All(ctx, &events); err != nil {
+			return nil, err
+		}
+		return events, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.([]models.OutboxEvent), nil
+}
+
+func (r *mongoOutboxRepository) OldestEvent(ctx context.Context) (*models.OutboxEvent, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+		var event models.OutboxEvent
+		err := r.collection.FindOne(ctx, bson.M{}, opts).Decode(&event)
+		if err == mongo.ErrNoDocuments {
+			return (*models.OutboxEvent)(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &event, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(*models.OutboxEvent), nil
+}
+
+func (r *mongoOutboxRepository) ClaimEvent(ctx context.Context, id primitive.ObjectID, types []string, owner string, lease time.Duration) (*models.OutboxEvent, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		now := time.Now()
+		filter := claimableFilter(types, now)
+		filter["_id"] = id
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+		var event models.OutboxEvent
+		err := r.collection.FindOneAndUpdate(ctx, filter, claimUpdate(owner, primitive.NewObjectID().Hex(), now, lease), opts).Decode(&event)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &event, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result.(*models.OutboxEvent), nil
+}
+
+func (r *mongoOutboxRepository) CompleteEvent(ctx context.Context, id primitive.ObjectID, owner string) error {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "claim_owner": owner})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	if !result.(bool) {
+		return interfaces.ErrClaimNotHeld
+	}
+
+	return nil
+}
+
+func (r *mongoOutboxRepository) CompleteEvents(ctx context.Context, ids []primitive.ObjectID, owner string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		res, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "claim_owner": owner})
+		if err != nil {
+			return 0, err
+		}
+		return int(res.DeletedCount), nil
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(int), nil
+}
+
+func (r *mongoOutboxRepository) ReleaseEvent(ctx context.Context, id primitive.ObjectID, owner string, lastErr string) error {
+	_, err := r.cb.Execute(func() (interface{}, error) {
+		update := bson.M{
+			"$unset": bson.M{"claim_owner": "", "claim_token": "", "claim_expires_at": ""},
+			"$set":   bson.M{"last_error": lastErr},
+			"$inc":   bson.M{"attempts": 1},
+		}
+		_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "claim_owner": owner}, update)
+		return nil, err
+	})
+
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *mongoOutboxRepository) EnqueueUnsentMessages(ctx context.Context) (int, error) {
+	cursor, err := r.messages.Find(ctx, bson.M{"status": models.StatusUnsent})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unsent messages: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	enqueued := 0
+	for cursor.Next(ctx) {
+		var msg models.Message
+		if err := cursor.Decode(&msg); err != nil {
+			return enqueued, fmt.Errorf("failed to decode message: %v", err)
+		}
+
+		event, err := models.NewMessageCreatedEvent(&msg)
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to build outbox event: %v", err)
+		}
+
+		// The event ID is the message ID, so a message that already has a
+		// pending event is skipped.
+		_, err = r.collection.InsertOne(ctx, event)
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to insert outbox event: %v", err)
+		}
+		enqueued++
+	}
+
+	return enqueued, cursor.Err()
+}
+
+type changeStreamToken struct {
+	Stream    string    `bson:"_id"`
+	Token     bson.Raw  `bson:"token"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func (r *mongoOutboxRepository) WatchInserts(ctx context.Context, stream string, handle func(ctx context.Context, id primitive.ObjectID) error) error {
+	opts := options.ChangeStream()
+	var saved changeStreamToken
+	err := r.tokens.FindOne(ctx, bson.M{"_id": stream}).Decode(&saved)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return fmt.Errorf("failed to load resume token: %v", err)
+	}
+	if err == nil {
+		opts.SetResumeAfter(saved.Token)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+	changeStream, err := r.collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		if serverErr, ok := err.(mongo.ServerError); ok && saved.Token != nil && serverErr.HasErrorCode(changeStreamHistoryLost) {
+			// The saved position has fallen off the oplog. Start from now on
+			// the next attempt; polling picks up anything missed.
+			if _, delErr := r.tokens.DeleteOne(ctx, bson.M{"_id": stream}); delErr != nil {
+				return fmt.Errorf("failed to open change stream: %v; failed to discard resume token: %v", err, delErr)
+			}
+		}
+		return fmt.Errorf("failed to open change stream: %v", err)
+	}
+	defer changeStream.Close(context.Background())
+
+	for changeStream.Next(ctx) {
+		var event struct {
+			DocumentKey struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+		}
+		if err := changeStream.Decode(&event); err != nil {
+			return fmt.Errorf("failed to decode change event: %v", err)
+		}
+
+		if err := handle(ctx, event.DocumentKey.ID); err != nil {
+			return err
+		}
+
+		update := bson.M{"$set": bson.M{"token": changeStream.ResumeToken(), "updated_at": time.Now()}}
+		if _, err := r.tokens.UpdateOne(ctx, bson.M{"_id": stream}, update, options.Update().SetUpsert(true)); err != nil {
+			return fmt.Errorf("failed to save resume token: %v", err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return changeStream.Err()
+}
@@ -0,0 +1,218 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"github.com/sony/gobreaker"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRecurringMessageRepository struct {
+	collection *mongo.Collection
+	messages   *mongo.Collection
+	outbox     *mongo.Collection
+	cb         *gobreaker.CircuitBreaker
+}
+
+func NewRecurringMessageRepository(db *mongo.Database) interfaces.RecurringMessageRepository {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "mongodb-recurring-messages",
+		MaxRequests: 3,
+		Interval:    10 * time.Second,
+		Timeout:     30 * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= 3 && failureRatio >= 0.6
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			fmt.Printf("Circuit breaker %s state changed from %s to %s\n", name, from, to)
+		},
+	})
+
+	return &mongoRecurringMessageRepository{
+		collection: db.Collection("recurring_messages"),
+		messages:   db.Collection("messages"),
+		outbox:     db.Collection("outbox"),
+		cb:         cb,
+	}
+}
+
+func (r *mongoRecurringMessageRepository) Create(ctx context.Context, recurring *models.RecurringMessage) error {
+	_, err := r.cb.Execute(func() (interface{}, error) {
+		if recurring.ID.IsZero() {
+			recurring.ID = primitive.NewObjectID()
+		}
+		_, err := r.collection.InsertOne(ctx, recurring)
+		return nil, err
+	})
+
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *mongoRecurringMessageRepository) List(ctx context.Context) ([]models.RecurringMessage, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+		cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		recurring := []models.RecurringMessage{}
+		if err := cursor.All(ctx, &recurring); err != nil {
+			return nil, err
+		}
+		return recurring, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.([]models.RecurringMessage), nil
+}
+
+func (r *mongoRecurringMessageRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.RecurringMessage, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		var recurring models.RecurringMessage
+		err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&recurring)
+		if err == mongo.ErrNoDocuments {
+			return (*models.RecurringMessage)(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &recurring, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(*models.RecurringMessage), nil
+}
+
+func (r *mongoRecurringMessageRepository) SetPaused(ctx context.Context, id primitive.ObjectID, paused bool, nextRunAt time.Time) (bool, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		update := bson.M{
+			"$set": bson.M{
+				"paused":      paused,
+				"next_run_at": nextRunAt,
+				"updated_at":  time.Now(),
+			},
+		}
+		res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+		if err != nil {
+			return false, err
+		}
+		return res.MatchedCount > 0, nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(bool), nil
+}
+
+func (r *mongoRecurringMessageRepository) Delete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(bool), nil
+}
+
+func (r *mongoRecurringMessageRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]models.RecurringMessage, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		opts := options.Find().
+			SetSort(bson.D{{Key: "next_run_at", Value: 1}}).
+			SetLimit(int64(limit))
+		cursor, err := r.collection.Find(ctx, bson.M{"paused": false, "next_run_at": bson.M{"$lte": now}}, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		due := []models.RecurringMessage{}
+		if err := cursor.All(ctx, &due); err != nil {
+			return nil, err
+		}
+		return due, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.([]models.RecurringMessage), nil
+}
+
+func (r *mongoRecurringMessageRepository) RecordOccurrence(ctx context.Context, id primitive.ObjectID, occurrence time.Time, next time.Time, msg *models.Message) (bool, error) {
+	if msg.ID.IsZero() {
+		msg.ID = primitive.NewObjectID()
+	}
+	event, err := models.NewMessageCreatedEvent(msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to build outbox event: %v", err)
+	}
+
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		session, err := r.collection.Database().Client().StartSession()
+		if err != nil {
+			return false, err
+		}
+		defer session.EndSession(ctx)
+
+		return session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+			now := time.Now()
+			update := bson.M{
+				"$set": bson.M{
+					"next_run_at": next,
+					"last_run_at": occurrence,
+					"updated_at":  now,
+				},
+			}
+			res, err := r.collection.UpdateOne(sc, bson.M{"_id": id, "paused": false, "next_run_at": occurrence}, update)
+			if err != nil {
+				return false, err
+			}
+			if res.MatchedCount == 0 {
+				return false, nil
+			}
+			if _, err := r.messages.InsertOne(sc, msg); err != nil {
+				return false, err
+			}
+			if _, err := r.outbox.InsertOne(sc, event); err != nil {
+				return false, err
+			}
+			return true, nil
+		})
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return result.(bool), nil
+}
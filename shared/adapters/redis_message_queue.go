@@ -0,0 +1,1105 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/streadway/amqp"
+)
+
+// Message queue backends selectable in config.
+const (
+	QueueBackendRabbitMQ = "rabbitmq"
+	QueueBackendRedis    = "redis"
+)
+
+const (
+	redisConsumerGroup = "processors"
+	// redisReadBlock bounds how long a consumer blocks in XREADGROUP, so it
+	// notices Close and refreshes its heartbeat.
+	redisReadBlock = time.Second
+	// redisConsumerTimeout is how long a consumer counts as live after its
+	// last heartbeat.
+	redisConsumerTimeout = 3 * redisReadBlock
+	redisReadCount       = 16
+	redisPromoteBatch    = 100
+	redisRetryDelay      = time.Second
+)
+
+// publishScript adds a message to a stream unless its length limit is
+// reached. Then reject-publish returns nil, and drop-head returns the oldest
+// entry that is not being delivered, for the caller to dead-letter before it
+// publishes again. When every entry is being delivered the message is added
+// anyway, as the broker does not count unacknowledged messages.
+var publishScript = redis.NewScript(`
+local maxlen = tonumber(ARGV[1])
+if maxlen > 0 and redis.call('XLEN', KEYS[1]) >= maxlen then
+	if ARGV[2] ~= 'drop-head' then
+		return false
+	end
+	local start = '-'
+	while true do
+		local entries = redis.call('XRANGE', KEYS[1], start, '+', 'COUNT', 16)
+		if #entries == 0 then
+			break
+		end
+		for _, entry in ipairs(entries) do
+			local pending = redis.pcall('XPENDING', KEYS[1], ARGV[3], entry[1], entry[1], 1)
+			if type(pending) ~= 'table' or pending.err or #pending == 0 then
+				return entry
+			end
+		end
+		start = '(' .. entries[#entries][1]
+	end
+end
+return redis.call('XADD', KEYS[1], '*', unpack(ARGV, 4))
+`)
+
+// dropHeadScript moves an entry dropped from the head of a stream to the
+// delayed set. The XDEL makes sure only one publisher moves it.
+var dropHeadScript = redis.NewScript(`
+if redis.call('XDEL', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[3])
+return 1
+`)
+
+// promoteScript moves a due message from the delayed set to the stream. The
+// ZREM makes sure only one instance promotes it.
+var promoteScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('XADD', KEYS[2], '*', unpack(ARGV, 2))
+return 1
+`)
+
+// redisMessageQueue implements MessageQueue on Redis Streams for deployments
+// that run Redis but not RabbitMQ. The topology's queue names become keys:
+//
+//   - the main queue and the DLQ are streams. Consumers read the main
+//     stream through one consumer group, and acked entries are deleted.
+//   - the retry queue is a sorted set of delayed messages scored by when they
+//     are due, shared by every backoff tier. A promoter moves due messages
+//     back to the main stream.
+//
+// A delivery that stays unacknowledged for claimIdle, e.g. because its
+// consumer died, is claimed by another consumer with XPENDING and XCLAIM and
+// redelivered. Message expirations are checked when an entry is read, and
+// expired entries wait in the retry queue like rejected ones. Both get the
+// x-death headers RabbitMQ would add, so the processor handles both backends
+// the same way.
+//
+// MaxLength counts unacknowledged entries too. Drop-head dead-letters the
+// oldest entry that is not being delivered to the retry queue, and
+// reject-publish-dlx behaves like reject-publish.
+type redisMessageQueue struct {
+	client    *redis.Client
+	topology  contracts.Topology
+	consumer  string
+	claimIdle time.Duration
+
+	mu         sync.Mutex
+	connected  bool
+	since      time.Time
+	reconnects int
+	lastError  string
+	consumers  int
+
+	promote   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type redisConsumer struct {
+	mq         *redisMessageQueue
+	stream     string
+	name       string
+	prefetch   int
+	deliveries chan amqp.Delivery
+	settled    chan struct{}
+
+	mu      sync.Mutex
+	nextTag uint64
+	unacked map[uint64]redisUnacked
+}
+
+type redisUnacked struct {
+	id         string
+	publishing amqp.Publishing
+}
+
+// redisDelayed is a member of the delayed set. Queue is the retry queue the
+// message waits in, recorded in its x-death header when it is due. Nonce
+// keeps identical messages apart. Fields are bytes, so binary bodies survive
+// the JSON encoding.
+type redisDelayed struct {
+	Nonce  string            `json:"nonce"`
+	Queue  string            `json:"queue"`
+	Fields map[string][]byte `json:"fields"`
+}
+
+// NewRedisMessageQueue consumes as consumer, suffixed per ConsumeMessages
+// call, so it should be unique to the instance.
+func NewRedisMessageQueue(client *redis.Client, topology contracts.Topology, consumer string, claimIdle time.Duration) (interfaces.MessageQueue, error) {
+	return newRedisMessageQueue(client, topology, consumer, claimIdle)
+}
+
+func newRedisMessageQueue(client *redis.Client, topology contracts.Topology, consumer string, claimIdle time.Duration) (*redisMessageQueue, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid topology: %v", err)
+	}
+	if claimIdle <= 0 {
+		return nil, fmt.Errorf("claim idle time must be positive, got %s", claimIdle)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+	}
+
+	mq := &redisMessageQueue{
+		client:    client,
+		topology:  topology,
+		consumer:  consumer,
+		claimIdle: claimIdle,
+		connected: true,
+		since:     time.Now(),
+		promote:   make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+	}
+	go mq.promoteLoop()
+	return mq, nil
+}
+
+func (mq *redisMessageQueue) isClosed() bool {
+	select {
+	case <-mq.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// observe records the outcome of a Redis call in the connection state and
+// returns err.
+func (mq *redisMessageQueue) observe(err error) error {
+	// Errors after Close come from calls racing the owner closing the
+	// client.
+	if err == redis.Nil || mq.isClosed() {
+		return err
+	}
+
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	switch {
+	case err != nil && mq.connected:
+		mq.connected = false
+		mq.since = time.Now()
+		mq.lastError = err.Error()
+		log.Printf("Lost Redis connection: %v", err)
+	case err != nil:
+		mq.lastError = err.Error()
+	case !mq.connected:
+		mq.connected = true
+		mq.since = time.Now()
+		mq.reconnects++
+		log.Println("Reconnected to Redis")
+	}
+	return err
+}
+
+func (mq *redisMessageQueue) ConnectionState() interfaces.ConnectionState {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	state := interfaces.ConnectionState{
+		Connected:  mq.connected && !mq.isClosed(),
+		Since:      mq.since,
+		Reconnects: mq.reconnects,
+	}
+	if !state.Connected {
+		state.LastError = mq.lastError
+	}
+	return state
+}
+
+// streamValues flattens a message into stream entry fields.
+func streamValues(publishing amqp.Publishing, redelivered bool) (map[string]string, error) {
+	headers, err := json.Marshal(publishing.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal headers: %v", err)
+	}
+
+	values := map[string]string{
+		"message_id":     publishing.MessageId,
+		"correlation_id": publishing.CorrelationId,
+		"app_id":         publishing.AppId,
+		"type":           publishing.Type,
+		"content_type":   publishing.ContentType,
+		"timestamp":      publishing.Timestamp.UTC().Format(time.RFC3339Nano),
+		"headers":        string(headers),
+		"body":           string(publishing.Body),
+	}
+	if publishing.Expiration != "" {
+		values["expiration"] = publishing.Expiration
+	}
+	if redelivered {
+		values["redelivered"] = "1"
+	}
+	return values, nil
+}
+
+// fieldArgs orders fields for XADD.
+func fieldArgs(fields map[string]string) []interface{} {
+	args := make([]interface{}, 0, 2*len(fields))
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	return args
+}
+
+func publishingFromValues(values map[string]interface{}) (amqp.Publishing, bool) {
+	field := func(name string) string {
+		value, _ := values[name].(string)
+		return value
+	}
+
+	publishing := amqp.Publishing{
+		MessageId:     field("message_id"),
+		CorrelationId: field("correlation_id"),
+		AppId:         field("app_id"),
+		Type:          field("type"),
+		ContentType:   field("content_type"),
+		Expiration:    field("expiration"),
+		DeliveryMode:  amqp.Persistent,
+		Body:          []byte(field("body")),
+	}
+	publishing.Timestamp, _ = time.Parse(time.RFC3339Nano, field("timestamp"))
+	publishing.Headers = decodeHeaders(field("headers"))
+	return publishing, field("redelivered") == "1"
+}
+
+// decodeHeaders reads headers written by streamValues. Whole numbers are
+// decoded as int64 and times stay RFC 3339 strings, both of which the
+// header helpers in interfaces accept.
+func decodeHeaders(encoded string) amqp.Table {
+	if encoded == "" || encoded == "null" {
+		return amqp.Table{}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(encoded)))
+	decoder.UseNumber()
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return amqp.Table{}
+	}
+	return decodeHeaderValue(raw).(amqp.Table)
+}
+
+func decodeHeaderValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		table := amqp.Table{}
+		for k, item := range v {
+			table[k] = decodeHeaderValue(item)
+		}
+		return table
+	case []interface{}:
+		for i, item := range v {
+			v[i] = decodeHeaderValue(item)
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+func (mq *redisMessageQueue) publish(ctx context.Context, publishing amqp.Publishing) error {
+	if mq.isClosed() {
+		return interfaces.ErrNotConnected
+	}
+
+	values, err := streamValues(publishing, false)
+	if err != nil {
+		return err
+	}
+
+	args := append([]interface{}{mq.topology.MaxLength, mq.topology.Overflow, redisConsumerGroup}, fieldArgs(values)...)
+	for {
+		result, err := publishScript.Run(ctx, mq.client, []string{mq.topology.MainQueue()}, args...).Result()
+		err = mq.observe(err)
+		if err == redis.Nil {
+			return &interfaces.PublishError{Reason: interfaces.ErrPublishNacked}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to publish message: %v", err)
+		}
+
+		head, ok := result.([]interface{})
+		if !ok {
+			return nil
+		}
+		if err := mq.dropHead(ctx, head); err != nil {
+			return fmt.Errorf("failed to publish message: %v", err)
+		}
+	}
+}
+
+// dropHead dead-letters an entry dropped from the main stream to the retry
+// queue, like the broker does with the head of a full queue.
+func (mq *redisMessageQueue) dropHead(ctx context.Context, entry []interface{}) error {
+	if len(entry) != 2 {
+		return fmt.Errorf("unexpected stream entry %v", entry)
+	}
+	id, _ := entry[0].(string)
+	fields, _ := entry[1].([]interface{})
+	values := make(map[string]interface{}, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, ok := fields[i].(string); ok {
+			values[key] = fields[i+1]
+		}
+	}
+
+	now := time.Now()
+	publishing, _ := publishingFromValues(values)
+	publishing.Headers = recordDeath(publishing.Headers, mq.topology.MainQueue(), mq.topology.MainExchange(), "maxlen", publishing.Expiration, now)
+	publishing.Expiration = ""
+	member, err := mq.delayedMember(mq.topology.RetryQueue(), publishing)
+	if err != nil {
+		return err
+	}
+
+	due := now.Add(mq.topology.RetryTTL).UnixMilli()
+	err = dropHeadScript.Run(ctx, mq.client, []string{mq.topology.MainQueue(), mq.topology.RetryQueue()}, id, due, member).Err()
+	if mq.observe(err) != nil {
+		return err
+	}
+	mq.wakePromoter()
+	return nil
+}
+
+func (mq *redisMessageQueue) PublishMessage(ctx context.Context, msg contracts.QueueMessage) error {
+	publishing, err := newPublishing(msg, mq.topology)
+	if err != nil {
+		return err
+	}
+	return mq.publish(ctx, publishing)
+}
+
+type redisPublisher struct {
+	mq *redisMessageQueue
+}
+
+// NewPublisher returns a publisher sharing the client's connection pool.
+func (mq *redisMessageQueue) NewPublisher() (interfaces.Publisher, error) {
+	if mq.isClosed() {
+		return nil, interfaces.ErrNotConnected
+	}
+	return &redisPublisher{mq: mq}, nil
+}
+
+func (p *redisPublisher) PublishMessage(ctx context.Context, msg contracts.QueueMessage) error {
+	return p.mq.PublishMessage(ctx, msg)
+}
+
+func (p *redisPublisher) Close() {}
+
+// delay adds a message to the delayed set in pipe, due at due. The caller
+// wakes the promoter once pipe has been executed.
+func (mq *redisMessageQueue) delay(ctx context.Context, pipe redis.Pipeliner, queue string, publishing amqp.Publishing, due time.Time) error {
+	member, err := mq.delayedMember(queue, publishing)
+	if err != nil {
+		return err
+	}
+
+	pipe.ZAdd(ctx, mq.topology.RetryQueue(), &redis.Z{Score: float64(due.UnixMilli()), Member: member})
+	return nil
+}
+
+// delayedMember encodes a message waiting in queue as a member of the
+// delayed set.
+func (mq *redisMessageQueue) delayedMember(queue string, publishing amqp.Publishing) (string, error) {
+	values, err := streamValues(publishing, false)
+	if err != nil {
+		return "", err
+	}
+	fields := make(map[string][]byte, len(values))
+	for k, v := range values {
+		fields[k] = []byte(v)
+	}
+	member, err := json.Marshal(redisDelayed{
+		Nonce:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		Queue:  queue,
+		Fields: fields,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal delayed message: %v", err)
+	}
+	return string(member), nil
+}
+
+func (mq *redisMessageQueue) wakePromoter() {
+	select {
+	case mq.promote <- struct{}{}:
+	default:
+	}
+}
+
+func (mq *redisMessageQueue) promoteLoop() {
+	for {
+		wait, err := mq.promoteDue(context.Background())
+		if err != nil && !mq.isClosed() {
+			log.Printf("Failed to promote delayed messages: %v", err)
+			wait = redisRetryDelay
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-mq.closed:
+			timer.Stop()
+			return
+		case <-mq.promote:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// promoteDue moves the due messages of the delayed set to the main stream
+// and returns how long until the next one is due.
+func (mq *redisMessageQueue) promoteDue(ctx context.Context) (time.Duration, error) {
+	for {
+		now := time.Now()
+		due, err := mq.client.ZRangeByScore(ctx, mq.topology.RetryQueue(), &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(now.UnixMilli(), 10),
+			Count: redisPromoteBatch,
+		}).Result()
+		if mq.observe(err) != nil {
+			return 0, err
+		}
+
+		for _, member := range due {
+			var delayed redisDelayed
+			if err := json.Unmarshal([]byte(member), &delayed); err != nil {
+				log.Printf("Dropping malformed delayed message: %v", err)
+				mq.client.ZRem(ctx, mq.topology.RetryQueue(), member)
+				continue
+			}
+
+			values := make(map[string]interface{}, len(delayed.Fields))
+			for k, v := range delayed.Fields {
+				values[k] = string(v)
+			}
+			publishing, _ := publishingFromValues(values)
+			publishing.Headers = recordDeath(publishing.Headers, delayed.Queue, mq.topology.RetryExchange(), "expired", "", now)
+			fields, err := streamValues(publishing, false)
+			if err != nil {
+				return 0, err
+			}
+
+			args := append([]interface{}{member}, fieldArgs(fields)...)
+			err = promoteScript.Run(ctx, mq.client, []string{mq.topology.RetryQueue(), mq.topology.MainQueue()}, args...).Err()
+			if mq.observe(err) != nil {
+				return 0, err
+			}
+		}
+
+		if len(due) < redisPromoteBatch {
+			break
+		}
+	}
+
+	next, err := mq.client.ZRangeWithScores(ctx, mq.topology.RetryQueue(), 0, 0).Result()
+	if mq.observe(err) != nil {
+		return 0, err
+	}
+	if len(next) == 0 {
+		return redisReadBlock, nil
+	}
+	wait := time.Until(time.UnixMilli(int64(next[0].Score)))
+	if wait > redisReadBlock {
+		wait = redisReadBlock
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, nil
+}
+
+// ConsumeMessages reads the stream through the consumer group, creating
+// both if needed. The group starts at the beginning of the stream, so
+// messages published before the first consumer are delivered too.
+func (mq *redisMessageQueue) ConsumeMessages(queueName string, prefetch int) (<-chan amqp.Delivery, error) {
+	if mq.isClosed() {
+		return nil, interfaces.ErrNotConnected
+	}
+	if prefetch < 0 {
+		return nil, fmt.Errorf("prefetch must not be negative, got %d", prefetch)
+	}
+
+	ctx := context.Background()
+	err := mq.client.XGroupCreateMkStream(ctx, queueName, redisConsumerGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		mq.observe(err)
+		return nil, fmt.Errorf("failed to create consumer group for %s: %v", queueName, err)
+	}
+
+	mq.mu.Lock()
+	mq.consumers++
+	name := fmt.Sprintf("%s-%d", mq.consumer, mq.consumers)
+	mq.mu.Unlock()
+
+	c := &redisConsumer{
+		mq:         mq,
+		stream:     queueName,
+		name:       name,
+		prefetch:   prefetch,
+		deliveries: make(chan amqp.Delivery),
+		settled:    make(chan struct{}, 1),
+		unacked:    make(map[uint64]redisUnacked),
+	}
+	go c.run()
+	return c.deliveries, nil
+}
+
+func heartbeatKey(stream string) string {
+	return stream + ".consumers"
+}
+
+// run claims stale deliveries and reads new ones while the consumer has
+// prefetch to spare. It is the only sender on deliveries and closes it when
+// the queue is closed.
+func (c *redisConsumer) run() {
+	defer close(c.deliveries)
+	defer c.mq.client.ZRem(context.Background(), heartbeatKey(c.stream), c.name)
+
+	ctx := context.Background()
+	for !c.mq.isClosed() {
+		c.mq.observe(c.mq.client.ZAdd(ctx, heartbeatKey(c.stream), &redis.Z{Score: float64(time.Now().UnixMilli()), Member: c.name}).Err())
+
+		available := c.available()
+		if available == 0 {
+			select {
+			case <-c.mq.closed:
+			case <-c.settled:
+			case <-time.After(redisReadBlock):
+			}
+			continue
+		}
+
+		messages, redelivered, err := c.read(ctx, available)
+		if err != nil {
+			if !c.mq.isClosed() {
+				log.Printf("Failed to read from %s: %v", c.stream, err)
+				select {
+				case <-c.mq.closed:
+				case <-time.After(redisRetryDelay):
+				}
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			publishing, requeued := publishingFromValues(message.Values)
+			if entryExpired(message.ID, publishing.Expiration, time.Now()) {
+				if err := c.deadLetterExpired(ctx, message.ID, publishing); err != nil && !c.mq.isClosed() {
+					log.Printf("Failed to dead-letter expired message %s: %v", publishing.MessageId, err)
+				}
+				continue
+			}
+
+			delivery := c.delivery(message.ID, publishing, requeued || redelivered)
+			select {
+			case <-c.mq.closed:
+				return
+			case c.deliveries <- delivery:
+			}
+		}
+	}
+}
+
+func (c *redisConsumer) available() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.prefetch == 0 {
+		return redisReadCount
+	}
+	return int64(c.prefetch - len(c.unacked))
+}
+
+// read claims up to count deliveries left unacknowledged for the claim idle
+// time, or else reads up to count new entries.
+func (c *redisConsumer) read(ctx context.Context, count int64) ([]redis.XMessage, bool, error) {
+	pending, err := c.mq.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.stream,
+		Group:  redisConsumerGroup,
+		Idle:   c.mq.claimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  count,
+	}).Result()
+	if err := c.mq.observe(err); err != nil && err != redis.Nil {
+		return nil, false, err
+	}
+
+	if len(pending) > 0 {
+		ids := make([]string, 0, len(pending))
+		for _, p := range pending {
+			ids = append(ids, p.ID)
+		}
+		// MinIdle makes the claim fail for entries another consumer has
+		// claimed in the meantime.
+		claimed, err := c.mq.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   c.stream,
+			Group:    redisConsumerGroup,
+			Consumer: c.name,
+			MinIdle:  c.mq.claimIdle,
+			Messages: ids,
+		}).Result()
+		if err := c.mq.observe(err); err != nil && err != redis.Nil {
+			return nil, false, err
+		}
+		if len(claimed) > 0 {
+			return claimed, true, nil
+		}
+	}
+
+	streams, err := c.mq.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    redisConsumerGroup,
+		Consumer: c.name,
+		Streams:  []string{c.stream, ">"},
+		Count:    count,
+		Block:    redisReadBlock,
+	}).Result()
+	if c.mq.observe(err) == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var messages []redis.XMessage
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+	return messages, false, nil
+}
+
+// entryExpired reports whether an entry has outlived its expiration, counted
+// from when it was added to the stream. Entry IDs start with that time in
+// milliseconds.
+func entryExpired(id, expiration string, now time.Time) bool {
+	ms, err := strconv.ParseInt(expiration, 10, 64)
+	if err != nil {
+		return false
+	}
+	added, err := strconv.ParseInt(strings.SplitN(id, "-", 2)[0], 10, 64)
+	if err != nil {
+		return false
+	}
+	return !time.UnixMilli(added).Add(time.Duration(ms) * time.Millisecond).After(now)
+}
+
+// deadLetterExpired moves an expired entry to the retry queue, like the
+// broker dead-letters expired messages from the main queue.
+func (c *redisConsumer) deadLetterExpired(ctx context.Context, id string, publishing amqp.Publishing) error {
+	now := time.Now()
+	publishing.Headers = recordDeath(publishing.Headers, c.stream, c.mq.topology.MainExchange(), "expired", publishing.Expiration, now)
+	publishing.Expiration = ""
+
+	pipe := c.mq.client.TxPipeline()
+	if err := c.mq.delay(ctx, pipe, c.mq.topology.RetryQueue(), publishing, now.Add(c.mq.topology.RetryTTL)); err != nil {
+		return err
+	}
+	c.remove(ctx, pipe, []redisUnacked{{id: id}})
+	_, err := pipe.Exec(ctx)
+	if c.mq.observe(err) != nil {
+		return err
+	}
+	c.mq.wakePromoter()
+	return nil
+}
+
+func (c *redisConsumer) delivery(id string, publishing amqp.Publishing, redelivered bool) amqp.Delivery {
+	c.mu.Lock()
+	c.nextTag++
+	tag := c.nextTag
+	c.unacked[tag] = redisUnacked{id: id, publishing: publishing}
+	c.mu.Unlock()
+
+	return amqp.Delivery{
+		Acknowledger:  c,
+		Headers:       publishing.Headers,
+		ContentType:   publishing.ContentType,
+		DeliveryMode:  publishing.DeliveryMode,
+		Expiration:    publishing.Expiration,
+		MessageId:     publishing.MessageId,
+		CorrelationId: publishing.CorrelationId,
+		AppId:         publishing.AppId,
+		Type:          publishing.Type,
+		Timestamp:     publishing.Timestamp,
+		ConsumerTag:   c.name,
+		DeliveryTag:   tag,
+		Redelivered:   redelivered,
+		RoutingKey:    c.stream,
+		Body:          publishing.Body,
+	}
+}
+
+// settle removes the deliveries that tag and multiple select and returns
+// them oldest first.
+func (c *redisConsumer) settle(tag uint64, multiple bool) ([]redisUnacked, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.unacked[tag]; !ok {
+		return nil, fmt.Errorf("unknown delivery tag %d", tag)
+	}
+
+	tags := []uint64{tag}
+	if multiple {
+		tags = tags[:0]
+		for t := uint64(1); t <= tag; t++ {
+			if _, ok := c.unacked[t]; ok {
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	settled := make([]redisUnacked, 0, len(tags))
+	for _, t := range tags {
+		settled = append(settled, c.unacked[t])
+		delete(c.unacked, t)
+	}
+
+	select {
+	case c.settled <- struct{}{}:
+	default:
+	}
+	return settled, nil
+}
+
+// remove acks and deletes the settled entries in pipe.
+func (c *redisConsumer) remove(ctx context.Context, pipe redis.Pipeliner, settled []redisUnacked) {
+	ids := make([]string, 0, len(settled))
+	for _, u := range settled {
+		ids = append(ids, u.id)
+	}
+	pipe.XAck(ctx, c.stream, redisConsumerGroup, ids...)
+	pipe.XDel(ctx, c.stream, ids...)
+}
+
+// An entry that fails to be acked stays pending and is claimed again after
+// the claim idle time.
+func (c *redisConsumer) Ack(tag uint64, multiple bool) error {
+	settled, err := c.settle(tag, multiple)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pipe := c.mq.client.TxPipeline()
+	c.remove(ctx, pipe, settled)
+	_, err = pipe.Exec(ctx)
+	if c.mq.observe(err) != nil {
+		return fmt.Errorf("failed to ack message: %v", err)
+	}
+	return nil
+}
+
+// Nack with requeue adds the messages to the end of the stream again,
+// marked as redelivered. Without requeue they wait in the retry queue for
+// the retry TTL, like messages the main queue dead-letters.
+func (c *redisConsumer) Nack(tag uint64, multiple bool, requeue bool) error {
+	settled, err := c.settle(tag, multiple)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	pipe := c.mq.client.TxPipeline()
+	for _, u := range settled {
+		if requeue {
+			values, err := streamValues(u.publishing, true)
+			if err != nil {
+				return err
+			}
+			pipe.XAdd(ctx, &redis.XAddArgs{Stream: c.stream, Values: fieldArgs(values)})
+			continue
+		}
+
+		publishing := u.publishing
+		publishing.Headers = recordDeath(u.publishing.Headers, c.stream, c.mq.topology.MainExchange(), "rejected", "", now)
+		if err := c.mq.delay(ctx, pipe, c.mq.topology.RetryQueue(), publishing, now.Add(c.mq.topology.RetryTTL)); err != nil {
+			return err
+		}
+	}
+	c.remove(ctx, pipe, settled)
+
+	_, err = pipe.Exec(ctx)
+	if c.mq.observe(err) != nil {
+		return fmt.Errorf("failed to nack message: %v", err)
+	}
+	if !requeue {
+		c.mq.wakePromoter()
+	}
+	return nil
+}
+
+func (c *redisConsumer) Reject(tag uint64, requeue bool) error {
+	return c.Nack(tag, false, requeue)
+}
+
+func (mq *redisMessageQueue) MoveToDeadLetter(msg *amqp.Delivery, failure interfaces.Failure) error {
+	if mq.isClosed() {
+		return interfaces.ErrNotConnected
+	}
+
+	now := time.Now()
+	values, err := streamValues(deadLetterPublishing(msg, failure, mq.topology, now), false)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	args := &redis.XAddArgs{Stream: mq.topology.DLQQueue(), Values: fieldArgs(values)}
+	if mq.topology.DLQMaxLength > 0 {
+		args.MaxLen = int64(mq.topology.DLQMaxLength)
+	}
+
+	pipe := mq.client.TxPipeline()
+	pipe.XAdd(ctx, args)
+	if mq.topology.DLQMessageTTL > 0 {
+		// Entry IDs start with their creation time in milliseconds.
+		minID := strconv.FormatInt(now.Add(-mq.topology.DLQMessageTTL).UnixMilli(), 10)
+		pipe.XTrimMinID(ctx, mq.topology.DLQQueue(), minID)
+	}
+	_, err = pipe.Exec(ctx)
+	if mq.observe(err) != nil {
+		return fmt.Errorf("failed to move message to DLQ: %v", err)
+	}
+	return nil
+}
+
+func (mq *redisMessageQueue) MoveToRetryQueue(msg *amqp.Delivery, tier int, delay time.Duration) error {
+	if mq.isClosed() {
+		return interfaces.ErrNotConnected
+	}
+
+	now := time.Now()
+	tier, publishing := retryPublishing(msg, tier, delay, mq.topology, now)
+	publishing.Expiration = ""
+
+	ctx := context.Background()
+	pipe := mq.client.TxPipeline()
+	if err := mq.delay(ctx, pipe, mq.topology.RetryTierQueue(tier), publishing, now.Add(delay)); err != nil {
+		return err
+	}
+	_, err := pipe.Exec(ctx)
+	if mq.observe(err) != nil {
+		return fmt.Errorf("failed to move message to retry queue: %v", err)
+	}
+	mq.wakePromoter()
+	return nil
+}
+
+// trimDLQ drops the DLQ entries older than the DLQ message TTL.
+func (mq *redisMessageQueue) trimDLQ(ctx context.Context) error {
+	if mq.topology.DLQMessageTTL <= 0 {
+		return nil
+	}
+	minID := strconv.FormatInt(time.Now().Add(-mq.topology.DLQMessageTTL).UnixMilli(), 10)
+	return mq.observe(mq.client.XTrimMinID(ctx, mq.topology.DLQQueue(), minID).Err())
+}
+
+func (mq *redisMessageQueue) GetDLQMessageCount() (int, error) {
+	ctx := context.Background()
+	if err := mq.trimDLQ(ctx); err != nil {
+		return 0, fmt.Errorf("failed to inspect DLQ: %v", err)
+	}
+
+	count, err := mq.client.XLen(ctx, mq.topology.DLQQueue()).Result()
+	if mq.observe(err) != nil {
+		return 0, fmt.Errorf("failed to inspect DLQ: %v", err)
+	}
+	return int(count), nil
+}
+
+func deadLetterFromEntry(message redis.XMessage) interfaces.DeadLetter {
+	publishing, _ := publishingFromValues(message.Values)
+	return interfaces.DeadLetter{
+		MessageID:   publishing.MessageId,
+		ContentType: publishing.ContentType,
+		Body:        publishing.Body,
+		Headers:     publishing.Headers,
+		Timestamp:   publishing.Timestamp,
+	}
+}
+
+func (mq *redisMessageQueue) BrowseDLQ(offset, limit int) ([]interfaces.DeadLetter, int, error) {
+	ctx := context.Background()
+	total, err := mq.GetDLQMessageCount()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries, err := mq.client.XRangeN(ctx, mq.topology.DLQQueue(), "-", "+", int64(offset+limit)).Result()
+	if mq.observe(err) != nil {
+		return nil, 0, fmt.Errorf("failed to read DLQ: %v", err)
+	}
+
+	var messages []interfaces.DeadLetter
+	for i := offset; i < len(entries); i++ {
+		messages = append(messages, deadLetterFromEntry(entries[i]))
+	}
+	return messages, total, nil
+}
+
+// ReplayDLQ passes each entry that was in the DLQ when the replay started to
+// replay. A replayed entry is deleted only after its replacement has been
+// published.
+func (mq *redisMessageQueue) ReplayDLQ(ctx context.Context, replay interfaces.ReplayFunc) (int, error) {
+	if err := mq.trimDLQ(ctx); err != nil {
+		return 0, fmt.Errorf("failed to inspect DLQ: %v", err)
+	}
+
+	last, err := mq.client.XRevRangeN(ctx, mq.topology.DLQQueue(), "+", "-", 1).Result()
+	if mq.observe(err) != nil {
+		return 0, fmt.Errorf("failed to inspect DLQ: %v", err)
+	}
+	if len(last) == 0 {
+		return 0, nil
+	}
+
+	replayed := 0
+	start := "-"
+	for {
+		entries, err := mq.client.XRangeN(ctx, mq.topology.DLQQueue(), start, last[0].ID, redisPromoteBatch).Result()
+		if mq.observe(err) != nil {
+			return replayed, fmt.Errorf("failed to read DLQ: %v", err)
+		}
+
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return replayed, err
+			}
+
+			msg, err := replay(deadLetterFromEntry(entry))
+			if err != nil {
+				return replayed, err
+			}
+			if msg == nil {
+				continue
+			}
+
+			if err := mq.PublishMessage(ctx, *msg); err != nil {
+				return replayed, fmt.Errorf("failed to replay message %s: %v", msg.ID, err)
+			}
+			if err := mq.observe(mq.client.XDel(ctx, mq.topology.DLQQueue(), entry.ID).Err()); err != nil {
+				return replayed, fmt.Errorf("failed to remove replayed message %s from DLQ: %v", msg.ID, err)
+			}
+			replayed++
+		}
+
+		if len(entries) < redisPromoteBatch {
+			return replayed, nil
+		}
+		// An exclusive start continues after the last entry read.
+		start = "(" + entries[len(entries)-1].ID
+	}
+}
+
+func (mq *redisMessageQueue) PurgeDLQ() (int, error) {
+	ctx := context.Background()
+	pipe := mq.client.TxPipeline()
+	count := pipe.XLen(ctx, mq.topology.DLQQueue())
+	pipe.Del(ctx, mq.topology.DLQQueue())
+	_, err := pipe.Exec(ctx)
+	if mq.observe(err) != nil {
+		return 0, fmt.Errorf("failed to purge DLQ: %v", err)
+	}
+	return int(count.Val()), nil
+}
+
+// GetQueueStats reports the entries of a stream not yet delivered or
+// awaiting an ack, and the consumers that sent a heartbeat recently. The
+// retry queue reports the delayed messages of every tier, so the tier queues,
+// which share its sorted set, report none of their own.
+func (mq *redisMessageQueue) GetQueueStats(queueName string) (interfaces.QueueStats, error) {
+	ctx := context.Background()
+	if queueName == mq.topology.RetryQueue() {
+		count, err := mq.client.ZCard(ctx, queueName).Result()
+		if mq.observe(err) != nil {
+			return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: %v", queueName, err)
+		}
+		return interfaces.QueueStats{Messages: int(count)}, nil
+	}
+	for tier := range mq.topology.RetryBackoff {
+		if queueName == mq.topology.RetryTierQueue(tier) {
+			return interfaces.QueueStats{}, nil
+		}
+	}
+	if queueName != mq.topology.MainQueue() && queueName != mq.topology.DLQQueue() {
+		return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: no such queue", queueName)
+	}
+	if queueName == mq.topology.DLQQueue() {
+		if err := mq.trimDLQ(ctx); err != nil {
+			return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: %v", queueName, err)
+		}
+	}
+
+	length, err := mq.client.XLen(ctx, queueName).Result()
+	if mq.observe(err) != nil {
+		return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: %v", queueName, err)
+	}
+
+	var pending int64
+	summary, err := mq.client.XPending(ctx, queueName, redisConsumerGroup).Result()
+	switch {
+	case err == nil:
+		pending = summary.Count
+	case strings.HasPrefix(err.Error(), "NOGROUP"):
+	default:
+		mq.observe(err)
+		return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: %v", queueName, err)
+	}
+
+	since := strconv.FormatInt(time.Now().Add(-redisConsumerTimeout).UnixMilli(), 10)
+	consumers, err := mq.client.ZCount(ctx, heartbeatKey(queueName), since, "+inf").Result()
+	if mq.observe(err) != nil {
+		return interfaces.QueueStats{}, fmt.Errorf("failed to inspect queue %s: %v", queueName, err)
+	}
+
+	return interfaces.QueueStats{Messages: int(length - pending), Consumers: int(consumers)}, nil
+}
+
+// Close stops the consumers and the promoter. Unacked deliveries stay
+// pending and are claimed by another consumer after the claim idle time.
+// The Redis client is left open for its owner to close.
+func (mq *redisMessageQueue) Close() {
+	mq.closeOnce.Do(func() { close(mq.closed) })
+}
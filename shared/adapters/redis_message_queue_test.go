@@ -0,0 +1,90 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/interfaces"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisQueue(t *testing.T, client *redis.Client, topology contracts.Topology, consumer string, claimIdle time.Duration) interfaces.MessageQueue {
+	mq, err := NewRedisMessageQueue(client, topology, consumer, claimIdle)
+	require.NoError(t, err)
+	t.Cleanup(mq.Close)
+	return mq
+}
+
+func newTestRedisClient(t *testing.T) *redis.Client {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRedisMessageQueue(t *testing.T) {
+	testMessageQueueBehaviour(t, func(t *testing.T, topology contracts.Topology) interfaces.MessageQueue {
+		return newTestRedisQueue(t, newTestRedisClient(t), topology, "test", time.Minute)
+	})
+}
+
+func TestRedisMessageQueue_ClaimsDeliveriesOfDeadConsumer(t *testing.T) {
+	topology := testTopology()
+	client := newTestRedisClient(t)
+
+	crashed := newTestRedisQueue(t, client, topology, "crashed", time.Minute)
+	require.NoError(t, crashed.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+	deliveries, err := crashed.ConsumeMessages(topology.MainQueue(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "m1", receive(t, deliveries).MessageId)
+	crashed.Close()
+
+	survivor := newTestRedisQueue(t, client, topology, "survivor", 50*time.Millisecond)
+	deliveries, err = survivor.ConsumeMessages(topology.MainQueue(), 0)
+	require.NoError(t, err)
+
+	d := receive(t, deliveries)
+	assert.Equal(t, "m1", d.MessageId)
+	assert.True(t, d.Redelivered)
+	require.NoError(t, d.Ack(false))
+
+	stats, err := survivor.GetQueueStats(topology.MainQueue())
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Messages)
+}
+
+func TestRedisMessageQueue_DropHeadKeepsEntriesBeingDelivered(t *testing.T) {
+	topology := testTopology()
+	topology.MaxLength = 1
+	mq := newTestRedisQueue(t, newTestRedisClient(t), topology, "test", time.Minute)
+
+	require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m1"}))
+	deliveries, err := mq.ConsumeMessages(topology.MainQueue(), 0)
+	require.NoError(t, err)
+	d := receive(t, deliveries)
+	assert.Equal(t, "m1", d.MessageId)
+
+	require.NoError(t, mq.PublishMessage(context.Background(), contracts.QueueMessage{ID: "m2"}))
+	stats, err := mq.GetQueueStats(topology.RetryQueue())
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Messages)
+
+	require.NoError(t, d.Ack(false))
+	assert.Equal(t, "m2", receive(t, deliveries).MessageId)
+}
+
+func TestRedisMessageQueue_ConnectFailure(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	server.Close()
+
+	_, err := NewRedisMessageQueue(client, testTopology(), "test", time.Minute)
+	assert.Error(t, err)
+}
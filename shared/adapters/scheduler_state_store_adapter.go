@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+
+	"github.com/sony/gobreaker"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSchedulerStateStore struct {
+	collection *mongo.Collection
+	cb         *gobreaker.CircuitBreaker
+}
+
+func NewSchedulerStateStore(db *mongo.Database) interfaces.SchedulerStateStore {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "mongodb-scheduler-state",
+		MaxRequests: 3,
+		Interval:    10 * time.Second,
+		Timeout:     30 * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= 3 && failureRatio >= 0.6
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			fmt.Printf("Circuit breaker %s state changed from %s to %s\n", name, from, to)
+		},
+	})
+
+	return &mongoSchedulerStateStore{
+		collection: db.Collection("scheduler_state"),
+		cb:         cb,
+	}
+}
+
+func (s *mongoSchedulerStateStore) LoadSchedulerState(ctx context.Context, name string) (*models.SchedulerState, error) {
+	result, err := s.cb.Execute(func() (interface{}, error) {
+		var state models.SchedulerState
+		err := s.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&state)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &state, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result.(*models.SchedulerState), nil
+}
+
+func (s *mongoSchedulerStateStore) SaveSchedulerState(ctx context.Context, state *models.SchedulerState) error {
+	_, err := s.cb.Execute(func() (interface{}, error) {
+		state.UpdatedAt = time.Now()
+		_, err := s.collection.ReplaceOne(ctx, bson.M{"_id": state.Name}, state, options.Replace().SetUpsert(true))
+		return nil, err
+	})
+
+	if err != nil {
+		return fmt.Errorf("circuit breaker error: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"strings"
+)
+
+const (
+	APIKeyHeader   = "X-API-Key"
+	APIKeyMetadata = "x-api-key"
+)
+
+// APIKeys validates client keys shared by the REST and gRPC transports. An
+// empty key set disables authentication.
+type APIKeys struct {
+	keys [][]byte
+}
+
+func NewAPIKeys(keys []string) *APIKeys {
+	a := &APIKeys{}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			a.keys = append(a.keys, []byte(key))
+		}
+	}
+	return a
+}
+
+func (a *APIKeys) Enabled() bool {
+	return len(a.keys) > 0
+}
+
+func (a *APIKeys) Valid(key string) bool {
+	if !a.Enabled() {
+		return true
+	}
+
+	valid := false
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(k, []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeys(t *testing.T) {
+	t.Run("disabled when no keys configured", func(t *testing.T) {
+		keys := NewAPIKeys([]string{"", "  "})
+
+		assert.False(t, keys.Enabled())
+		assert.True(t, keys.Valid(""))
+	})
+
+	t.Run("validates configured keys", func(t *testing.T) {
+		keys := NewAPIKeys([]string{"first", " second "})
+
+		assert.True(t, keys.Enabled())
+		assert.True(t, keys.Valid("first"))
+		assert.True(t, keys.Valid("second"))
+		assert.False(t, keys.Valid(""))
+		assert.False(t, keys.Valid("third"))
+	})
+}
@@ -0,0 +1,19 @@
+package correlation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Equal(t, "abc", FromContext(WithID(context.Background(), "abc")))
+}
+
+func TestNewID(t *testing.T) {
+	id := NewID()
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, NewID())
+}
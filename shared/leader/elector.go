@@ -0,0 +1,213 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/mongodb/interfaces"
+)
+
+var ErrNotLeader = errors.New("not the current leader")
+
+// Leadership is what singleton jobs need from an elector: a cheap local check
+// before each run and a fenced check against the lease store before writes.
+type Leadership interface {
+	IsLeader() bool
+	Fence(ctx context.Context) error
+	Status() Status
+}
+
+type Status struct {
+	Name     string `json:"name"`
+	Holder   string `json:"holder"`
+	IsLeader bool   `json:"is_leader"`
+	Token    int64  `json:"token,omitempty"`
+}
+
+type Elector struct {
+	store         interfaces.LeaseStore
+	name          string
+	holder        string
+	ttl           time.Duration
+	renewInterval time.Duration
+
+	mu        sync.RWMutex
+	isLeader  bool
+	token     int64
+	expiresAt time.Time
+}
+
+func NewElector(store interfaces.LeaseStore, name string, holder string, ttl time.Duration) *Elector {
+	return &Elector{
+		store:         store,
+		name:          name,
+		holder:        holder,
+		ttl:           ttl,
+		renewInterval: ttl / 3,
+	}
+}
+
+// Run campaigns for the lease and keeps renewing it until ctx is cancelled,
+// then releases it so another instance can take over without waiting for expiry.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	e.tick(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			e.tick(ctx)
+		case <-ctx.Done():
+			e.release()
+			return
+		}
+	}
+}
+
+// Start returns AlwaysLeader when election is disabled. Otherwise it campaigns
+// for the named lease in the background; the returned stop function releases
+// the lease and waits for the campaign to end.
+func Start(enabled bool, store interfaces.LeaseStore, name string, holder string, ttl time.Duration) (Leadership, func()) {
+	if !enabled {
+		return AlwaysLeader{Name: name, Holder: holder}, func() {}
+	}
+
+	elector := NewElector(store, name, holder, ttl)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		elector.Run(ctx)
+		close(done)
+	}()
+
+	return elector, func() {
+		cancel()
+		<-done
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.renewInterval)
+	defer cancel()
+
+	lease, err := e.store.Acquire(ctx, e.name, e.holder, e.ttl)
+	if err != nil {
+		log.Printf("Leader election %s: failed to renew lease: %v", e.name, err)
+		e.mu.Lock()
+		// Step down before the lease can expire so two leaders never overlap.
+		if e.isLeader && time.Now().Add(e.renewInterval).After(e.expiresAt) {
+			e.isLeader = false
+			log.Printf("Leader election %s: %s stepped down, lease could not be renewed", e.name, e.holder)
+		}
+		e.mu.Unlock()
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if lease == nil || lease.Holder != e.holder {
+		if e.isLeader {
+			log.Printf("Leader election %s: %s lost leadership", e.name, e.holder)
+		}
+		e.isLeader = false
+		return
+	}
+
+	if !e.isLeader || e.token != lease.Token {
+		log.Printf("Leader election %s: %s is now leader with token %d", e.name, e.holder, lease.Token)
+	}
+	e.isLeader = true
+	e.token = lease.Token
+	e.expiresAt = lease.ExpiresAt
+}
+
+func (e *Elector) release() {
+	e.mu.Lock()
+	wasLeader := e.isLeader
+	e.isLeader = false
+	e.mu.Unlock()
+
+	if !wasLeader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.store.Release(ctx, e.name, e.holder); err != nil {
+		log.Printf("Leader election %s: failed to release lease: %v", e.name, err)
+	}
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// Fence confirms with the lease store that this instance's fencing token is
+// still the current one. A paused leader whose lease was taken over fails here
+// even if its local state has not caught up yet.
+func (e *Elector) Fence(ctx context.Context) error {
+	e.mu.RLock()
+	isLeader, token := e.isLeader, e.token
+	e.mu.RUnlock()
+
+	if !isLeader {
+		return ErrNotLeader
+	}
+
+	current, err := e.store.IsCurrent(ctx, e.name, token)
+	if err != nil {
+		return fmt.Errorf("failed to check fencing token: %v", err)
+	}
+	if !current {
+		e.mu.Lock()
+		if e.token == token {
+			e.isLeader = false
+		}
+		e.mu.Unlock()
+		return ErrNotLeader
+	}
+
+	return nil
+}
+
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	status := Status{
+		Name:     e.name,
+		Holder:   e.holder,
+		IsLeader: e.isLeader,
+	}
+	if e.isLeader {
+		status.Token = e.token
+	}
+	return status
+}
+
+// AlwaysLeader is used when leader election is disabled and the instance is
+// known to be the only one running.
+type AlwaysLeader struct {
+	Name   string
+	Holder string
+}
+
+func (a AlwaysLeader) IsLeader() bool {
+	return true
+}
+
+func (a AlwaysLeader) Fence(ctx context.Context) error {
+	return nil
+}
+
+func (a AlwaysLeader) Status() Status {
+	return Status{Name: a.Name, Holder: a.Holder, IsLeader: true}
+}
@@ -0,0 +1,160 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLeaseStore struct {
+	mu    sync.Mutex
+	lease *models.Lease
+	now   time.Time
+	err   error
+}
+
+func newFakeLeaseStore() *fakeLeaseStore {
+	return &fakeLeaseStore{now: time.Now()}
+}
+
+func (f *fakeLeaseStore) Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (*models.Lease, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.lease == nil {
+		f.lease = &models.Lease{Name: name}
+	}
+	if f.lease.Holder == holder && f.lease.ExpiresAt.After(f.now) {
+		f.lease.ExpiresAt = f.now.Add(ttl)
+	} else if !f.lease.ExpiresAt.After(f.now) {
+		f.lease.Holder = holder
+		f.lease.Token++
+		f.lease.ExpiresAt = f.now.Add(ttl)
+	} else {
+		return nil, nil
+	}
+	lease := *f.lease
+	return &lease, nil
+}
+
+func (f *fakeLeaseStore) Release(ctx context.Context, name string, holder string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.lease != nil && f.lease.Holder == holder {
+		f.lease.ExpiresAt = f.now
+	}
+	return nil
+}
+
+func (f *fakeLeaseStore) IsCurrent(ctx context.Context, name string, token int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.lease != nil && f.lease.Token == token && f.lease.ExpiresAt.After(f.now), nil
+}
+
+func (f *fakeLeaseStore) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func TestElector_SingleLeader(t *testing.T) {
+	store := newFakeLeaseStore()
+	first := NewElector(store, "job", "instance-1", 15*time.Second)
+	second := NewElector(store, "job", "instance-2", 15*time.Second)
+
+	first.tick(context.Background())
+	second.tick(context.Background())
+
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+	assert.NoError(t, first.Fence(context.Background()))
+	assert.ErrorIs(t, second.Fence(context.Background()), ErrNotLeader)
+	assert.Equal(t, Status{Name: "job", Holder: "instance-1", IsLeader: true, Token: 1}, first.Status())
+}
+
+func TestElector_TakeoverBumpsFencingToken(t *testing.T) {
+	store := newFakeLeaseStore()
+	first := NewElector(store, "job", "instance-1", 15*time.Second)
+	second := NewElector(store, "job", "instance-2", 15*time.Second)
+
+	first.tick(context.Background())
+	store.advance(20 * time.Second)
+	second.tick(context.Background())
+
+	assert.True(t, second.IsLeader())
+	assert.Equal(t, int64(2), second.Status().Token)
+
+	// The old leader has not noticed yet, but its stale token is rejected.
+	assert.True(t, first.IsLeader())
+	assert.ErrorIs(t, first.Fence(context.Background()), ErrNotLeader)
+	assert.False(t, first.IsLeader())
+}
+
+func TestElector_RenewKeepsToken(t *testing.T) {
+	store := newFakeLeaseStore()
+	elector := NewElector(store, "job", "instance-1", 15*time.Second)
+
+	elector.tick(context.Background())
+	store.advance(5 * time.Second)
+	elector.tick(context.Background())
+
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, int64(1), elector.Status().Token)
+}
+
+func TestElector_StepsDownWhenRenewalFails(t *testing.T) {
+	store := newFakeLeaseStore()
+	elector := NewElector(store, "job", "instance-1", 15*time.Second)
+	elector.tick(context.Background())
+
+	store.err = errors.New("connection refused")
+	elector.tick(context.Background())
+	assert.True(t, elector.IsLeader())
+
+	elector.mu.Lock()
+	elector.expiresAt = time.Now().Add(time.Second)
+	elector.mu.Unlock()
+	elector.tick(context.Background())
+	assert.False(t, elector.IsLeader())
+}
+
+func TestElector_RunReleasesLease(t *testing.T) {
+	store := newFakeLeaseStore()
+	first := NewElector(store, "job", "instance-1", 15*time.Second)
+	second := NewElector(store, "job", "instance-2", 15*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		first.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, first.IsLeader, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	assert.False(t, first.IsLeader())
+	second.tick(context.Background())
+	assert.True(t, second.IsLeader())
+}
+
+func TestStart_Disabled(t *testing.T) {
+	leadership, stop := Start(false, nil, "job", "instance-1", 15*time.Second)
+	defer stop()
+
+	assert.True(t, leadership.IsLeader())
+	assert.NoError(t, leadership.Fence(context.Background()))
+	assert.Equal(t, Status{Name: "job", Holder: "instance-1", IsLeader: true}, leadership.Status())
+}
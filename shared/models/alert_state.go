@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AlertState is the firing alert of a rule that only the leader evaluates.
+// It is shared by the instances, so a new leader neither reports the alert
+// again nor misses its resolve.
+type AlertState struct {
+	Rule      string    `bson:"_id" json:"rule"`
+	Summary   string    `bson:"summary" json:"summary"`
+	Value     float64   `bson:"value" json:"value"`
+	Threshold float64   `bson:"threshold" json:"threshold"`
+	Instance  string    `bson:"instance,omitempty" json:"instance,omitempty"`
+	StartsAt  time.Time `bson:"starts_at" json:"starts_at"`
+}
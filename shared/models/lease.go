@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Lease struct {
+	Name       string    `bson:"_id" json:"name"`
+	Holder     string    `bson:"holder" json:"holder"`
+	Token      int64     `bson:"token" json:"token"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"`
+}
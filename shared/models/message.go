@@ -22,6 +22,13 @@ type Message struct {
 	Content   string            `bson:"content" json:"content"`
 	Status    MessageStatus     `bson:"status" json:"status"`
 	RetryCount int              `bson:"retry_count" json:"retry_count"`
+	// RetryTier and NextRetryAt describe the backoff tier the message is
+	// waiting in after its last failed attempt. NextRetryAt is the latest the
+	// message can return from the tier.
+	RetryTier   *int       `bson:"retry_tier,omitempty" json:"retry_tier,omitempty"`
+	NextRetryAt *time.Time `bson:"next_retry_at,omitempty" json:"next_retry_at,omitempty"`
+	// CorrelationID ties the message to the request that created it.
+	CorrelationID string `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
 	CreatedAt time.Time         `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time         `bson:"updated_at" json:"updated_at"`
 } 
\ No newline at end of file
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	EventMessageCreated = "message.created"
+)
+
+// OutboxEvent is an entry in the outbox collection. It is written in the same
+// transaction as the change it describes and deleted once a relay has
+// published it. Payload is specific to Type.
+type OutboxEvent struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Type           string             `bson:"type"`
+	AggregateID    primitive.ObjectID `bson:"aggregate_id"`
+	Payload        bson.Raw           `bson:"payload"`
+	Attempts       int                `bson:"attempts"`
+	LastError      string             `bson:"last_error,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	ClaimOwner     string             `bson:"claim_owner,omitempty"`
+	ClaimExpiresAt *time.Time         `bson:"claim_expires_at,omitempty"`
+}
+
+type MessageCreatedPayload struct {
+	To         string `bson:"to"`
+	Content    string `bson:"content"`
+	RetryCount int    `bson:"retry_count"`
+	// CorrelationID is empty for events written before it was recorded.
+	CorrelationID string `bson:"correlation_id,omitempty"`
+}
+
+// NewMessageCreatedEvent builds the event announcing msg. The event reuses the
+// message ID, so a message can have at most one pending created event.
+func NewMessageCreatedEvent(msg *Message) (*OutboxEvent, error) {
+	payload, err := bson.Marshal(MessageCreatedPayload{
+		To:            msg.To,
+		Content:       msg.Content,
+		RetryCount:    msg.RetryCount,
+		CorrelationID: msg.CorrelationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		ID:          msg.ID,
+		Type:        EventMessageCreated,
+		AggregateID: msg.ID,
+		Payload:     payload,
+		CreatedAt:   msg.CreatedAt,
+	}, nil
+}
+
+func (e *OutboxEvent) DecodePayload(v interface{}) error {
+	return bson.Unmarshal(e.Payload, v)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewMessageCreatedEvent(t *testing.T) {
+	msg := &Message{
+		ID:         primitive.NewObjectID(),
+		To:         "+905321234567",
+		Content:    "test message",
+		Status:     StatusUnsent,
+		RetryCount: 1,
+		CreatedAt:  time.Now(),
+	}
+
+	event, err := NewMessageCreatedEvent(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.ID, event.ID)
+	assert.Equal(t, msg.ID, event.AggregateID)
+	assert.Equal(t, EventMessageCreated, event.Type)
+
+	var payload MessageCreatedPayload
+	assert.NoError(t, event.DecodePayload(&payload))
+	assert.Equal(t, MessageCreatedPayload{To: msg.To, Content: msg.Content, RetryCount: 1}, payload)
+}
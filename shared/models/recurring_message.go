@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RecurringMessage creates a message for every occurrence of a cron
+// schedule. Content is a text/template rendered for each occurrence.
+type RecurringMessage struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Schedule  string             `bson:"schedule" json:"schedule"`
+	Timezone  string             `bson:"timezone" json:"timezone"`
+	Content   string             `bson:"content" json:"content"`
+	To        string             `bson:"to" json:"to"`
+	Paused    bool               `bson:"paused" json:"paused"`
+	NextRunAt time.Time          `bson:"next_run_at" json:"next_run_at"`
+	LastRunAt *time.Time         `bson:"last_run_at,omitempty" json:"last_run_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SchedulerState is the persisted part of a scheduler's configuration, so a
+// restarted instance resumes with the same settings and running state.
+type SchedulerState struct {
+	Name            string    `bson:"_id" json:"name"`
+	Running         bool      `bson:"running" json:"running"`
+	BatchSize       int       `bson:"batch_size" json:"batch_size"`
+	IntervalSeconds int       `bson:"interval_seconds" json:"interval_seconds"`
+	UpdatedAt       time.Time `bson:"updated_at" json:"updated_at"`
+}
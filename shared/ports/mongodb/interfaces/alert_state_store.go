@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+)
+
+type AlertStateStore interface {
+	// LoadAlertState returns nil when the rule is not firing.
+	LoadAlertState(ctx context.Context, rule string) (*models.AlertState, error)
+	// CreateAlertState records that a rule started firing. It reports false
+	// if the rule is already firing.
+	CreateAlertState(ctx context.Context, state *models.AlertState) (bool, error)
+	// DeleteAlertState records that a rule resolved. It reports false if the
+	// rule was not firing.
+	DeleteAlertState(ctx context.Context, rule string) (bool, error)
+}
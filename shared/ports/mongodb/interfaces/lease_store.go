@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+)
+
+type LeaseStore interface {
+	// Acquire renews the lease when holder already owns it, takes it over when
+	// it is free or expired, and returns nil when another holder owns it.
+	Acquire(ctx context.Context, name string, holder string, ttl time.Duration) (*models.Lease, error)
+	Release(ctx context.Context, name string, holder string) error
+	IsCurrent(ctx context.Context, name string, token int64) (bool, error)
+}
@@ -12,9 +12,26 @@ import (
 type MessageRepository interface {
 	FindUnsentMessages(ctx context.Context, limit int) ([]models.Message, error)
 	UpdateStatus(ctx context.Context, id primitive.ObjectID, status models.MessageStatus) error
+	// TransitionStatus sets the status only if the message is still in from.
+	// It reports whether the message was updated.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (bool, error)
+	// TransitionStatuses is the bulk form of TransitionStatus. It returns the
+	// number of messages updated.
+	TransitionStatuses(ctx context.Context, ids []primitive.ObjectID, from models.MessageStatus, to models.MessageStatus) (int, error)
 	IncrementRetryCount(ctx context.Context, id primitive.ObjectID) error
+	// ScheduleRetry records the backoff tier of a failed message and when it
+	// is due to be delivered again.
+	ScheduleRetry(ctx context.Context, id primitive.ObjectID, tier int, nextRetryAt time.Time) error
+	// ResetRetries clears the retry count and backoff tier of a message and
+	// sets it back to processing, so it can be delivered again from the DLQ.
+	ResetRetries(ctx context.Context, id primitive.ObjectID) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Message, error)
+	// CreateMessage and CreateMessages write each message together with its
+	// message.created outbox event in one transaction.
 	CreateMessage(ctx context.Context, msg *models.Message) error
+	CreateMessages(ctx context.Context, msgs []*models.Message) error
 	ListMessages(ctx context.Context) ([]models.Message, error)
+	// FindStaleProcessingMessages returns processing messages not updated
+	// within staleDuration, ignoring messages still waiting for a retry.
 	FindStaleProcessingMessages(ctx context.Context, staleDuration time.Duration) ([]models.Message, error)
-} 
\ No newline at end of file
+}
@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrClaimNotHeld = errors.New("outbox event claim is not held by this owner")
+
+type OutboxRepository interface {
+	// ClaimEvents atomically leases up to limit pending events of the given
+	// types to owner, oldest first. Events whose lease has expired are
+	// claimable again.
+	ClaimEvents(ctx context.Context, types []string, owner string, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	// ClaimEvent leases a single event. It returns nil when the event is
+	// already claimed or no longer pending.
+	ClaimEvent(ctx context.Context, id primitive.ObjectID, types []string, owner string, lease time.Duration) (*models.OutboxEvent, error)
+	// CompleteEvent deletes a published event. It returns ErrClaimNotHeld
+	// when owner's lease has been taken over.
+	CompleteEvent(ctx context.Context, id primitive.ObjectID, owner string) error
+	// CompleteEvents deletes the published events still claimed by owner and
+	// returns how many were deleted.
+	CompleteEvents(ctx context.Context, ids []primitive.ObjectID, owner string) (int, error)
+	ReleaseEvent(ctx context.Context, id primitive.ObjectID, owner string, lastErr string) error
+	// EnqueueUnsentMessages creates message.created events for unsent
+	// messages that have none, such as messages written before the outbox
+	// collection existed.
+	EnqueueUnsentMessages(ctx context.Context) (int, error)
+	// OldestEvent returns the oldest event still in the outbox, or nil when
+	// the outbox is empty.
+	OldestEvent(ctx context.Context) (*models.OutboxEvent, error)
+	// WatchInserts calls handle for every event inserted after the stream's
+	// saved resume token and blocks until ctx is done or the stream fails. The
+	// token is saved only after handle succeeds.
+	WatchInserts(ctx context.Context, stream string, handle func(ctx context.Context, id primitive.ObjectID) error) error
+}
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RecurringMessageRepository interface {
+	Create(ctx context.Context, recurring *models.RecurringMessage) error
+	List(ctx context.Context) ([]models.RecurringMessage, error)
+	// GetByID returns nil when the definition does not exist.
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.RecurringMessage, error)
+	// SetPaused and Delete report whether the definition exists.
+	SetPaused(ctx context.Context, id primitive.ObjectID, paused bool, nextRunAt time.Time) (bool, error)
+	Delete(ctx context.Context, id primitive.ObjectID) (bool, error)
+	// FindDue returns active definitions whose next run is at or before now.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]models.RecurringMessage, error)
+	// RecordOccurrence writes msg with its message.created outbox event and
+	// moves the definition's next run from occurrence to next, in one
+	// transaction. It writes nothing and returns false when the definition's
+	// next run is no longer occurrence, e.g. because the occurrence was
+	// already recorded or the definition was paused or deleted.
+	RecordOccurrence(ctx context.Context, id primitive.ObjectID, occurrence time.Time, next time.Time, msg *models.Message) (bool, error)
+}
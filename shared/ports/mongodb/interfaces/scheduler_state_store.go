@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/models"
+)
+
+type SchedulerStateStore interface {
+	// LoadSchedulerState returns nil when no state has been saved yet.
+	LoadSchedulerState(ctx context.Context, name string) (*models.SchedulerState, error)
+	SaveSchedulerState(ctx context.Context, state *models.SchedulerState) error
+}
@@ -1,8 +1,147 @@
 package contracts
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts/queuev2"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// SchemaVersionHeader records the schema version of a message body.
+// Messages without it were published before the schema was versioned and
+// are version 1.
+const SchemaVersionHeader = "x-schema-version"
+
+const (
+	// SchemaVersion1 is the bare JSON encoding of QueueMessage.
+	SchemaVersion1 = 1
+	// SchemaVersion2 wraps the message in an Envelope, encoded as JSON or
+	// protobuf.
+	SchemaVersion2 = 2
+
+	CurrentSchemaVersion = SchemaVersion2
+)
+
+// QueueMessageType is the envelope type of a message to deliver.
+const QueueMessageType = "message.send"
+
+var ErrUnsupportedSchema = errors.New("unsupported message schema")
+
+// AppID is the AMQP app-id of every message the services publish.
+const AppID = "reliable-messaging-system"
+
+// QueueMessage is a message for the processor to deliver. CorrelationID
+// travels as the correlation-id property rather than in the body.
 type QueueMessage struct {
-	ID      string `json:"id"`
-	Content string `json:"content"`
+	ID            string `json:"id"`
+	Content       string `json:"content"`
+	To            string `json:"to"`
+	Retry         int    `json:"retry"`
+	CorrelationID string `json:"-"`
+}
+
+// Envelope is the JSON encoding of schema version 2. It keeps the message ID
+// at the top level, where version 1 consumers read it. Consumers ignore
+// fields they do not know, so new optional fields do not need a new version.
+type Envelope struct {
+	Type      string          `json:"type"`
+	Version   int             `json:"version"`
+	ID        string          `json:"id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   EnvelopePayload `json:"payload"`
+}
+
+type EnvelopePayload struct {
 	To      string `json:"to"`
+	Content string `json:"content"`
 	Retry   int    `json:"retry"`
-}
\ No newline at end of file
+}
+
+// EncodeQueueMessage encodes msg in the current schema version with the
+// given content type. Publishers set SchemaVersionHeader to
+// CurrentSchemaVersion alongside it.
+func EncodeQueueMessage(msg QueueMessage, contentType string, timestamp time.Time) ([]byte, error) {
+	switch contentType {
+	case ContentTypeJSON:
+		return json.Marshal(Envelope{
+			Type:      QueueMessageType,
+			Version:   CurrentSchemaVersion,
+			ID:        msg.ID,
+			Timestamp: timestamp.UTC(),
+			Payload:   EnvelopePayload{To: msg.To, Content: msg.Content, Retry: msg.Retry},
+		})
+	case ContentTypeProtobuf:
+		return proto.MarshalOptions{Deterministic: true}.Marshal(&queuev2.Envelope{
+			Type:      QueueMessageType,
+			Version:   CurrentSchemaVersion,
+			Id:        msg.ID,
+			Timestamp: timestamppb.New(timestamp),
+			Payload:   &queuev2.Payload{To: msg.To, Content: msg.Content, Retry: int32(msg.Retry)},
+		})
+	default:
+		return nil, fmt.Errorf("%w: content type %q", ErrUnsupportedSchema, contentType)
+	}
+}
+
+// DecodeQueueMessage decodes a body of the given content type and schema
+// version. It accepts the current version and the one before it.
+func DecodeQueueMessage(contentType string, version int, body []byte) (QueueMessage, error) {
+	switch {
+	case version == SchemaVersion1 && (contentType == ContentTypeJSON || contentType == ""):
+		var msg QueueMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return QueueMessage{}, err
+		}
+		return msg, nil
+	case version == SchemaVersion2 && contentType == ContentTypeJSON:
+		var envelope Envelope
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return QueueMessage{}, err
+		}
+		if err := checkEnvelope(envelope.Type, envelope.Version, version); err != nil {
+			return QueueMessage{}, err
+		}
+		return QueueMessage{
+			ID:      envelope.ID,
+			Content: envelope.Payload.Content,
+			To:      envelope.Payload.To,
+			Retry:   envelope.Payload.Retry,
+		}, nil
+	case version == SchemaVersion2 && contentType == ContentTypeProtobuf:
+		var envelope queuev2.Envelope
+		if err := proto.Unmarshal(body, &envelope); err != nil {
+			return QueueMessage{}, err
+		}
+		if err := checkEnvelope(envelope.GetType(), int(envelope.GetVersion()), version); err != nil {
+			return QueueMessage{}, err
+		}
+		return QueueMessage{
+			ID:      envelope.GetId(),
+			Content: envelope.GetPayload().GetContent(),
+			To:      envelope.GetPayload().GetTo(),
+			Retry:   int(envelope.GetPayload().GetRetry()),
+		}, nil
+	default:
+		return QueueMessage{}, fmt.Errorf("%w: version %d with content type %q", ErrUnsupportedSchema, version, contentType)
+	}
+}
+
+func checkEnvelope(messageType string, version, headerVersion int) error {
+	if messageType != QueueMessageType {
+		return fmt.Errorf("%w: message type %q", ErrUnsupportedSchema, messageType)
+	}
+	if version != headerVersion {
+		return fmt.Errorf("envelope version %d does not match header version %d", version, headerVersion)
+	}
+	return nil
+}
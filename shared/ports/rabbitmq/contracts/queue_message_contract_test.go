@@ -0,0 +1,106 @@
+package contracts
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests pin the wire format. A change that breaks them breaks
+// consumers that are already deployed and needs a new schema version.
+
+var (
+	contractTime    = time.Date(2026, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	contractMessage = QueueMessage{ID: "65a1f0c2e4b0a1b2c3d4e5f6", Content: "hello", To: "+905321234567", Retry: 2}
+)
+
+const contractJSON = `{"type":"message.send","version":2,"id":"65a1f0c2e4b0a1b2c3d4e5f6","timestamp":"2026-01-02T03:04:05.006Z","payload":{"to":"+905321234567","content":"hello","retry":2}}`
+
+// contractProtobuf is contractMessage as a queue.v2.Envelope.
+var contractProtobuf = "" +
+	"0a0c" + hex.EncodeToString([]byte("message.send")) + // 1: type
+	"1002" + // 2: version
+	"1a18" + hex.EncodeToString([]byte("65a1f0c2e4b0a1b2c3d4e5f6")) + // 3: id
+	"220b" + "08a5ebdcca06" + "10809bee02" + // 4: timestamp {seconds, nanos}
+	"2a18" + // 5: payload
+	"0a0d" + hex.EncodeToString([]byte("+905321234567")) + // payload 1: to
+	"1205" + hex.EncodeToString([]byte("hello")) + // payload 2: content
+	"1802" // payload 3: retry
+
+func TestEncodeQueueMessage_JSON(t *testing.T) {
+	body, err := EncodeQueueMessage(contractMessage, ContentTypeJSON, contractTime)
+	require.NoError(t, err)
+	assert.JSONEq(t, contractJSON, string(body))
+}
+
+func TestEncodeQueueMessage_Protobuf(t *testing.T) {
+	body, err := EncodeQueueMessage(contractMessage, ContentTypeProtobuf, contractTime)
+	require.NoError(t, err)
+	assert.Equal(t, contractProtobuf, hex.EncodeToString(body))
+}
+
+func TestEncodeQueueMessage_UnknownContentType(t *testing.T) {
+	_, err := EncodeQueueMessage(contractMessage, "text/plain", contractTime)
+	assert.True(t, errors.Is(err, ErrUnsupportedSchema))
+}
+
+func TestDecodeQueueMessage(t *testing.T) {
+	protobufBody, err := hex.DecodeString(contractProtobuf)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		version     int
+		body        []byte
+	}{
+		{name: "version 1", contentType: ContentTypeJSON, version: SchemaVersion1,
+			body: []byte(`{"id":"65a1f0c2e4b0a1b2c3d4e5f6","content":"hello","to":"+905321234567","retry":2}`)},
+		{name: "version 1 without content type", version: SchemaVersion1,
+			body: []byte(`{"id":"65a1f0c2e4b0a1b2c3d4e5f6","content":"hello","to":"+905321234567","retry":2}`)},
+		{name: "version 2 JSON", contentType: ContentTypeJSON, version: SchemaVersion2, body: []byte(contractJSON)},
+		{name: "version 2 JSON with unknown fields", contentType: ContentTypeJSON, version: SchemaVersion2,
+			body: []byte(`{"type":"message.send","version":2,"id":"65a1f0c2e4b0a1b2c3d4e5f6","priority":"high","payload":{"to":"+905321234567","content":"hello","retry":2,"channel":"sms"}}`)},
+		{name: "version 2 protobuf", contentType: ContentTypeProtobuf, version: SchemaVersion2, body: protobufBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := DecodeQueueMessage(tt.contentType, tt.version, tt.body)
+			require.NoError(t, err)
+			assert.Equal(t, contractMessage, msg)
+		})
+	}
+}
+
+func TestDecodeQueueMessage_Rejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		version     int
+		body        string
+		unsupported bool
+	}{
+		{name: "future version", contentType: ContentTypeJSON, version: 3, body: contractJSON, unsupported: true},
+		{name: "version 1 protobuf", contentType: ContentTypeProtobuf, version: SchemaVersion1, body: "", unsupported: true},
+		{name: "unknown content type", contentType: "text/plain", version: SchemaVersion2, body: contractJSON, unsupported: true},
+		{name: "unknown message type", contentType: ContentTypeJSON, version: SchemaVersion2,
+			body: `{"type":"message.deleted","version":2,"id":"65a1f0c2e4b0a1b2c3d4e5f6"}`, unsupported: true},
+		{name: "envelope version differs from header", contentType: ContentTypeJSON, version: SchemaVersion2,
+			body: `{"type":"message.send","version":3,"id":"65a1f0c2e4b0a1b2c3d4e5f6"}`},
+		{name: "malformed JSON", contentType: ContentTypeJSON, version: SchemaVersion2, body: `{"type":`},
+		{name: "malformed protobuf", contentType: ContentTypeProtobuf, version: SchemaVersion2, body: "\xff\xff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeQueueMessage(tt.contentType, tt.version, []byte(tt.body))
+			require.Error(t, err)
+			assert.Equal(t, tt.unsupported, errors.Is(err, ErrUnsupportedSchema))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package contracts
+
+import (
+	"fmt"
+	"time"
+)
+
+type QueueType string
+
+const (
+	QueueTypeClassic QueueType = "classic"
+	QueueTypeQuorum  QueueType = "quorum"
+	// QueueTypeLazy is a classic queue that keeps its messages on disk.
+	QueueTypeLazy QueueType = "lazy"
+)
+
+const (
+	OverflowDropHead         = "drop-head"
+	OverflowRejectPublish    = "reject-publish"
+	OverflowRejectPublishDLX = "reject-publish-dlx"
+)
+
+// Topology describes the exchanges and queues declared on the broker. Prefix
+// is prepended to every name, so several environments can share a broker.
+// A zero MaxLength, MessageTTL, DLQMaxLength or DLQMessageTTL means no limit.
+// MessageTTL is the expiration of every published message.
+//
+// Failed messages wait in one retry queue per RetryBackoff tier. RetryTTL is
+// the TTL of the retry queue the main queue dead-letters into. ContentType is
+// the encoding messages are published with; consumers accept every encoding.
+type Topology struct {
+	Prefix        string
+	QueueType     QueueType
+	RetryTTL      time.Duration
+	RetryBackoff  []time.Duration
+	MaxLength     int
+	Overflow      string
+	MessageTTL    time.Duration
+	DLQMaxLength  int
+	DLQMessageTTL time.Duration
+	ContentType   string
+}
+
+// DefaultTopology matches the names and retry TTL used before the topology
+// was configurable.
+func DefaultTopology() Topology {
+	return Topology{
+		QueueType: QueueTypeClassic,
+		RetryTTL:  10 * time.Second,
+		RetryBackoff: []time.Duration{
+			10 * time.Second,
+			time.Minute,
+			10 * time.Minute,
+			time.Hour,
+		},
+		Overflow:    OverflowDropHead,
+		ContentType: ContentTypeJSON,
+	}
+}
+
+func (t Topology) MainQueue() string     { return t.name(MainQueueName) }
+func (t Topology) RetryQueue() string    { return t.name(RetryQueueName) }
+func (t Topology) DLQQueue() string      { return t.name(DLQQueueName) }
+func (t Topology) MainExchange() string  { return t.name(MainExchange) }
+func (t Topology) RetryExchange() string { return t.name(RetryExchange) }
+
+// RetryTierQueue names the retry queue of a backoff tier after its delay, e.g.
+// messages.retry.10m, so changing the schedule declares new queues instead
+// of conflicting with existing ones.
+func (t Topology) RetryTierQueue(tier int) string {
+	return t.name(RetryQueueName + "." + formatDelay(t.RetryBackoff[tier]))
+}
+
+func (t Topology) name(base string) string {
+	if t.Prefix == "" {
+		return base
+	}
+	return t.Prefix + "." + base
+}
+
+func (t Topology) Validate() error {
+	switch t.QueueType {
+	case QueueTypeClassic, QueueTypeLazy, QueueTypeQuorum:
+	default:
+		return fmt.Errorf("unknown queue type %q, expected classic, quorum or lazy", t.QueueType)
+	}
+
+	switch t.Overflow {
+	case OverflowDropHead, OverflowRejectPublish:
+	case OverflowRejectPublishDLX:
+		if t.QueueType == QueueTypeQuorum {
+			return fmt.Errorf("overflow %q is not supported by quorum queues", t.Overflow)
+		}
+	default:
+		return fmt.Errorf("unknown overflow policy %q, expected drop-head, reject-publish or reject-publish-dlx", t.Overflow)
+	}
+
+	switch t.ContentType {
+	case ContentTypeJSON, ContentTypeProtobuf:
+	default:
+		return fmt.Errorf("unknown content type %q, expected %s or %s", t.ContentType, ContentTypeJSON, ContentTypeProtobuf)
+	}
+
+	if t.RetryTTL <= 0 {
+		return fmt.Errorf("retry TTL must be positive, got %s", t.RetryTTL)
+	}
+	if len(t.RetryBackoff) == 0 {
+		return fmt.Errorf("retry backoff needs at least one tier")
+	}
+	for _, delay := range t.RetryBackoff {
+		if delay <= 0 {
+			return fmt.Errorf("retry backoff tiers must be positive, got %s", delay)
+		}
+	}
+	if t.MaxLength < 0 || t.DLQMaxLength < 0 {
+		return fmt.Errorf("max length must not be negative")
+	}
+	if t.MessageTTL < 0 {
+		return fmt.Errorf("message TTL must not be negative")
+	}
+	if t.DLQMessageTTL < 0 {
+		return fmt.Errorf("DLQ message TTL must not be negative")
+	}
+	return nil
+}
+
+func formatDelay(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		return fmt.Sprintf("%dms", d/time.Millisecond)
+	}
+}
@@ -0,0 +1,76 @@
+package contracts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopology_Names(t *testing.T) {
+	topology := DefaultTopology()
+	assert.Equal(t, "messages", topology.MainQueue())
+	assert.Equal(t, "messages.retry", topology.RetryQueue())
+	assert.Equal(t, "messages.dlq", topology.DLQQueue())
+	assert.Equal(t, "messages.exchange", topology.MainExchange())
+	assert.Equal(t, "messages.retry.exchange", topology.RetryExchange())
+	assert.Equal(t, "messages.retry.10s", topology.RetryTierQueue(0))
+	assert.Equal(t, "messages.retry.1m", topology.RetryTierQueue(1))
+	assert.Equal(t, "messages.retry.10m", topology.RetryTierQueue(2))
+	assert.Equal(t, "messages.retry.1h", topology.RetryTierQueue(3))
+
+	topology.Prefix = "staging"
+	assert.Equal(t, "staging.messages", topology.MainQueue())
+	assert.Equal(t, "staging.messages.retry", topology.RetryQueue())
+	assert.Equal(t, "staging.messages.dlq", topology.DLQQueue())
+	assert.Equal(t, "staging.messages.exchange", topology.MainExchange())
+	assert.Equal(t, "staging.messages.retry.exchange", topology.RetryExchange())
+	assert.Equal(t, "staging.messages.retry.10s", topology.RetryTierQueue(0))
+
+	topology.RetryBackoff = []time.Duration{90 * time.Second, 1500 * time.Millisecond}
+	assert.Equal(t, "staging.messages.retry.90s", topology.RetryTierQueue(0))
+	assert.Equal(t, "staging.messages.retry.1500ms", topology.RetryTierQueue(1))
+}
+
+func TestTopology_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Topology)
+		wantErr bool
+	}{
+		{name: "default", modify: func(*Topology) {}},
+		{name: "quorum", modify: func(t *Topology) { t.QueueType = QueueTypeQuorum }},
+		{name: "lazy with reject-publish-dlx", modify: func(t *Topology) {
+			t.QueueType = QueueTypeLazy
+			t.Overflow = OverflowRejectPublishDLX
+		}},
+		{name: "unknown queue type", modify: func(t *Topology) { t.QueueType = "stream" }, wantErr: true},
+		{name: "unknown overflow", modify: func(t *Topology) { t.Overflow = "drop-tail" }, wantErr: true},
+		{name: "quorum with reject-publish-dlx", modify: func(t *Topology) {
+			t.QueueType = QueueTypeQuorum
+			t.Overflow = OverflowRejectPublishDLX
+		}, wantErr: true},
+		{name: "zero retry TTL", modify: func(t *Topology) { t.RetryTTL = 0 }, wantErr: true},
+		{name: "no retry tiers", modify: func(t *Topology) { t.RetryBackoff = nil }, wantErr: true},
+		{name: "zero retry tier", modify: func(t *Topology) { t.RetryBackoff = []time.Duration{0} }, wantErr: true},
+		{name: "negative max length", modify: func(t *Topology) { t.MaxLength = -1 }, wantErr: true},
+		{name: "negative message TTL", modify: func(t *Topology) { t.MessageTTL = -time.Second }, wantErr: true},
+		{name: "negative DLQ TTL", modify: func(t *Topology) { t.DLQMessageTTL = -time.Second }, wantErr: true},
+		{name: "protobuf", modify: func(t *Topology) { t.ContentType = ContentTypeProtobuf }},
+		{name: "unknown content type", modify: func(t *Topology) { t.ContentType = "text/plain" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology := DefaultTopology()
+			tt.modify(&topology)
+
+			err := topology.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Headers added to every message moved to the DLQ.
+const (
+	FailureReasonHeader   = "x-failure-reason"
+	FailureErrorHeader    = "x-failure-error"
+	FailureAttemptsHeader = "x-failure-attempts"
+	OriginalQueueHeader   = "x-original-queue"
+	FirstSeenHeader       = "x-first-seen-at"
+	DeadLetteredAtHeader  = "x-dead-lettered-at"
+	InstanceHeader        = "x-processor-instance"
+)
+
+type FailureReason string
+
+const (
+	FailureMalformed     FailureReason = "malformed_message"
+	FailureInvalidID     FailureReason = "invalid_message_id"
+	FailureMaxRetries    FailureReason = "max_retries"
+	FailureStale         FailureReason = "stale"
+	FailureStaleRecovery FailureReason = "stale_recovery"
+	FailureExpired       FailureReason = "expired"
+)
+
+// Failure explains why a message was moved to the DLQ. The queue fills in
+// the original queue and the timestamps.
+type Failure struct {
+	Reason         FailureReason `json:"reason"`
+	Error          string        `json:"error,omitempty"`
+	Attempts       int           `json:"attempts"`
+	Instance       string        `json:"instance,omitempty"`
+	OriginalQueue  string        `json:"original_queue,omitempty"`
+	FirstSeenAt    time.Time     `json:"first_seen_at,omitempty"`
+	DeadLetteredAt time.Time     `json:"dead_lettered_at,omitempty"`
+}
+
+// Death is one entry of the x-death header the broker adds each time it
+// dead-letters a message, e.g. when a retry tier's delay expires.
+type Death struct {
+	Queue    string    `json:"queue"`
+	Reason   string    `json:"reason"`
+	Exchange string    `json:"exchange,omitempty"`
+	Count    int64     `json:"count"`
+	Time     time.Time `json:"time"`
+}
+
+// Headers returns the failure as DLQ headers.
+func (f Failure) Headers() amqp.Table {
+	return amqp.Table{
+		FailureReasonHeader:   string(f.Reason),
+		FailureErrorHeader:    f.Error,
+		FailureAttemptsHeader: f.Attempts,
+		OriginalQueueHeader:   f.OriginalQueue,
+		FirstSeenHeader:       f.FirstSeenAt.UTC().Format(time.RFC3339Nano),
+		DeadLetteredAtHeader:  f.DeadLetteredAt.UTC().Format(time.RFC3339Nano),
+		InstanceHeader:        f.Instance,
+	}
+}
+
+// ParseFailure reads the failure headers of a DLQ message. It returns nil
+// for messages dead-lettered without them.
+func ParseFailure(headers amqp.Table) *Failure {
+	reason, ok := headers[FailureReasonHeader].(string)
+	if !ok {
+		return nil
+	}
+
+	failure := &Failure{Reason: FailureReason(reason)}
+	failure.Error, _ = headers[FailureErrorHeader].(string)
+	failure.Attempts = int(headerInt(headers[FailureAttemptsHeader]))
+	failure.Instance, _ = headers[InstanceHeader].(string)
+	failure.OriginalQueue, _ = headers[OriginalQueueHeader].(string)
+	failure.FirstSeenAt = headerTime(headers[FirstSeenHeader])
+	failure.DeadLetteredAt = headerTime(headers[DeadLetteredAtHeader])
+	return failure
+}
+
+// ParseDeaths reads the x-death header, most recent death first.
+func ParseDeaths(headers amqp.Table) []Death {
+	entries, ok := headers["x-death"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	deaths := make([]Death, 0, len(entries))
+	for _, entry := range entries {
+		table, ok := entry.(amqp.Table)
+		if !ok {
+			continue
+		}
+		death := Death{Count: headerInt(table["count"]), Time: headerTime(table["time"])}
+		death.Queue, _ = table["queue"].(string)
+		death.Reason, _ = table["reason"].(string)
+		death.Exchange, _ = table["exchange"].(string)
+		deaths = append(deaths, death)
+	}
+	return deaths
+}
+
+// FirstSeen is when a message was first seen according to its headers: the
+// first-seen header set on retries, or else its earliest x-death entry. It
+// returns the zero time if neither is present.
+func FirstSeen(headers amqp.Table) time.Time {
+	if t := headerTime(headers[FirstSeenHeader]); !t.IsZero() {
+		return t
+	}
+
+	var first time.Time
+	for _, death := range ParseDeaths(headers) {
+		if !death.Time.IsZero() && (first.IsZero() || death.Time.Before(first)) {
+			first = death.Time
+		}
+	}
+	return first
+}
+
+func headerInt(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		return 0
+	}
+}
+
+func headerTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case string:
+		t, _ := time.Parse(time.RFC3339Nano, v)
+		return t
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,54 @@
+package interfaces
+
+import (
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailure_HeadersRoundTrip(t *testing.T) {
+	failure := Failure{
+		Reason:         FailureMaxRetries,
+		Error:          "webhook returned 500",
+		Attempts:       5,
+		Instance:       "processor-1",
+		OriginalQueue:  "messages",
+		FirstSeenAt:    time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		DeadLetteredAt: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC),
+	}
+
+	parsed := ParseFailure(failure.Headers())
+	assert.Equal(t, &failure, parsed)
+}
+
+func TestParseFailure_WithoutHeaders(t *testing.T) {
+	assert.Nil(t, ParseFailure(nil))
+	assert.Nil(t, ParseFailure(amqp.Table{"x-death": []interface{}{}}))
+}
+
+func TestParseDeaths(t *testing.T) {
+	first := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Minute)
+	headers := amqp.Table{
+		"x-death": []interface{}{
+			amqp.Table{"queue": "messages.retry.1m", "reason": "expired", "exchange": "messages.retry.exchange", "count": int64(1), "time": second},
+			amqp.Table{"queue": "messages.retry.10s", "reason": "expired", "exchange": "messages.retry.exchange", "count": int64(2), "time": first},
+		},
+	}
+
+	deaths := ParseDeaths(headers)
+	assert.Equal(t, []Death{
+		{Queue: "messages.retry.1m", Reason: "expired", Exchange: "messages.retry.exchange", Count: 1, Time: second},
+		{Queue: "messages.retry.10s", Reason: "expired", Exchange: "messages.retry.exchange", Count: 2, Time: first},
+	}, deaths)
+	assert.Equal(t, first, FirstSeen(headers))
+
+	headers[FirstSeenHeader] = first.Add(-time.Hour).Format(time.RFC3339Nano)
+	assert.Equal(t, first.Add(-time.Hour), FirstSeen(headers))
+}
+
+func TestFirstSeen_Unknown(t *testing.T) {
+	assert.True(t, FirstSeen(amqp.Table{}).IsZero())
+}
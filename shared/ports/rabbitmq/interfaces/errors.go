@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrPublishNacked     = errors.New("broker nacked the message")
+	ErrPublishUnroutable = errors.New("message could not be routed to any queue")
+	ErrNotConnected      = errors.New("not connected to RabbitMQ")
+)
+
+// PublishError reports a message the broker did not accept. Reason is
+// ErrPublishNacked or ErrPublishUnroutable.
+type PublishError struct {
+	Reason    error
+	ReplyCode uint16
+	ReplyText string
+}
+
+func (e *PublishError) Error() string {
+	if e.ReplyText == "" {
+		return e.Reason.Error()
+	}
+	return fmt.Sprintf("%v: %d %s", e.Reason, e.ReplyCode, e.ReplyText)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Reason
+}
@@ -2,17 +2,90 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/Furkan-Gulsen/reliable_messaging_system/shared/ports/rabbitmq/contracts"
 
 	"github.com/streadway/amqp"
 )
 
+// RetryTierHeader records the backoff tier a retried message last waited in.
+const RetryTierHeader = "x-retry-tier"
+
+// SchemaVersion is the schema version of a message according to its
+// headers.
+func SchemaVersion(headers amqp.Table) int {
+	if version := headerInt(headers[contracts.SchemaVersionHeader]); version > 0 {
+		return int(version)
+	}
+	return contracts.SchemaVersion1
+}
+
+// DecodeMessage decodes a message body by its content type and the schema
+// version in its headers.
+func DecodeMessage(contentType string, headers amqp.Table, body []byte) (contracts.QueueMessage, error) {
+	return contracts.DecodeQueueMessage(contentType, SchemaVersion(headers), body)
+}
+
+// QueueStats is a snapshot of a queue's ready messages and consumers.
+type QueueStats struct {
+	Messages  int
+	Consumers int
+}
+
+// ConnectionState reports whether the queue is connected, since when, and
+// how often it has reconnected.
+type ConnectionState struct {
+	Connected  bool      `json:"connected"`
+	Since      time.Time `json:"since"`
+	Reconnects int       `json:"reconnects"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// DeadLetter is a message read from the DLQ.
+type DeadLetter struct {
+	MessageID   string
+	ContentType string
+	Body        []byte
+	Headers     amqp.Table
+	Timestamp   time.Time
+}
+
+// ReplayFunc decides what to do with a DLQ message during a replay. It
+// returns the message to publish to the main queue, or nil to leave the
+// message in the DLQ.
+type ReplayFunc func(DeadLetter) (*contracts.QueueMessage, error)
+
+// Publisher publishes over a channel of its own. Close releases the channel.
+type Publisher interface {
+	PublishMessage(ctx context.Context, msg contracts.QueueMessage) error
+	Close()
+}
+
 type MessageQueue interface {
 	PublishMessage(ctx context.Context, msg contracts.QueueMessage) error
-	ConsumeMessages(queueName string) (<-chan amqp.Delivery, error)
-	MoveToDeadLetter(msg *amqp.Delivery) error
-	MoveToRetryQueue(msg *amqp.Delivery) error
+	NewPublisher() (Publisher, error)
+	// ConsumeMessages starts a consumer that the broker sends at most
+	// prefetch unacknowledged deliveries; 0 means no limit.
+	ConsumeMessages(queueName string, prefetch int) (<-chan amqp.Delivery, error)
+	// MoveToDeadLetter publishes the message to the DLQ with headers that
+	// record the failure.
+	MoveToDeadLetter(msg *amqp.Delivery, failure Failure) error
+	// MoveToRetryQueue delays the message in the retry queue of the given
+	// backoff tier and then returns it to the main queue.
+	MoveToRetryQueue(msg *amqp.Delivery, tier int, delay time.Duration) error
 	GetDLQMessageCount() (int, error)
+	// BrowseDLQ returns up to limit DLQ messages after skipping offset, and
+	// the number of messages in the DLQ. The messages stay in the DLQ.
+	BrowseDLQ(offset, limit int) ([]DeadLetter, int, error)
+	// ReplayDLQ passes each message in the DLQ to replay and moves the
+	// messages it returns a replacement for to the main queue. It returns the
+	// number of messages replayed.
+	ReplayDLQ(ctx context.Context, replay ReplayFunc) (int, error)
+	// PurgeDLQ deletes every message in the DLQ and returns how many there
+	// were.
+	PurgeDLQ() (int, error)
+	GetQueueStats(queueName string) (QueueStats, error)
+	ConnectionState() ConnectionState
 	Close()
 } 
\ No newline at end of file
@@ -9,4 +9,10 @@ type IdempotencyServicePort interface {
 	IsProcessed(ctx context.Context, messageID string) (bool, error)
 	MarkAsProcessed(ctx context.Context, messageID string) error
 	StoreWebhookMessageID(ctx context.Context, messageID string, webhookMessageID string, expiration time.Duration) error
+	// Claim takes the inbox entry of a message for owner until ttl passes, so
+	// only one delivery of the message is processed at a time. It reports
+	// false if the message is claimed or already processed.
+	Claim(ctx context.Context, messageID string, owner string, ttl time.Duration) (bool, error)
+	// Release gives up owner's claim, leaving a processed entry in place.
+	Release(ctx context.Context, messageID string, owner string) error
 } 
\ No newline at end of file
@@ -3,9 +3,29 @@ package ratelimit
 import (
 	"context"
 
+	"github.com/go-redis/redis/v8"
 	"golang.org/x/time/rate"
 )
 
+const (
+	BackendLocal = "local"
+	BackendRedis = "redis"
+)
+
+type Limiter interface {
+	Wait(ctx context.Context) error
+	Allow() bool
+}
+
+// NewLimiter returns a Redis-backed limiter shared across replicas when the
+// backend is "redis" and a client is available, otherwise an in-process one.
+func NewLimiter(backend string, client *redis.Client, name string, rps float64, burst int) Limiter {
+	if backend == BackendRedis && client != nil {
+		return NewRedisRateLimiter(client, name, rps, burst)
+	}
+	return NewRateLimiter(rps, burst)
+}
+
 type RateLimiter struct {
 	limiter *rate.Limiter
 }
@@ -26,4 +46,4 @@ func (r *RateLimiter) Allow() bool {
 
 func (r *RateLimiter) Reserve() *rate.Reservation {
 	return r.limiter.Reserve()
-} 
\ No newline at end of file
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sony/gobreaker"
+)
+
+// tokenBucketScript refills the bucket from the Redis server clock so that
+// every replica sharing the key observes the same bucket state.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local now_parts = redis.call('TIME')
+local now = tonumber(now_parts[1]) * 1000 + math.floor(tonumber(now_parts[2]) / 1000)
+
+local bucket = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+local elapsed = math.max(0, now - ts)
+tokens = math.min(burst, tokens + (elapsed * rate / 1000))
+
+local allowed = 0
+local retry_after = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry_after = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', key, math.ceil(burst * 1000 / rate) + 1000)
+
+return {allowed, retry_after}
+`)
+
+const redisLimiterTimeout = 500 * time.Millisecond
+
+type redisRateLimiter struct {
+	client   *redis.Client
+	key      string
+	rps      float64
+	burst    int
+	fallback *RateLimiter
+	cb       *gobreaker.CircuitBreaker
+	degraded atomic.Bool
+}
+
+func NewRedisRateLimiter(client *redis.Client, name string, rps float64, burst int) Limiter {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "redis-ratelimit-" + name,
+		MaxRequests: 3,
+		Interval:    10 * time.Second,
+		Timeout:     30 * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= 3 && failureRatio >= 0.6
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			fmt.Printf("Circuit breaker %s state changed from %s to %s\n", name, from, to)
+		},
+	})
+
+	return &redisRateLimiter{
+		client:   client,
+		key:      fmt.Sprintf("ratelimit:%s", name),
+		rps:      rps,
+		burst:    burst,
+		fallback: NewRateLimiter(rps, burst),
+		cb:       cb,
+	}
+}
+
+func (r *redisRateLimiter) Wait(ctx context.Context) error {
+	for {
+		allowed, retryAfter, err := r.take(ctx)
+		if err != nil {
+			return r.fallback.Wait(ctx)
+		}
+		if allowed {
+			return nil
+		}
+
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *redisRateLimiter) Allow() bool {
+	allowed, _, err := r.take(context.Background())
+	if err != nil {
+		return r.fallback.Allow()
+	}
+	return allowed
+}
+
+func (r *redisRateLimiter) take(ctx context.Context) (bool, time.Duration, error) {
+	result, err := r.cb.Execute(func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, redisLimiterTimeout)
+		defer cancel()
+
+		return tokenBucketScript.Run(ctx, r.client, []string{r.key}, r.rps, r.burst).Int64Slice()
+	})
+	if err != nil {
+		if r.degraded.CompareAndSwap(false, true) {
+			log.Printf("Redis rate limiter %s unavailable, falling back to local limiter: %v", r.key, err)
+		}
+		return false, 0, err
+	}
+
+	if r.degraded.CompareAndSwap(true, false) {
+		log.Printf("Redis rate limiter %s recovered", r.key)
+	}
+
+	values := result.([]int64)
+	if len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	return values[0] == 1, time.Duration(values[1]) * time.Millisecond, nil
+}
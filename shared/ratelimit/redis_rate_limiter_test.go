@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestRedisRateLimiter_Allow(t *testing.T) {
+	_, client := newTestRedis(t)
+	limiter := NewRedisRateLimiter(client, "test-allow", 1, 2)
+
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+}
+
+func TestRedisRateLimiter_SharedAcrossInstances(t *testing.T) {
+	_, client := newTestRedis(t)
+	first := NewRedisRateLimiter(client, "test-shared", 1, 2)
+	second := NewRedisRateLimiter(client, "test-shared", 1, 2)
+
+	assert.True(t, first.Allow())
+	assert.True(t, second.Allow())
+	assert.False(t, first.Allow())
+	assert.False(t, second.Allow())
+}
+
+func TestRedisRateLimiter_Refill(t *testing.T) {
+	server, client := newTestRedis(t)
+	now := time.Now()
+	server.SetTime(now)
+	limiter := NewRedisRateLimiter(client, "test-refill", 1, 1)
+
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	server.SetTime(now.Add(1100 * time.Millisecond))
+	assert.True(t, limiter.Allow())
+}
+
+func TestRedisRateLimiter_Wait(t *testing.T) {
+	_, client := newTestRedis(t)
+	limiter := NewRedisRateLimiter(client, "test-wait", 1, 1)
+
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestRedisRateLimiter_FallbackWhenRedisUnavailable(t *testing.T) {
+	server, client := newTestRedis(t)
+	limiter := NewRedisRateLimiter(client, "test-fallback", 1, 1)
+	server.Close()
+
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+	assert.NoError(t, limiter.Wait(context.Background()))
+}
+
+func TestNewLimiter(t *testing.T) {
+	_, client := newTestRedis(t)
+
+	assert.IsType(t, &RateLimiter{}, NewLimiter(BackendLocal, client, "local", 1, 1))
+	assert.IsType(t, &RateLimiter{}, NewLimiter(BackendRedis, nil, "no-client", 1, 1))
+	assert.IsType(t, &redisRateLimiter{}, NewLimiter(BackendRedis, client, "redis", 1, 1))
+}